@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/Dysar/url-crawler/backend/internal/api"
+	"github.com/Dysar/url-crawler/backend/internal/blobstore"
 	"github.com/Dysar/url-crawler/backend/internal/config"
 	"github.com/Dysar/url-crawler/backend/internal/crawler"
 	"github.com/Dysar/url-crawler/backend/internal/db"
@@ -36,6 +37,14 @@ func main() {
 	urlRepo := repository.NewURLRepository(conn)
 	jobRepo := repository.NewJobRepository(conn)
 	resultRepo := repository.NewResultRepository(conn)
+	tagRepo := repository.NewTagRepository(conn)
+	ruleRepo := repository.NewExtractionRuleRepository(conn)
+	certRepo := repository.NewCertificateRepository(conn)
+	profileRepo := repository.NewRequestProfileRepository(conn)
+	loginRepo := repository.NewLoginRecipeRepository(conn)
+	proxyRepo := repository.NewProxyPoolRepository(conn)
+	linkCheckRepo := repository.NewLinkCheckRepository(conn)
+	snapshotRepo := repository.NewSnapshotRepository(conn)
 
 	// Create services
 	urlService, err := service.NewURLService(urlRepo)
@@ -48,16 +57,141 @@ func main() {
 		log.Fatalf("failed to create result service: %v", err)
 	}
 
-	cr := crawler.New(crawler.HTTPClient(30 * time.Second))
-	jobService, err := service.NewJobService(jobRepo, resultRepo, urlRepo, cr)
+	tagService, err := service.NewTagService(tagRepo)
+	if err != nil {
+		log.Fatalf("failed to create tag service: %v", err)
+	}
+
+	ruleService, err := service.NewExtractionRuleService(ruleRepo)
+	if err != nil {
+		log.Fatalf("failed to create extraction rule service: %v", err)
+	}
+
+	certAlertThreshold := time.Duration(cfg.CertAlertDays) * 24 * time.Hour
+	certService, err := service.NewCertificateService(certRepo, certAlertThreshold)
+	if err != nil {
+		log.Fatalf("failed to create certificate service: %v", err)
+	}
+
+	// stored credentials get a key of their own; JWT_SECRET has a well-known default
+	if cfg.SecretsKey == "" || cfg.SecretsKey == cfg.JWTSecret {
+		log.Fatalf("SECRETS_KEY must be set, and differ from JWT_SECRET, to encrypt stored credentials")
+	}
+	secretBox, err := service.NewSecretBox(cfg.SecretsKey)
+	if err != nil {
+		log.Fatalf("failed to set up secrets encryption: %v", err)
+	}
+
+	profileService, err := service.NewRequestProfileService(profileRepo, secretBox)
+	if err != nil {
+		log.Fatalf("failed to create request profile service: %v", err)
+	}
+
+	loginService, err := service.NewLoginRecipeService(loginRepo, secretBox)
+	if err != nil {
+		log.Fatalf("failed to create login recipe service: %v", err)
+	}
+
+	proxyService, err := service.NewProxyService(proxyRepo, secretBox)
+	if err != nil {
+		log.Fatalf("failed to create proxy service: %v", err)
+	}
+
+	var snapshotService *service.SnapshotService
+	if cfg.SnapshotStore != "" {
+		store, err := blobstore.Open(cfg.SnapshotStoreConfig())
+		if err != nil {
+			log.Fatalf("failed to set up snapshot store: %v", err)
+		}
+		snapshotService, err = service.NewSnapshotService(snapshotRepo, store, service.SnapshotRetention{
+			MaxAge:     cfg.SnapshotMaxAge,
+			MaxPerURL:  cfg.SnapshotMaxPerURL,
+			WARCMaxAge: cfg.WARCMaxAge,
+		})
+		if err != nil {
+			log.Fatalf("failed to create snapshot service: %v", err)
+		}
+		snapshotService.SetWARCArchive(cfg.WARCArchive)
+		snapshotService.StartPruning()
+		log.Printf("keeping raw response snapshots in the %s store", cfg.SnapshotStore)
+	} else if cfg.WARCArchive {
+		log.Fatalf("WARC_ARCHIVE needs a SNAPSHOT_STORE to keep the records in")
+	}
+
+	guard, err := crawler.NewAddressGuard(cfg.CrawlAllowlist)
+	if err != nil {
+		log.Fatalf("invalid CRAWL_ALLOWLIST: %v", err)
+	}
+	cr := crawler.New(crawler.HTTPClient(30*time.Second, crawler.WithAddressGuard(guard)))
+	cr.Analyzers().Register(crawler.SecurityAnalyzer{ExpiryWarning: certAlertThreshold})
+	cr.SetLimits(crawler.Limits{MaxBodyBytes: cfg.MaxBodyBytes, MaxParseTime: cfg.MaxParseTime})
+	if len(cfg.InaccessibleLinkClasses) > 0 {
+		if err := cr.SetInaccessibleClasses(cfg.InaccessibleLinkClasses); err != nil {
+			log.Fatalf("invalid INACCESSIBLE_LINK_CLASSES: %v", err)
+		}
+	}
+	if cfg.LinkCacheTTL > 0 {
+		var store crawler.LinkStore
+		if cfg.LinkCachePersist {
+			linkStore, err := service.NewLinkCheckStore(linkCheckRepo, cfg.LinkCacheTTL)
+			if err != nil {
+				log.Fatalf("failed to create link check store: %v", err)
+			}
+			store = linkStore
+		}
+		cr.SetLinkCache(crawler.NewLinkCache(cfg.LinkCacheTTL, store))
+	}
+	if cfg.TechnologyRulesFile != "" {
+		custom, err := crawler.LoadTechnologyRules(cfg.TechnologyRulesFile)
+		if err != nil {
+			log.Fatalf("failed to load technology rules: %v", err)
+		}
+		fingerprints, err := crawler.NewFingerprintAnalyzer(append(crawler.DefaultTechnologyRules(), custom...))
+		if err != nil {
+			log.Fatalf("failed to compile technology rules: %v", err)
+		}
+		cr.Analyzers().Register(fingerprints)
+		log.Printf("loaded %d custom technology rules from %s", len(custom), cfg.TechnologyRulesFile)
+	}
+	if cfg.TrackerListFile != "" {
+		custom, err := crawler.LoadTrackerList(cfg.TrackerListFile)
+		if err != nil {
+			log.Fatalf("failed to load tracker list: %v", err)
+		}
+		trackers, err := crawler.NewTrackerAnalyzer(crawler.DefaultTrackerList().Extend(custom))
+		if err != nil {
+			log.Fatalf("failed to compile tracker list: %v", err)
+		}
+		cr.Analyzers().Register(trackers)
+		log.Printf("loaded %d custom tracker domains from %s", len(custom.Trackers), cfg.TrackerListFile)
+	}
+	jobOpts := []service.JobServiceOption{
+		service.WithExtractionRules(ruleRepo),
+		service.WithCertificateMonitor(certService),
+		service.WithRequestProfiles(profileService),
+		service.WithLoginRecipes(loginService),
+		service.WithProxyPools(proxyService),
+		service.WithConditionalFetch(),
+	}
+	if snapshotService != nil {
+		jobOpts = append(jobOpts, service.WithSnapshots(snapshotService))
+	}
+	jobService, err := service.NewJobService(jobRepo, resultRepo, urlRepo, cr, jobOpts...)
 	if err != nil {
 		log.Fatalf("failed to create job service: %v", err)
 	}
 
 	deps := api.Deps{
-		URLService:    urlService,
-		JobService:    jobService,
-		ResultService: resultService,
+		URLService:            urlService,
+		JobService:            jobService,
+		ResultService:         resultService,
+		TagService:            tagService,
+		ExtractionRuleService: ruleService,
+		CertificateService:    certService,
+		RequestProfileService: profileService,
+		LoginRecipeService:    loginService,
+		ProxyService:          proxyService,
+		SnapshotService:       snapshotService,
 	}
 	api.RegisterRoutes(r, cfg, deps)
 
@@ -86,6 +220,9 @@ func main() {
 	// Gracefully shutdown job service workers
 	log.Println("shutting down job service workers...")
 	jobService.Shutdown()
+	if snapshotService != nil {
+		snapshotService.Shutdown()
+	}
 
 	// Gracefully shutdown HTTP server with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,133 @@
+// Command warc exports archived crawl results as a .warc.gz file with a CDX index, and
+// indexes existing .warc.gz files.
+//
+//	warc export [-job ID] [-from DATE] [-to DATE] [-o FILE]
+//	warc index FILE.warc.gz > FILE.cdx
+//
+// export reads the same environment as the server (database and SNAPSHOT_* settings).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dysar/url-crawler/backend/internal/blobstore"
+	"github.com/Dysar/url-crawler/backend/internal/config"
+	"github.com/Dysar/url-crawler/backend/internal/db"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+	"github.com/Dysar/url-crawler/backend/internal/service"
+	"github.com/Dysar/url-crawler/backend/internal/warc"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: warc export [-job ID] [-from DATE] [-to DATE] [-o FILE]")
+	fmt.Fprintln(os.Stderr, "       warc index FILE.warc.gz")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "export":
+		export(os.Args[2:])
+	case "index":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		if _, err := index(os.Stdout, os.Args[2]); err != nil {
+			log.Fatalf("index failed: %v", err)
+		}
+	default:
+		usage()
+	}
+}
+
+func export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	jobID := fs.Int64("job", 0, "export the results of this job")
+	from := fs.String("from", "", "export results crawled from this date or RFC 3339 time")
+	to := fs.String("to", "", "export results crawled before this date or RFC 3339 time")
+	out := fs.String("o", "", "output file (default: named after the selection)")
+	_ = fs.Parse(args)
+
+	filter := models.ArchiveFilter{FromDate: *from, ToDate: *to}
+	if *jobID != 0 {
+		filter.JobID = jobID
+	}
+	if *out == "" {
+		*out = service.ArchiveName(filter)
+	}
+	if !strings.HasSuffix(*out, ".warc.gz") {
+		log.Fatalf("output file %s must end in .warc.gz", *out)
+	}
+
+	cfg := config.Load()
+	if cfg.SnapshotStore == "" {
+		log.Fatalf("SNAPSHOT_STORE is not set; there is nothing to export")
+	}
+	conn, err := db.NewMySQLConnection(cfg)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer conn.Close()
+	store, err := blobstore.Open(cfg.SnapshotStoreConfig())
+	if err != nil {
+		log.Fatalf("failed to set up snapshot store: %v", err)
+	}
+	snapshots, err := service.NewSnapshotService(repository.NewSnapshotRepository(conn), store, service.SnapshotRetention{})
+	if err != nil {
+		log.Fatalf("failed to create snapshot service: %v", err)
+	}
+	snapshots.SetWARCArchive(true)
+
+	ctx := context.Background()
+	snaps, err := snapshots.ArchivedSnapshots(ctx, filter)
+	if err != nil {
+		log.Fatalf("export failed: %v", err)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("export failed: %v", err)
+	}
+	if err := snapshots.WriteWARC(ctx, f, snaps, filepath.Base(*out)); err != nil {
+		f.Close()
+		log.Fatalf("export failed: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("export failed: %v", err)
+	}
+
+	cdxPath := strings.TrimSuffix(*out, ".warc.gz") + ".cdx"
+	cdx, err := os.Create(cdxPath)
+	if err != nil {
+		log.Fatalf("index failed: %v", err)
+	}
+	n, err := index(cdx, *out)
+	if closeErr := cdx.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("index failed: %v", err)
+	}
+	log.Printf("wrote %d results to %s and %d responses to %s", len(snaps), *out, n, cdxPath)
+}
+
+// index writes the CDX index of a .warc.gz file
+func index(w io.Writer, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return warc.WriteCDX(w, f, filepath.Base(path))
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Dysar/url-crawler/backend/internal/service"
+)
+
+type CertificateHandlers struct {
+	svc *service.CertificateService
+}
+
+func NewCertificateHandlers(svc *service.CertificateService) *CertificateHandlers {
+	return &CertificateHandlers{svc: svc}
+}
+
+// List returns certificates, optionally only those expiring within ?expiring_within=30d
+func (h *CertificateHandlers) List(c *gin.Context) {
+	certs, err := h.svc.ListCertificates(c, c.Query("expiring_within"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": certs})
+}
+
+func (h *CertificateHandlers) Alerts(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = n
+	}
+	alerts, err := h.svc.ListAlerts(c, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": alerts})
+}
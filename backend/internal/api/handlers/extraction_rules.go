@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/service"
+)
+
+type ExtractionRuleHandlers struct {
+	svc *service.ExtractionRuleService
+}
+
+func NewExtractionRuleHandlers(svc *service.ExtractionRuleService) *ExtractionRuleHandlers {
+	return &ExtractionRuleHandlers{svc: svc}
+}
+
+func (h *ExtractionRuleHandlers) Create(c *gin.Context) {
+	var req models.CreateExtractionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	resp, err := h.svc.CreateRule(c, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": resp})
+}
+
+func (h *ExtractionRuleHandlers) List(c *gin.Context) {
+	rules, err := h.svc.ListRules(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+func (h *ExtractionRuleHandlers) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+	if err := h.svc.DeleteRule(c, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
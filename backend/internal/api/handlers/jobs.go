@@ -19,17 +19,35 @@ func NewJobHandlers(svc *service.JobService) *JobHandlers {
 	return &JobHandlers{svc: svc}
 }
 
+// startJobsRequest selects URLs either explicitly or by tag (or both).
+// Analyzers picks the analyzers to run; omitted means all of them.
+// FetchResources makes the resources analyzer measure page weight.
+// Refetch skips the conditional request, so unchanged pages are parsed and link-checked again.
+// RecheckLinks keeps the conditional request but checks the links of unchanged pages again.
+// ArchiveLinks adds the link checks to the WARC archive of each page.
 type startJobsRequest struct {
-	URLIDs []int64 `json:"url_ids" binding:"required"`
+	URLIDs         []int64  `json:"url_ids"`
+	Tag            string   `json:"tag"`
+	Analyzers      []string `json:"analyzers"`
+	FetchResources bool     `json:"fetch_resources"`
+	Refetch        bool     `json:"refetch"`
+	RecheckLinks   bool     `json:"recheck_links"`
+	ArchiveLinks   bool     `json:"archive_links"`
 }
 
 func (h *JobHandlers) Start(c *gin.Context) {
 	var req startJobsRequest
-	if err := c.ShouldBindJSON(&req); err != nil || len(req.URLIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "url_ids required"})
+	if err := c.ShouldBindJSON(&req); err != nil || (len(req.URLIDs) == 0 && req.Tag == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url_ids or tag required"})
 		return
 	}
-	started := make([]models.JobStartResponse, 0, len(req.URLIDs))
+	opts := models.JobOptions{Analyzers: req.Analyzers, FetchResources: req.FetchResources, Refetch: req.Refetch, RecheckLinks: req.RecheckLinks, ArchiveLinks: req.ArchiveLinks}
+	if err := h.svc.ValidateOptions(opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targets := make([]models.URL, 0, len(req.URLIDs))
 	for _, id := range req.URLIDs {
 		urlRec, err := h.svc.GetURLByID(c, id)
 		if err != nil {
@@ -40,7 +58,26 @@ func (h *JobHandlers) Start(c *gin.Context) {
 			logrus.WithField("url_id", id).Warn("URL not found")
 			continue
 		}
-		jobID, err := h.svc.StartForURL(c, id, urlRec.URL)
+		targets = append(targets, *urlRec)
+	}
+	if req.Tag != "" {
+		tagged, err := h.svc.GetURLsByTag(c, req.Tag)
+		if err != nil {
+			c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		targets = append(targets, tagged...)
+	}
+
+	started := make([]models.JobStartResponse, 0, len(targets))
+	seen := make(map[int64]bool, len(targets))
+	for _, urlRec := range targets {
+		id := urlRec.ID
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		jobID, err := h.svc.StartForURL(c, id, urlRec.URL, opts)
 		if err != nil {
 			logrus.WithError(err).WithFields(logrus.Fields{
 				"url_id": id,
@@ -53,6 +90,16 @@ func (h *JobHandlers) Start(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": started})
 }
 
+// Analyzers lists the analyzer names that can be passed when starting jobs
+func (h *JobHandlers) Analyzers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.svc.AvailableAnalyzers()})
+}
+
+// Metrics reports crawler counters such as link cache hits and misses
+func (h *JobHandlers) Metrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.svc.Metrics()})
+}
+
 func (h *JobHandlers) Status(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseInt(idParam, 10, 64)
@@ -70,17 +117,30 @@ func (h *JobHandlers) Status(c *gin.Context) {
 }
 
 type stopJobsRequest struct {
-	URLIDs []int64 `json:"url_ids" binding:"required"`
+	URLIDs []int64 `json:"url_ids"`
+	Tag    string  `json:"tag"`
 }
 
 func (h *JobHandlers) Stop(c *gin.Context) {
 	var req stopJobsRequest
-	if err := c.ShouldBindJSON(&req); err != nil || len(req.URLIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "url_ids required"})
+	if err := c.ShouldBindJSON(&req); err != nil || (len(req.URLIDs) == 0 && req.Tag == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url_ids or tag required"})
 		return
 	}
 
-	stopped, err := h.svc.StopJobs(c, req.URLIDs)
+	urlIDs := req.URLIDs
+	if req.Tag != "" {
+		tagged, err := h.svc.GetURLsByTag(c, req.Tag)
+		if err != nil {
+			c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		for _, u := range tagged {
+			urlIDs = append(urlIDs, u.ID)
+		}
+	}
+
+	stopped, err := h.svc.StopJobs(c, urlIDs)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
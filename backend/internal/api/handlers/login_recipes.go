@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/service"
+)
+
+type LoginRecipeHandlers struct {
+	svc *service.LoginRecipeService
+}
+
+func NewLoginRecipeHandlers(svc *service.LoginRecipeService) *LoginRecipeHandlers {
+	return &LoginRecipeHandlers{svc: svc}
+}
+
+func (h *LoginRecipeHandlers) Create(c *gin.Context) {
+	var req models.CreateLoginRecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	resp, err := h.svc.CreateRecipe(c, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": resp})
+}
+
+func (h *LoginRecipeHandlers) List(c *gin.Context) {
+	recipes, err := h.svc.ListRecipes(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": recipes})
+}
+
+func (h *LoginRecipeHandlers) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe id"})
+		return
+	}
+	if err := h.svc.DeleteRecipe(c, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AssignToURL sets or clears the login recipe of the URL in the path
+func (h *LoginRecipeHandlers) AssignToURL(c *gin.Context) {
+	urlID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid url id"})
+		return
+	}
+	var req models.AssignLoginRecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := h.svc.AssignToURL(c, urlID, req.RecipeID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "url or login recipe not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
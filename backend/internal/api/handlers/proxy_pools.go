@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/service"
+)
+
+type ProxyPoolHandlers struct {
+	svc *service.ProxyService
+}
+
+func NewProxyPoolHandlers(svc *service.ProxyService) *ProxyPoolHandlers {
+	return &ProxyPoolHandlers{svc: svc}
+}
+
+func (h *ProxyPoolHandlers) Create(c *gin.Context) {
+	var req models.CreateProxyPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	resp, err := h.svc.CreatePool(c, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": resp})
+}
+
+// List returns the pools with the health of each proxy
+func (h *ProxyPoolHandlers) List(c *gin.Context) {
+	pools, err := h.svc.ListPools(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": pools})
+}
+
+func (h *ProxyPoolHandlers) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pool id"})
+		return
+	}
+	if err := h.svc.DeletePool(c, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/service"
+)
+
+type RequestProfileHandlers struct {
+	svc *service.RequestProfileService
+}
+
+func NewRequestProfileHandlers(svc *service.RequestProfileService) *RequestProfileHandlers {
+	return &RequestProfileHandlers{svc: svc}
+}
+
+func (h *RequestProfileHandlers) Create(c *gin.Context) {
+	var req models.CreateRequestProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	resp, err := h.svc.CreateProfile(c, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": resp})
+}
+
+func (h *RequestProfileHandlers) List(c *gin.Context) {
+	profiles, err := h.svc.ListProfiles(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": profiles})
+}
+
+func (h *RequestProfileHandlers) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid profile id"})
+		return
+	}
+	if err := h.svc.DeleteProfile(c, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/service"
+)
+
+type SnapshotHandlers struct {
+	svc *service.SnapshotService // nil when no snapshot store is configured
+}
+
+func NewSnapshotHandlers(svc *service.SnapshotService) *SnapshotHandlers {
+	return &SnapshotHandlers{svc: svc}
+}
+
+// Download sends the gzipped raw response of a result, by result ID
+func (h *SnapshotHandlers) Download(c *gin.Context) {
+	if h.svc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshots are not enabled"})
+		return
+	}
+	resultID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid result id"})
+		return
+	}
+	snap, blob, err := h.svc.Open(c, resultID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer blob.Close()
+	c.DataFromReader(http.StatusOK, snap.SizeBytes, "application/gzip", blob, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="result-%d.http.gz"`, resultID),
+	})
+}
+
+// ExportJob sends the WARC archive of a job's results
+func (h *SnapshotHandlers) ExportJob(c *gin.Context) {
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+	h.export(c, models.ArchiveFilter{JobID: &jobID})
+}
+
+// Export sends the WARC archive of the results crawled between the from and to query
+// parameters, optionally of one job_id
+func (h *SnapshotHandlers) Export(c *gin.Context) {
+	filter := models.ArchiveFilter{FromDate: c.Query("from"), ToDate: c.Query("to")}
+	if v := c.Query("job_id"); v != "" {
+		jobID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job_id"})
+			return
+		}
+		filter.JobID = &jobID
+	}
+	h.export(c, filter)
+}
+
+func (h *SnapshotHandlers) export(c *gin.Context, filter models.ArchiveFilter) {
+	if h.svc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshots are not enabled"})
+		return
+	}
+	snaps, err := h.svc.ArchivedSnapshots(c, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := service.ArchiveName(filter)
+	c.Header("Content-Type", "application/warc+gzip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	c.Status(http.StatusOK)
+	// the status is sent by now, so a failure can only cut the archive short
+	if err := h.svc.WriteWARC(c, c.Writer, snaps, name); err != nil {
+		logrus.WithError(err).Warnf("WARC export %s failed", name)
+		c.Abort()
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/service"
+)
+
+type TagHandlers struct {
+	svc *service.TagService
+}
+
+func NewTagHandlers(svc *service.TagService) *TagHandlers {
+	return &TagHandlers{svc: svc}
+}
+
+func (h *TagHandlers) List(c *gin.Context) {
+	tags, err := h.svc.ListTags(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+func (h *TagHandlers) Assign(c *gin.Context) {
+	var req models.TagURLsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tags and url_ids required"})
+		return
+	}
+	if err := h.svc.TagURLs(c, req.Tags, req.URLIDs); err != nil {
+		c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"tags": req.Tags, "url_ids": req.URLIDs}})
+}
+
+func (h *TagHandlers) Unassign(c *gin.Context) {
+	var req models.TagURLsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tags and url_ids required"})
+		return
+	}
+	removed, err := h.svc.UntagURLs(c, req.Tags, req.URLIDs)
+	if err != nil {
+		c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"removed": removed}})
+}
+
+// tagErrorStatus is 400 for invalid tags or URLs and 500 for a failed lookup or update
+func tagErrorStatus(err error) int {
+	for _, invalid := range []error{service.ErrEmptyTag, service.ErrTagTooLong, service.ErrNoTags, service.ErrNoURLs} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusInternalServerError
+}
@@ -37,8 +37,9 @@ func (h *URLHandlers) ListURLs(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	sortBy := c.DefaultQuery("sort_by", "created_at")
 	order := c.DefaultQuery("order", "desc")
+	filter := models.URLFilter{Tag: c.Query("tag"), ChangedWithin: c.Query("changed_within")}
 
-	resp, err := h.svc.ListURLs(c, page, limit, sortBy, order)
+	resp, err := h.svc.ListURLs(c, page, limit, sortBy, order, filter)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
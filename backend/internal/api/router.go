@@ -47,21 +47,70 @@ func RegisterRoutes(r *gin.Engine, cfg config.Config, deps Deps) {
 		secured.POST("/urls", urlHandlers.CreateURL)
 		secured.GET("/urls", urlHandlers.ListURLs)
 
+		// tags
+		tagHandlers := handlers.NewTagHandlers(deps.TagService)
+		secured.GET("/tags", tagHandlers.List)
+		secured.POST("/tags/assign", tagHandlers.Assign)
+		secured.POST("/tags/unassign", tagHandlers.Unassign)
+
+		// extraction rules
+		ruleHandlers := handlers.NewExtractionRuleHandlers(deps.ExtractionRuleService)
+		secured.GET("/extraction-rules", ruleHandlers.List)
+		secured.POST("/extraction-rules", ruleHandlers.Create)
+		secured.DELETE("/extraction-rules/:id", ruleHandlers.Delete)
+
+		// request profiles
+		profileHandlers := handlers.NewRequestProfileHandlers(deps.RequestProfileService)
+		secured.GET("/request-profiles", profileHandlers.List)
+		secured.POST("/request-profiles", profileHandlers.Create)
+		secured.DELETE("/request-profiles/:id", profileHandlers.Delete)
+
+		// login recipes
+		loginHandlers := handlers.NewLoginRecipeHandlers(deps.LoginRecipeService)
+		secured.GET("/login-recipes", loginHandlers.List)
+		secured.POST("/login-recipes", loginHandlers.Create)
+		secured.DELETE("/login-recipes/:id", loginHandlers.Delete)
+		secured.PUT("/urls/:id/login-recipe", loginHandlers.AssignToURL)
+
+		// proxy pools
+		proxyHandlers := handlers.NewProxyPoolHandlers(deps.ProxyService)
+		secured.GET("/proxy-pools", proxyHandlers.List)
+		secured.POST("/proxy-pools", proxyHandlers.Create)
+		secured.DELETE("/proxy-pools/:id", proxyHandlers.Delete)
+
+		// certificates
+		certHandlers := handlers.NewCertificateHandlers(deps.CertificateService)
+		secured.GET("/certificates", certHandlers.List)
+		secured.GET("/certificates/alerts", certHandlers.Alerts)
+
 		// jobs
 		jobHandlers := handlers.NewJobHandlers(deps.JobService)
 		secured.POST("/jobs/start", jobHandlers.Start)
 		secured.POST("/jobs/stop", jobHandlers.Stop)
 		secured.GET("/jobs/:id/status", jobHandlers.Status)
+		secured.GET("/analyzers", jobHandlers.Analyzers)
+		secured.GET("/metrics", jobHandlers.Metrics)
 
 		// results
 		resultHandlers := handlers.NewResultHandlers(deps.ResultService)
 		secured.GET("/results/:id", resultHandlers.GetByURLID)
+		snapshotHandlers := handlers.NewSnapshotHandlers(deps.SnapshotService)
+		secured.GET("/results/:id/snapshot", snapshotHandlers.Download)
+		secured.GET("/jobs/:id/warc", snapshotHandlers.ExportJob)
+		secured.GET("/warc", snapshotHandlers.Export)
 	}
 }
 
 // Deps contains runtime dependencies for handlers.
 type Deps struct {
-	URLService    *service.URLService
-	JobService    *service.JobService
-	ResultService *service.ResultService
+	URLService            *service.URLService
+	JobService            *service.JobService
+	ResultService         *service.ResultService
+	TagService            *service.TagService
+	ExtractionRuleService *service.ExtractionRuleService
+	CertificateService    *service.CertificateService
+	RequestProfileService *service.RequestProfileService
+	LoginRecipeService    *service.LoginRecipeService
+	ProxyService          *service.ProxyService
+	SnapshotService       *service.SnapshotService // optional
 }
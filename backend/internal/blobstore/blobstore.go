@@ -0,0 +1,57 @@
+// Package blobstore keeps opaque blobs, such as raw crawl snapshots, under slash-separated keys
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get for a key that holds no blob
+var ErrNotFound = errors.New("blob not found")
+
+// Store is a flat namespace of blobs
+type Store interface {
+	// Put stores data under key, replacing any blob already there
+	Put(ctx context.Context, key string, data []byte) error
+	// Get opens the blob under key, or returns ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key; a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects a store: Kind "fs" uses Dir, "s3" uses S3
+type Config struct {
+	Kind string
+	Dir  string
+	S3   S3Config
+}
+
+// Open returns the store cfg describes
+func Open(cfg Config) (Store, error) {
+	switch cfg.Kind {
+	case "fs":
+		return NewFS(cfg.Dir)
+	case "s3":
+		return NewS3(cfg.S3, &http.Client{Timeout: 30 * time.Second})
+	}
+	return nil, fmt.Errorf("unknown blob store %q (want fs or s3)", cfg.Kind)
+}
+
+// validKey rejects keys that could escape a store's root: keys are relative, slash-separated
+// paths without empty, "." or ".." segments
+func validKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
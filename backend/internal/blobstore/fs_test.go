@@ -0,0 +1,62 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testStore exercises the Store contract
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.Put(ctx, "snapshots/1/2.http.gz", []byte("first")); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if err := s.Put(ctx, "snapshots/1/2.http.gz", []byte("second")); err != nil {
+		t.Fatalf("overwrite failed: %v", err)
+	}
+	r, err := s.Get(ctx, "snapshots/1/2.http.gz")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "second" {
+		t.Errorf("expected the latest blob, got %q", data)
+	}
+
+	if err := s.Delete(ctx, "snapshots/1/2.http.gz"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := s.Delete(ctx, "snapshots/1/2.http.gz"); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed, got %v", err)
+	}
+	if _, err := s.Get(ctx, "snapshots/1/2.http.gz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	for _, key := range []string{"", "/abs", "a/../../b", "a//b", `a\b`} {
+		if err := s.Put(ctx, key, nil); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+func TestFS(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFS(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	// no temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Join(dir, "blobs", "snapshots", "1"))
+	if len(entries) != 0 {
+		t.Errorf("expected an empty directory, got %v", entries)
+	}
+}
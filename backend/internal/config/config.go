@@ -2,6 +2,11 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dysar/url-crawler/backend/internal/blobstore"
 )
 
 type Config struct {
@@ -12,6 +17,56 @@ type Config struct {
 	DBName     string
 	JWTSecret  string
 	APIPort    string
+	// CertAlertDays is how many days before expiry a certificate raises an alert
+	CertAlertDays int
+	// TechnologyRulesFile optionally points at a JSON rule set extending the bundled fingerprints
+	TechnologyRulesFile string
+	// TrackerListFile optionally points at a JSON tracker list extending the bundled one
+	TrackerListFile string
+	// MaxBodyBytes and MaxParseTime bound how much of a page is read and how long it is parsed
+	MaxBodyBytes int64
+	MaxParseTime time.Duration
+	// CrawlAllowlist lists private addresses, ranges and hosts the crawler may connect to anyway
+	CrawlAllowlist []string
+	// SecretsKey encrypts stored credentials; it is required and must differ from JWTSecret
+	SecretsKey string
+	// InaccessibleLinkClasses overrides which link error classes count as inaccessible
+	InaccessibleLinkClasses []string
+	// LinkCacheTTL is how long link check outcomes are reused; 0 turns the cache off.
+	// LinkCachePersist keeps them in the database as well.
+	LinkCacheTTL     time.Duration
+	LinkCachePersist bool
+	// SnapshotStore is where raw responses are kept: "fs", "s3", or empty to keep none
+	SnapshotStore      string
+	SnapshotDir        string
+	SnapshotS3Endpoint string
+	SnapshotS3Region   string
+	SnapshotS3Bucket   string
+	SnapshotS3Key      string
+	SnapshotS3Secret   string
+	// SnapshotMaxAge and SnapshotMaxPerURL bound how many snapshots are kept; 0 means no bound
+	SnapshotMaxAge    time.Duration
+	SnapshotMaxPerURL int
+	// WARCArchive also keeps each snapshot as WARC records, for WARC exports
+	WARCArchive bool
+	// WARCMaxAge bounds how long WARC records are kept, apart from their snapshots; 0 keeps
+	// them forever
+	WARCMaxAge time.Duration
+}
+
+// SnapshotStoreConfig is the blob store configuration of the snapshot store
+func (c Config) SnapshotStoreConfig() blobstore.Config {
+	return blobstore.Config{
+		Kind: c.SnapshotStore,
+		Dir:  c.SnapshotDir,
+		S3: blobstore.S3Config{
+			Endpoint:  c.SnapshotS3Endpoint,
+			Region:    c.SnapshotS3Region,
+			Bucket:    c.SnapshotS3Bucket,
+			AccessKey: c.SnapshotS3Key,
+			SecretKey: c.SnapshotS3Secret,
+		},
+	}
 }
 
 func getenv(key, def string) string {
@@ -22,6 +77,49 @@ func getenv(key, def string) string {
 	return v
 }
 
+func getenvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func getenvDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+// linkCacheTTL reads LINK_CACHE_TTL, where "0" turns the cache off
+func linkCacheTTL() time.Duration {
+	if os.Getenv("LINK_CACHE_TTL") == "0" {
+		return 0
+	}
+	return getenvDuration("LINK_CACHE_TTL", time.Hour)
+}
+
+// snapshotMaxAge reads SNAPSHOT_MAX_AGE, where "0" keeps snapshots regardless of age
+func snapshotMaxAge() time.Duration {
+	if os.Getenv("SNAPSHOT_MAX_AGE") == "0" {
+		return 0
+	}
+	return getenvDuration("SNAPSHOT_MAX_AGE", 30*24*time.Hour)
+}
+
+// getenvList splits a comma-separated variable, dropping empty entries
+func getenvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func Load() Config {
 	return Config{
 		DBHost:     getenv("DB_HOST", "127.0.0.1"),
@@ -31,5 +129,29 @@ func Load() Config {
 		DBName:     getenv("DB_NAME", "url_crawler"),
 		JWTSecret:  getenv("JWT_SECRET", "dev-secret-change"),
 		APIPort:    getenv("API_PORT", "8080"),
+
+		CertAlertDays:       getenvInt("CERT_ALERT_DAYS", 30),
+		TechnologyRulesFile: os.Getenv("TECHNOLOGY_RULES_FILE"),
+		TrackerListFile:     os.Getenv("TRACKER_LIST_FILE"),
+		MaxBodyBytes:        int64(getenvInt("MAX_BODY_BYTES", 10<<20)),
+		MaxParseTime:        getenvDuration("MAX_PARSE_TIME", 15*time.Second),
+		CrawlAllowlist:      getenvList("CRAWL_ALLOWLIST"),
+		SecretsKey:          os.Getenv("SECRETS_KEY"),
+
+		InaccessibleLinkClasses: getenvList("INACCESSIBLE_LINK_CLASSES"),
+		LinkCacheTTL:            linkCacheTTL(),
+		LinkCachePersist:        os.Getenv("LINK_CACHE_PERSIST") == "true",
+
+		SnapshotStore:      os.Getenv("SNAPSHOT_STORE"),
+		SnapshotDir:        getenv("SNAPSHOT_DIR", "data/snapshots"),
+		SnapshotS3Endpoint: os.Getenv("SNAPSHOT_S3_ENDPOINT"),
+		SnapshotS3Region:   getenv("SNAPSHOT_S3_REGION", "us-east-1"),
+		SnapshotS3Bucket:   os.Getenv("SNAPSHOT_S3_BUCKET"),
+		SnapshotS3Key:      os.Getenv("SNAPSHOT_S3_ACCESS_KEY"),
+		SnapshotS3Secret:   os.Getenv("SNAPSHOT_S3_SECRET_KEY"),
+		SnapshotMaxAge:     snapshotMaxAge(),
+		SnapshotMaxPerURL:  getenvInt("SNAPSHOT_MAX_PER_URL", 10),
+		WARCArchive:        os.Getenv("WARC_ARCHIVE") == "true",
+		WARCMaxAge:         getenvDuration("WARC_MAX_AGE", 0),
 	}
 }
@@ -0,0 +1,281 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// maxSnippetLength bounds the HTML snippet stored per finding
+const maxSnippetLength = 200
+
+// A11yFinding is one accessibility problem found on a page
+type A11yFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	WCAG     string `json:"wcag"` // success criterion, e.g. "1.1.1"
+	Message  string `json:"message"`
+	Selector string `json:"selector,omitempty"`
+	Snippet  string `json:"snippet,omitempty"`
+}
+
+type A11yFindings struct {
+	// Score is 100 minus penalties for errors and warnings, floored at 0
+	Score    int           `json:"score"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	Findings []A11yFinding `json:"findings"`
+}
+
+// score penalties per finding, capped per rule so a single repeated problem can't zero the score
+const (
+	a11yErrorPenalty   = 10
+	a11yWarningPenalty = 3
+	a11yRulePenaltyCap = 30
+)
+
+// AccessibilityAnalyzer runs basic WCAG checks: image alternatives, page language and title,
+// form labels, empty links and buttons, heading order and duplicate ids
+type AccessibilityAnalyzer struct{}
+
+func (AccessibilityAnalyzer) Name() string { return "accessibility" }
+
+func (AccessibilityAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	a := &a11yAudit{labelFor: make(map[string]bool), ids: make(map[string]int)}
+	outline := page.Outline()
+
+	// form controls are judged after the walk, once every <label for> is known
+	var htmlEl *html.Node
+	var controls []*html.Node
+	walkElements(page.Document(), func(n *html.Node) bool {
+		if id, ok := nodeAttr(n, "id"); ok && id != "" {
+			a.ids[id]++
+			if a.ids[id] == 2 {
+				a.add(n, "duplicate-id", SeverityWarning, "4.1.1", fmt.Sprintf("id %q is used more than once", id))
+			}
+		}
+		switch n.Data {
+		case "html":
+			htmlEl = n
+		case "label":
+			if f, ok := nodeAttr(n, "for"); ok {
+				a.labelFor[f] = true
+			}
+		case "img":
+			if _, ok := nodeAttr(n, "alt"); !ok && !ariaHidden(n) && !hasAccessibleName(n) {
+				a.add(n, "image-alt", SeverityError, "1.1.1", "image has no alt attribute")
+			}
+		case "input", "select", "textarea":
+			if needsLabel(n) {
+				controls = append(controls, n)
+			}
+		case "a":
+			if _, ok := nodeAttr(n, "href"); ok && !ariaHidden(n) && accessibleText(n) == "" {
+				a.add(n, "empty-link", SeverityError, "2.4.4", "link has no discernible text")
+			}
+		case "button":
+			if !ariaHidden(n) && accessibleText(n) == "" {
+				a.add(n, "empty-button", SeverityError, "4.1.2", "button has no discernible text")
+			}
+		}
+		return true
+	})
+	for _, n := range controls {
+		if !a.labelled(n) {
+			a.add(n, "form-label", SeverityError, "1.3.1", "form control has no associated label")
+		}
+	}
+
+	lastHeading := 0
+	for _, n := range outline.Headings {
+		level := headingLevel(n)
+		if lastHeading > 0 && level > lastHeading+1 {
+			a.add(n, "heading-order", SeverityWarning, "1.3.1",
+				fmt.Sprintf("heading level skipped from h%d to h%d", lastHeading, level))
+		} else if lastHeading == 0 && level > 1 {
+			a.add(n, "heading-order", SeverityWarning, "1.3.1",
+				fmt.Sprintf("first heading is h%d instead of h1", level))
+		}
+		lastHeading = level
+	}
+
+	if htmlEl == nil {
+		a.add(nil, "html-lang", SeverityError, "3.1.1", "document has no <html> element with a lang attribute")
+	} else if lang, _ := nodeAttr(htmlEl, "lang"); strings.TrimSpace(lang) == "" {
+		a.add(htmlEl, "html-lang", SeverityError, "3.1.1", "<html> element has no lang attribute")
+	}
+	if outline.Title == nil || *outline.Title == "" {
+		a.add(nil, "document-title", SeverityError, "2.4.2", "page has no title")
+	}
+	if len(outline.Headings) == 0 {
+		a.add(nil, "page-has-heading", SeverityWarning, "1.3.1", "page has no headings")
+	}
+
+	return a.result(), nil
+}
+
+type a11yAudit struct {
+	findings []A11yFinding
+	labelFor map[string]bool
+	ids      map[string]int
+}
+
+func (a *a11yAudit) add(n *html.Node, rule, severity, wcag, msg string) {
+	f := A11yFinding{Rule: rule, Severity: severity, WCAG: wcag, Message: msg}
+	if n != nil {
+		f.Selector = cssPath(n)
+		f.Snippet = snippet(n)
+	}
+	a.findings = append(a.findings, f)
+}
+
+func (a *a11yAudit) labelled(n *html.Node) bool {
+	if hasAccessibleName(n) {
+		return true
+	}
+	if id, ok := nodeAttr(n, "id"); ok && a.labelFor[id] {
+		return true
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "label" {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *a11yAudit) result() A11yFindings {
+	out := A11yFindings{Score: 100, Findings: a.findings}
+	if out.Findings == nil {
+		out.Findings = []A11yFinding{}
+	}
+	penalties := make(map[string]int)
+	for _, f := range a.findings {
+		p := a11yWarningPenalty
+		if f.Severity == SeverityError {
+			p = a11yErrorPenalty
+			out.Errors++
+		} else {
+			out.Warnings++
+		}
+		penalties[f.Rule] = min(penalties[f.Rule]+p, a11yRulePenaltyCap)
+	}
+	for _, p := range penalties {
+		out.Score -= p
+	}
+	out.Score = max(out.Score, 0)
+	return out
+}
+
+// needsLabel reports whether a form control must have a label (hidden inputs and buttons don't)
+func needsLabel(n *html.Node) bool {
+	if n.Data != "input" {
+		return true
+	}
+	typ, _ := nodeAttr(n, "type")
+	switch strings.ToLower(strings.TrimSpace(typ)) {
+	case "hidden", "submit", "reset", "button", "image":
+		return false
+	}
+	return true
+}
+
+func hasAccessibleName(n *html.Node) bool {
+	for _, key := range []string{"aria-label", "aria-labelledby", "title"} {
+		if v, ok := nodeAttr(n, key); ok && strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func ariaHidden(n *html.Node) bool {
+	v, _ := nodeAttr(n, "aria-hidden")
+	return strings.EqualFold(strings.TrimSpace(v), "true")
+}
+
+// accessibleText approximates the accessible name of a link or button: aria attributes,
+// text content and the alt text of contained images
+func accessibleText(n *html.Node) string {
+	if hasAccessibleName(n) {
+		return "name"
+	}
+	if text := textContent(n); text != "" {
+		return text
+	}
+	alt := ""
+	walkElements(n, func(c *html.Node) bool {
+		if c.Data == "img" {
+			if v, ok := nodeAttr(c, "alt"); ok && strings.TrimSpace(v) != "" {
+				alt = v
+			}
+		}
+		if c.Data == "svg" || c.Data == "input" {
+			if hasAccessibleName(c) {
+				alt = "name"
+			}
+		}
+		return alt == ""
+	})
+	return alt
+}
+
+// cssPath builds a selector for n that is unique within the document, anchored at the
+// nearest ancestor with an id
+func cssPath(n *html.Node) string {
+	var parts []string
+	for c := n; c != nil && c.Type == html.ElementNode; c = c.Parent {
+		if id, ok := nodeAttr(c, "id"); ok && id != "" && !strings.ContainsAny(id, " \t\n") {
+			parts = append(parts, "#"+id)
+			break
+		}
+		part := c.Data
+		if c.Parent != nil && c.Parent.Type == html.ElementNode {
+			pos := 1
+			for s := prevElementSibling(c); s != nil; s = prevElementSibling(s) {
+				pos++
+			}
+			part += fmt.Sprintf(":nth-child(%d)", pos)
+		}
+		parts = append(parts, part)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+// snippet renders the opening tag of n, truncated to maxSnippetLength
+func snippet(n *html.Node) string {
+	shallow := &html.Node{Type: n.Type, Data: n.Data, DataAtom: n.DataAtom, Attr: n.Attr}
+	var b bytes.Buffer
+	if err := html.Render(&b, shallow); err != nil {
+		return ""
+	}
+	s := b.String()
+	if end := "</" + n.Data + ">"; strings.HasSuffix(s, end) {
+		s = s[:len(s)-len(end)]
+	}
+	return truncate(s, maxSnippetLength)
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
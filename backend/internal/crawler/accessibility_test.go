@@ -0,0 +1,90 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAccessibility_ReportsCommonProblems(t *testing.T) {
+	out := crawlFindings[A11yFindings](t, `<!doctype html><html><head></head><body>
+      <h1>Shop</h1>
+      <h3>Skipped</h3>
+      <img src="/logo.png">
+      <img src="/spacer.gif" alt="">
+      <a href="/cart"></a>
+      <a href="/home"><img src="/home.png" alt="Home"></a>
+      <button></button>
+      <button aria-label="Close">x</button>
+      <form>
+        <input type="text" name="q">
+        <label for="email">Email</label><input type="email" id="email">
+        <label>Name <input type="text" name="name"></label>
+        <input type="hidden" name="csrf">
+        <input type="submit" value="Go">
+      </form>
+      <div id="dup"></div><p id="dup">second</p>
+    </body></html>`, "accessibility")
+
+	rules := map[string]int{}
+	for _, f := range out.Findings {
+		rules[f.Rule]++
+		if f.Selector == "" && f.Rule != "document-title" {
+			t.Errorf("finding %s has no selector", f.Rule)
+		}
+	}
+	want := map[string]int{
+		"heading-order":  1,
+		"image-alt":      1,
+		"empty-link":     1,
+		"empty-button":   1,
+		"form-label":     1,
+		"duplicate-id":   1,
+		"html-lang":      1,
+		"document-title": 1,
+	}
+	for rule, n := range want {
+		if rules[rule] != n {
+			t.Errorf("expected %d %s findings, got %d (%+v)", n, rule, rules[rule], out.Findings)
+		}
+	}
+	if out.Errors != 6 || out.Warnings != 2 {
+		t.Fatalf("expected 6 errors and 2 warnings, got %d/%d", out.Errors, out.Warnings)
+	}
+	for _, f := range out.Findings {
+		if f.Rule == "duplicate-id" && (f.Severity != SeverityWarning || f.WCAG != "4.1.1" || !strings.HasPrefix(f.Snippet, "<p")) {
+			t.Errorf("expected the second #dup to be reported as a 4.1.1 warning, got %+v", f)
+		}
+	}
+	if out.Score != 100-6*a11yErrorPenalty-2*a11yWarningPenalty {
+		t.Fatalf("unexpected score %d", out.Score)
+	}
+}
+
+func TestAccessibility_CleanPage(t *testing.T) {
+	out := crawlFindings[A11yFindings](t, `<!doctype html><html lang="en"><head><title>Clean</title></head><body>
+      <h1>Title</h1><h2>Section</h2><h2>Other</h2><h3>Sub</h3>
+      <img src="/a.png" alt="A chart">
+      <a href="/next">Next</a>
+    </body></html>`, "accessibility")
+
+	if out.Score != 100 || len(out.Findings) != 0 {
+		t.Fatalf("expected perfect score, got %d with %+v", out.Score, out.Findings)
+	}
+}
+
+func TestAccessibility_SelectorAndSnippet(t *testing.T) {
+	out := crawlFindings[A11yFindings](t, `<!doctype html><html lang="en"><head><title>T</title></head><body>
+      <h1>T</h1><div id="gallery"><p>text</p><img src="/x.png" class="photo"></div>
+    </body></html>`, "accessibility")
+
+	if len(out.Findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", out.Findings)
+	}
+	f := out.Findings[0]
+	if f.Selector != "#gallery > img:nth-child(2)" {
+		t.Fatalf("unexpected selector %q", f.Selector)
+	}
+	if f.Snippet != `<img src="/x.png" class="photo"/>` {
+		t.Fatalf("unexpected snippet %q", f.Snippet)
+	}
+}
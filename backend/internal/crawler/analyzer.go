@@ -0,0 +1,218 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// Page is everything an analyzer gets to see of a fetched document
+type Page struct {
+	URL        *url.URL // URL that was requested
+	BaseURL    *url.URL // URL relative references resolve against (honours <base href>)
+	StatusCode int
+	Header     http.Header
+	TLS        *tls.ConnectionState // nil for plain HTTP
+	Body       []byte
+	Tokens     []html.Token // every token of Body in document order
+	Options    Options      // options the crawl was started with
+	Fetcher    Fetcher      // client the page was fetched with, for analyzers that request more
+	BodyBytes  int64        // size of the body as read, before charset decoding
+	Truncated  bool         // the body was cut at the size limit
+
+	// deadline is when tokenizing, building the tree and analysing have to be done; zero for
+	// no limit
+	deadline    time.Time
+	docOnce     sync.Once
+	doc         *html.Node
+	docCut      bool // the tree was cut short by the deadline
+	outlineOnce sync.Once
+	outline     *Outline
+}
+
+// newPage tokenizes the body before the deadline (zero for no limit). When time runs out, the
+// page holds the part of the body that was tokenized and complete is false.
+func newPage(u *url.URL, resp *http.Response, body []byte, deadline time.Time) (page *Page, complete bool) {
+	var timeout time.Duration
+	if !deadline.IsZero() {
+		timeout = max(time.Until(deadline), time.Nanosecond)
+	}
+	tokens, n, complete := tokenizeWithin(body, timeout)
+	return &Page{
+		URL:        u,
+		BaseURL:    documentBase(u, tokens),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		TLS:        resp.TLS,
+		Body:       body[:n],
+		Tokens:     tokens,
+		deadline:   deadline,
+	}, complete
+}
+
+// Document returns the parsed DOM of the page. The tree is built on first use
+// so token-only analyzers don't pay for it. When the parse deadline passes while it is built,
+// the tree holds the part of the body parsed so far.
+func (p *Page) Document() *html.Node {
+	p.docOnce.Do(func() {
+		r := &deadlineReader{r: bytes.NewReader(p.Body), deadline: p.deadline}
+		doc, err := html.Parse(r)
+		if err != nil {
+			// html.Parse only fails on reader errors, which deadlineReader doesn't produce
+			doc = &html.Node{Type: html.DocumentNode}
+		}
+		p.doc, p.docCut = doc, r.cut
+	})
+	return p.doc
+}
+
+// Outline returns the title, headings and links of the page, found in one walk over its
+// document on first use
+func (p *Page) Outline() *Outline {
+	p.outlineOnce.Do(func() { p.outline = outlineOf(p) })
+	return p.outline
+}
+
+// Resolve resolves a reference found in the page against its base URL
+func (p *Page) Resolve(ref string) (*url.URL, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	return p.BaseURL.ResolveReference(u), nil
+}
+
+func tokenize(body []byte) []html.Token {
+	tokens, _, _ := tokenizeWithin(body, 0)
+	return tokens
+}
+
+// Analyzer inspects a page and returns named findings that are stored with the crawl result.
+// The returned value must be JSON-serialisable. Analyzers are shared between workers,
+// so Analyze must not keep per-page state on the receiver.
+type Analyzer interface {
+	Name() string
+	Analyze(ctx context.Context, page *Page) (any, error)
+}
+
+// ResultAnalyzer is an analyzer whose findings fill fields of Result, such as the title or
+// the link counts, rather than only being stored under its name
+type ResultAnalyzer interface {
+	Analyzer
+	// Apply copies findings, as returned by Analyze, into res and returns what to store under
+	// the analyzer's name in Result.Findings, or nil to store nothing
+	Apply(res *Result, findings any) any
+}
+
+// Registry holds the analyzers a crawler can run, in registration order
+type Registry struct {
+	mu        sync.RWMutex
+	analyzers map[string]Analyzer
+	order     []string
+}
+
+func NewRegistry(analyzers ...Analyzer) *Registry {
+	r := &Registry{analyzers: make(map[string]Analyzer)}
+	for _, a := range analyzers {
+		r.Register(a)
+	}
+	return r
+}
+
+// DefaultRegistry returns a registry with all built-in analyzers
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		TitleAnalyzer{},
+		HeadingsAnalyzer{},
+		LinksAnalyzer{},
+		DoctypeAnalyzer{},
+		ContentAnalyzer{},
+		FormsAnalyzer{},
+		NewExtractAnalyzer(),
+		StructuredDataAnalyzer{},
+		AccessibilityAnalyzer{},
+		SecurityAnalyzer{},
+		MixedContentAnalyzer{},
+		ResourceAnalyzer{},
+		defaultFingerprintAnalyzer(),
+		defaultTrackerAnalyzer(),
+		ValidationAnalyzer{},
+	)
+}
+
+// Register adds an analyzer, replacing any previously registered analyzer of the same name
+func (r *Registry) Register(a Analyzer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.analyzers[a.Name()]; !exists {
+		r.order = append(r.order, a.Name())
+	}
+	r.analyzers[a.Name()] = a
+}
+
+// Names returns the names of all registered analyzers, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := append([]string(nil), r.order...)
+	sort.Strings(names)
+	return names
+}
+
+// Resolve looks up analyzers by name. A nil slice selects every registered analyzer,
+// an empty one selects none.
+func (r *Registry) Resolve(names []string) ([]Analyzer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if names == nil {
+		out := make([]Analyzer, 0, len(r.order))
+		for _, name := range r.order {
+			out = append(out, r.analyzers[name])
+		}
+		return out, nil
+	}
+	out := make([]Analyzer, 0, len(names))
+	for _, name := range names {
+		a, ok := r.analyzers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown analyzer: %s", name)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// runAnalyzers runs each analyzer on the page and fills res with the findings. A failing
+// analyzer doesn't fail the crawl; its error is recorded in place of its findings.
+func runAnalyzers(ctx context.Context, analyzers []Analyzer, page *Page, res *Result) {
+	res.Findings = make(map[string]any, len(analyzers))
+	for _, a := range analyzers {
+		if err := ctx.Err(); err != nil {
+			// out of time; the remaining analyzers don't run
+			logrus.Warnf("Skipping analyzer %s for %s: %v", a.Name(), page.URL, err)
+			res.Findings[a.Name()] = map[string]string{"error": err.Error()}
+			continue
+		}
+		out, err := a.Analyze(ctx, page)
+		if err != nil {
+			logrus.Warnf("Analyzer %s failed for %s: %v", a.Name(), page.URL, err)
+			res.Findings[a.Name()] = map[string]string{"error": err.Error()}
+			continue
+		}
+		if ra, ok := a.(ResultAnalyzer); ok {
+			if out = ra.Apply(res, out); out == nil {
+				continue
+			}
+		}
+		res.Findings[a.Name()] = out
+	}
+}
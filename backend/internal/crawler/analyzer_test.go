@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// pageAnalyzers fill the summary fields of Result without running the heavier analyzers
+var pageAnalyzers = []string{"title", "headings", "links", "doctype", "content", "forms"}
+
+// countingAnalyzer counts start tags via tokens and element nodes via the DOM
+type countingAnalyzer struct{ tag string }
+
+func (a countingAnalyzer) Name() string { return "count_" + a.tag }
+
+func (a countingAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	tokens := 0
+	for _, t := range page.Tokens {
+		if t.Type == html.StartTagToken && t.Data == a.tag {
+			tokens++
+		}
+	}
+	nodes := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == a.tag {
+			nodes++
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(page.Document())
+	return map[string]int{"tokens": tokens, "nodes": nodes}, nil
+}
+
+type failingAnalyzer struct{}
+
+func (failingAnalyzer) Name() string { return "failing" }
+
+func (failingAnalyzer) Analyze(context.Context, *Page) (any, error) {
+	return nil, errors.New("boom")
+}
+
+// htmlHandler serves body as a UTF-8 HTML page
+func htmlHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func serveHTML(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(htmlHandler(body))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestCrawl_RunsRegisteredAnalyzers(t *testing.T) {
+	ts := serveHTML(t, `<!doctype html><html><body><p>a</p><p>b</p></body></html>`)
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	c.Analyzers().Register(countingAnalyzer{tag: "p"})
+	c.Analyzers().Register(failingAnalyzer{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := c.Crawl(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	counts, ok := res.Findings["count_p"].(map[string]int)
+	if !ok {
+		t.Fatalf("expected count_p findings, got %#v", res.Findings["count_p"])
+	}
+	if counts["tokens"] != 2 || counts["nodes"] != 2 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+	if failure, ok := res.Findings["failing"].(map[string]string); !ok || failure["error"] != "boom" {
+		t.Fatalf("expected failing analyzer error to be recorded, got %#v", res.Findings["failing"])
+	}
+	if _, ok := res.Findings["forms"]; !ok {
+		t.Fatalf("expected built-in forms analyzer to run, got %#v", res.Findings)
+	}
+}
+
+func TestCrawl_AnalyzerSelection(t *testing.T) {
+	ts := serveHTML(t, `<!doctype html><html><body><p>a</p></body></html>`)
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	c.Analyzers().Register(countingAnalyzer{tag: "p"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"count_p"}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if len(res.Findings) != 1 || res.Findings["count_p"] == nil {
+		t.Fatalf("expected only count_p findings, got %#v", res.Findings)
+	}
+
+	res, err = c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if len(res.Findings) != 0 {
+		t.Fatalf("expected no findings, got %#v", res.Findings)
+	}
+
+	if _, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"nope"}}); err == nil {
+		t.Fatalf("expected error for unknown analyzer")
+	}
+}
+
+func TestCrawl_ResultAnalyzersFillResult(t *testing.T) {
+	var checked []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checked = append(checked, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<!doctype html><html><head><base href="/docs/"><title> Guide </title></head><body>
+			<h1>a</h1><h2>b</h2><h2>c</h2><svg><title>icon</title></svg>
+			<a href="intro">intro</a><a href="http://external.invalid/">out</a><a href="#top">top</a><a href="mailto:x@example.com">mail</a>
+		</body></html>`))
+	}))
+	t.Cleanup(ts.Close)
+	c := New(ts.Client())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"title", "headings", "links", "doctype"}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Title == nil || *res.Title != "Guide" {
+		t.Errorf("expected the page title, got %v", res.Title)
+	}
+	if res.Headings["h1"] != 1 || res.Headings["h2"] != 2 || res.Headings["h3"] != 0 {
+		t.Errorf("unexpected headings: %v", res.Headings)
+	}
+	if res.InternalLinks != 1 || res.ExternalLinks != 1 {
+		t.Errorf("expected 1 internal and 1 external link, got %d and %d", res.InternalLinks, res.ExternalLinks)
+	}
+	if res.HTMLVersion == nil || *res.HTMLVersion != "HTML5" || res.DocumentMode != ModeNoQuirks {
+		t.Errorf("unexpected doctype: %v %s", res.HTMLVersion, res.DocumentMode)
+	}
+	if len(res.Findings) != 0 {
+		t.Errorf("expected analyzers that fill the result to keep nothing in Findings, got %#v", res.Findings)
+	}
+	// the relative link resolves against <base>; the external one isn't reachable from here
+	if len(checked) < 2 || checked[1] != "HEAD /docs/intro" {
+		t.Errorf("expected the internal link to be checked against the base URL, got %v", checked)
+	}
+
+	checked = nil
+	res, err = c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"title"}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.InternalLinks != 0 || len(checked) != 1 {
+		t.Errorf("expected no links to be collected or checked without the links analyzer, got %v", checked)
+	}
+}
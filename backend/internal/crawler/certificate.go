@@ -0,0 +1,91 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"time"
+)
+
+// CertificateInfo describes the leaf certificate a site presented and whether it validated
+type CertificateInfo struct {
+	Host          string
+	Subject       string
+	Issuer        string
+	SANs          []string
+	NotBefore     time.Time
+	NotAfter      time.Time
+	ChainValid    bool
+	HostnameMatch bool
+	// Error is the verification error, empty when the certificate validated
+	Error string
+}
+
+// certificateInfo builds the certificate record of a TLS connection to host. Connections made
+// by a verifying client carry their verified chains; otherwise the chain is checked against
+// the system roots here.
+func certificateInfo(host string, state *tls.ConnectionState) *CertificateInfo {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	info := newCertificateInfo(host, state.PeerCertificates[0])
+	if len(state.VerifiedChains) > 0 {
+		info.ChainValid = true
+	} else {
+		intermediates := x509.NewCertPool()
+		for _, c := range state.PeerCertificates[1:] {
+			intermediates.AddCert(c)
+		}
+		_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{Intermediates: intermediates})
+		info.ChainValid = err == nil
+		if err != nil {
+			info.Error = err.Error()
+		}
+	}
+	if err := state.PeerCertificates[0].VerifyHostname(host); err != nil {
+		info.HostnameMatch = false
+		if info.Error == "" {
+			info.Error = err.Error()
+		}
+	}
+	return info
+}
+
+// certificateFromError recovers the certificate of a fetch that failed TLS verification
+func certificateFromError(host string, err error) *CertificateInfo {
+	var verr *tls.CertificateVerificationError
+	if !errors.As(err, &verr) || len(verr.UnverifiedCertificates) == 0 {
+		return nil
+	}
+	leaf := verr.UnverifiedCertificates[0]
+	info := newCertificateInfo(host, leaf)
+	info.Error = verr.Err.Error()
+
+	var hostErr x509.HostnameError
+	if errors.As(verr.Err, &hostErr) {
+		// the chain is only reported invalid if it fails on its own
+		info.HostnameMatch = false
+		intermediates := x509.NewCertPool()
+		for _, c := range verr.UnverifiedCertificates[1:] {
+			intermediates.AddCert(c)
+		}
+		_, chainErr := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates})
+		info.ChainValid = chainErr == nil
+	} else {
+		info.ChainValid = false
+		info.HostnameMatch = leaf.VerifyHostname(host) == nil
+	}
+	return info
+}
+
+func newCertificateInfo(host string, leaf *x509.Certificate) *CertificateInfo {
+	return &CertificateInfo{
+		Host:          host,
+		Subject:       certName(leaf.Subject.CommonName, leaf.Subject.String()),
+		Issuer:        certName(leaf.Issuer.CommonName, leaf.Issuer.String()),
+		SANs:          certSANs(leaf),
+		NotBefore:     leaf.NotBefore.UTC(),
+		NotAfter:      leaf.NotAfter.UTC(),
+		HostnameMatch: true,
+	}
+}
@@ -0,0 +1,75 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCrawl_RecordsCertificateOfHTTPSPage(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := New(ts.Client()).Crawl(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	cert := res.Certificate
+	if cert == nil {
+		t.Fatal("expected certificate")
+	}
+	if cert.Host != "127.0.0.1" || !cert.ChainValid || !cert.HostnameMatch || cert.Error != "" {
+		t.Fatalf("expected valid certificate, got %+v", cert)
+	}
+	if cert.NotAfter.Before(time.Now()) || len(cert.SANs) == 0 {
+		t.Fatalf("unexpected certificate details: %+v", cert)
+	}
+}
+
+func TestCrawl_RecordsCertificateThatFailsVerification(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the default client doesn't trust the test server's CA
+	res, err := New(HTTPClient(5*time.Second, WithoutAddressGuard())).Crawl(ctx, ts.URL)
+	if err == nil {
+		t.Fatal("expected verification error")
+	}
+	cert := res.Certificate
+	if cert == nil {
+		t.Fatalf("expected certificate alongside error %v", err)
+	}
+	if cert.ChainValid || !cert.HostnameMatch || cert.Error == "" {
+		t.Fatalf("expected untrusted chain with matching hostname, got %+v", cert)
+	}
+}
+
+func TestCrawl_RecordsCertificateHostnameMismatch(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the test certificate covers 127.0.0.1 but not localhost
+	target := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+	res, err := New(ts.Client()).Crawl(ctx, target)
+	if err == nil {
+		t.Fatal("expected verification error")
+	}
+	if res.Certificate == nil || res.Certificate.HostnameMatch || res.Certificate.Host != "localhost" {
+		t.Fatalf("expected hostname mismatch, got %+v", res.Certificate)
+	}
+}
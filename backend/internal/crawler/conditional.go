@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Validators identify the version of a page seen by an earlier crawl
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+func (v *Validators) empty() bool {
+	return v == nil || (v.ETag == "" && v.LastModified == "")
+}
+
+// apply makes req conditional on the page having changed. Servers prefer If-None-Match when
+// both are sent.
+func (v *Validators) apply(req *http.Request) {
+	if v == nil {
+		return
+	}
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// validatorsOf returns the validators of resp, keeping those of prev the server didn't repeat
+// (a 304 may leave them out)
+func validatorsOf(resp *http.Response, prev *Validators) Validators {
+	v := Validators{
+		ETag:         strings.TrimSpace(resp.Header.Get("ETag")),
+		LastModified: strings.TrimSpace(resp.Header.Get("Last-Modified")),
+	}
+	if resp.StatusCode == http.StatusNotModified && prev != nil {
+		if v.ETag == "" {
+			v.ETag = prev.ETag
+		}
+		if v.LastModified == "" {
+			v.LastModified = prev.LastModified
+		}
+	}
+	return v
+}
@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawl_ConditionalFetch(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	var linkChecks atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><a href="/about">about</a></body></html>`))
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) { linkChecks.Add(1) })
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	ctx := context.Background()
+	first, err := c.Crawl(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if first.Validators.ETag != etag || first.Validators.LastModified != lastModified {
+		t.Fatalf("expected the validators to be recorded, got %+v", first.Validators)
+	}
+
+	second, err := c.CrawlWithOptions(ctx, ts.URL, Options{Conditional: &first.Validators})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if second.Outcome != OutcomeNotModified {
+		t.Fatalf("expected %s, got %s", OutcomeNotModified, second.Outcome)
+	}
+	if second.Validators != first.Validators {
+		t.Errorf("expected a 304 without validators to keep the previous ones, got %+v", second.Validators)
+	}
+	if n := linkChecks.Load(); n != 1 {
+		t.Errorf("expected an unchanged page not to be link-checked again, got %d checks", n)
+	}
+
+	// a stale ETag gets the page in full
+	third, err := c.CrawlWithOptions(ctx, ts.URL, Options{Conditional: &Validators{ETag: `"v0"`}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if third.Outcome != OutcomeOK || third.InternalLinks != 1 {
+		t.Errorf("expected a full crawl, got outcome %s with %d links", third.Outcome, third.InternalLinks)
+	}
+}
+
+func TestCrawl_UnsolicitedNotModified(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer ts.Close()
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	res, err := c.CrawlWithOptions(context.Background(), ts.URL, Options{Conditional: &Validators{}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Outcome == OutcomeNotModified {
+		t.Error("expected a 304 to an unconditional request not to count as unchanged")
+	}
+}
@@ -0,0 +1,120 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// simHashShingle is the number of consecutive words hashed together
+const simHashShingle = 3
+
+// ContentFingerprint identifies what a page says, ignoring its markup
+type ContentFingerprint struct {
+	// Hash is the hex SHA-256 of the visible text with whitespace collapsed; any edit changes it
+	Hash string
+	// SimHash is a 64-bit simhash of the visible text. Similar texts get hashes that differ in
+	// few bits (see Similarity).
+	SimHash uint64
+}
+
+// Similarity compares two simhashes: 1 for the same text, around 0.5 for unrelated texts
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// fingerprintContent fingerprints the text a browser would show for tokens
+func fingerprintContent(tokens []html.Token) ContentFingerprint {
+	text := visibleText(tokens)
+	sum := sha256.Sum256([]byte(text))
+	return ContentFingerprint{Hash: hex.EncodeToString(sum[:]), SimHash: simHash(text)}
+}
+
+// ContentAnalyzer fingerprints the visible text of the page, to tell whether it changed
+type ContentAnalyzer struct{}
+
+func (ContentAnalyzer) Name() string { return "content" }
+
+func (ContentAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	return fingerprintContent(page.Tokens), nil
+}
+
+func (ContentAnalyzer) Apply(res *Result, findings any) any {
+	res.Content, _ = findings.(ContentFingerprint)
+	return nil
+}
+
+// visibleText joins the text of tokens outside script, style and other invisible elements,
+// collapsing whitespace
+func visibleText(tokens []html.Token) string {
+	var b strings.Builder
+	hidden := 0
+	for _, t := range tokens {
+		switch t.Type {
+		case html.StartTagToken:
+			if isInvisible(t.Data) {
+				hidden++
+			}
+		case html.EndTagToken:
+			if isInvisible(t.Data) && hidden > 0 {
+				hidden--
+			}
+		case html.TextToken:
+			if hidden > 0 {
+				continue
+			}
+			for _, word := range strings.Fields(t.Data) {
+				if b.Len() > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(word)
+			}
+		}
+	}
+	return b.String()
+}
+
+func isInvisible(tag string) bool {
+	switch strings.ToLower(tag) {
+	case "script", "style", "noscript", "template", "svg":
+		return true
+	}
+	return false
+}
+
+// simHash computes the simhash of text's lowercased word shingles
+func simHash(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+	n := min(simHashShingle, len(words))
+	var weights [64]int
+	for i := 0; i+n <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+n], " ")))
+		sum := h.Sum64()
+		for bit := range 64 {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var out uint64
+	for bit, w := range weights {
+		if w > 0 {
+			out |= 1 << bit
+		}
+	}
+	return out
+}
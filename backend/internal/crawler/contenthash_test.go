@@ -0,0 +1,50 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func tokensOf(t *testing.T, doc string) []html.Token {
+	t.Helper()
+	tokens, _, _ := tokenizeWithin([]byte(doc), 0)
+	return tokens
+}
+
+func TestVisibleText(t *testing.T) {
+	doc := `<html><head><title>Title</title><style>p { color: red }</style></head>
+		<body><h1>Hello</h1>
+		<script>document.write("<p>hidden</p>")</script>
+		<noscript>enable JS</noscript>
+		<p>  world,
+		again </p><svg><text>icon</text></svg></body></html>`
+	if got := visibleText(tokensOf(t, doc)); got != "Title Hello world, again" {
+		t.Errorf("unexpected visible text %q", got)
+	}
+}
+
+func TestFingerprintContent(t *testing.T) {
+	article := strings.Repeat("Crawlers revisit pages to learn what changed since their last visit. ", 5) +
+		"Most edits are small, such as a corrected date or a new sentence at the end of a paragraph."
+	base := fingerprintContent(tokensOf(t, `<p>`+article+`</p>`))
+
+	reformatted := fingerprintContent(tokensOf(t, "<div>\n  <p>"+article+"</p>\n<script>var x = 1</script></div>"))
+	if reformatted != base {
+		t.Error("expected markup and whitespace changes not to change the fingerprint")
+	}
+
+	edited := fingerprintContent(tokensOf(t, `<p>`+strings.Replace(article, "new sentence", "fresh sentence", 1)+`</p>`))
+	if edited.Hash == base.Hash {
+		t.Error("expected an edit to change the hash")
+	}
+	if s := Similarity(base.SimHash, edited.SimHash); s < 0.8 || s == 1 {
+		t.Errorf("expected a small edit to stay similar, got %.2f", s)
+	}
+
+	other := fingerprintContent(tokensOf(t, `<p>Quarterly revenue grew by eleven percent, driven by subscriptions in Europe and Asia.</p>`))
+	if s := Similarity(base.SimHash, other.SimHash); s > 0.8 {
+		t.Errorf("expected unrelated texts to differ, got %.2f", s)
+	}
+}
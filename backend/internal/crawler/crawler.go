@@ -7,12 +7,12 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/net/html"
 )
 
 type Result struct {
@@ -21,21 +21,109 @@ type Result struct {
 	Headings          map[string]int
 	InternalLinks     int
 	ExternalLinks     int
-	InaccessibleLinks int
+	InaccessibleLinks int // links whose error class counts as inaccessible
+	LinkErrors        map[string]int
 	HasLoginForm      bool
+	// Outcome says whether the page was parsed completely (see the Outcome constants)
+	Outcome     string
+	ContentType string
+	BodyBytes   int64 // bytes of the body that were read
+	Truncated   bool  // the body was cut at the size limit
+	// Proxy is the proxy the page was fetched through, without credentials
+	Proxy string
+	// Validators are the ETag and Last-Modified the server sent, for the next crawl's
+	// Options.Conditional
+	Validators Validators
+	// Content fingerprints the visible text of the page, to tell whether it changed
+	Content ContentFingerprint
+	// Snapshot is the raw response, kept when Options.Snapshot asks for it
+	Snapshot *Snapshot
+	// DocumentMode is the rendering mode the doctype puts browsers in (no-quirks, limited-quirks, quirks)
+	DocumentMode string
+	// Charset is the encoding the page was decoded from before parsing
+	Charset Charset
+	// Findings holds the output of every analyzer that ran, keyed by analyzer name, except
+	// for those that only fill the fields above (see ResultAnalyzer)
+	Findings map[string]any
+	// Certificate is the certificate of an HTTPS page. It is also set, alongside the error,
+	// when the fetch failed certificate verification.
+	Certificate *CertificateInfo
+	// Links are the absolute links the links analyzer found, which the crawler checks
+	Links []string
 }
 
 type Fetcher interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// Options tune a single crawl
+type Options struct {
+	// Analyzers lists the analyzers to run by name; nil runs every registered analyzer
+	Analyzers []string
+	// Rules are evaluated by the extract analyzer, which runs whenever there are rules
+	Rules []ExtractionRule
+	// FetchResources makes the resources analyzer request every sub-resource to measure page weight
+	FetchResources bool
+	// Profile customises the page request and, if it says so, the internal link checks
+	Profile *RequestProfile
+	// Login, when set, is executed before the page is fetched. The page, its link checks and
+	// the analyzers share the resulting session.
+	Login *LoginRecipe
+	// Proxy, when set, carries every request of the crawl. Only clients built by HTTPClient
+	// honour it.
+	Proxy *url.URL
+	// Conditional, when set, makes the page fetch conditional on the page having changed since
+	// these validators were seen. An unchanged page yields OutcomeNotModified; nothing is
+	// parsed or analyzed, and only RecheckLinks are checked.
+	Conditional *Validators
+	// RecheckLinks are the links an earlier crawl found on the page, checked again when a
+	// conditional fetch finds it unchanged
+	RecheckLinks []string
+	// Snapshot keeps the raw response of the page in Result.Snapshot
+	Snapshot bool
+	// SnapshotLinks also keeps the request and response head of every link check. Links are
+	// then checked afresh rather than answered from the link cache.
+	SnapshotLinks bool
+}
+
 type Crawler struct {
-	client Fetcher
+	client       Fetcher
+	analyzers    *Registry
+	limits       Limits
+	inaccessible map[string]bool // link error classes counted as inaccessible
+	links        *LinkCache      // optional, shared by every crawl
+	sessions     *sessionCache   // cookies of recent logins, shared by every crawl
 }
 
-func New(client Fetcher) *Crawler { return &Crawler{client: client} }
+func New(client Fetcher) *Crawler {
+	return &Crawler{
+		client:       client,
+		analyzers:    DefaultRegistry(),
+		limits:       DefaultLimits(),
+		inaccessible: classSet(DefaultInaccessibleClasses()),
+		sessions:     newSessionCache(loginSessionTTL),
+	}
+}
 
+// SetLimits replaces the body size and parse time limits; zero values keep the defaults
+func (c *Crawler) SetLimits(l Limits) {
+	if l.MaxBodyBytes > 0 {
+		c.limits.MaxBodyBytes = l.MaxBodyBytes
+	}
+	if l.MaxParseTime > 0 {
+		c.limits.MaxParseTime = l.MaxParseTime
+	}
+}
+
+// Analyzers returns the registry of analyzers available to this crawler
+func (c *Crawler) Analyzers() *Registry { return c.analyzers }
+
+// Crawl fetches targetURL and runs all registered analyzers on it
 func (c *Crawler) Crawl(ctx context.Context, targetURL string) (Result, error) {
+	return c.CrawlWithOptions(ctx, targetURL, Options{})
+}
+
+func (c *Crawler) CrawlWithOptions(ctx context.Context, targetURL string, opts Options) (Result, error) {
 	logrus.Infof("Starting crawl for URL: %s", targetURL)
 	startTime := time.Now()
 
@@ -50,195 +138,196 @@ func (c *Crawler) Crawl(ctx context.Context, targetURL string) (Result, error) {
 		return Result{}, fmt.Errorf("unsupported URL scheme: %s", parsedURL.Scheme)
 	}
 
+	names := opts.Analyzers
+	// rules only take effect through the extract analyzer, so they bring it along
+	if len(opts.Rules) > 0 && names != nil && !slices.Contains(names, "extract") {
+		names = append(slices.Clone(names), "extract")
+	}
+	analyzers, err := c.analyzers.Resolve(names)
+	if err != nil {
+		return Result{}, err
+	}
+	if opts.Proxy != nil {
+		logrus.Infof("Crawling %s through proxy %s", targetURL, RedactProxy(opts.Proxy))
+		ctx = withProxy(ctx, opts.Proxy)
+	}
+
+	client := c.client
+	if opts.Login != nil {
+		s, err := c.sessions.open(ctx, c.client, opts.Login, opts.Profile, opts.Proxy, c.limits.MaxBodyBytes)
+		if err != nil {
+			logrus.Errorf("Login for %s failed: %v", targetURL, err)
+			return Result{}, err
+		}
+		client = s
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
 	if err != nil {
 		logrus.Errorf("Failed to create request for %s: %v", targetURL, err)
 		return Result{}, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("User-Agent", "url-crawler/1.0")
+	req.Header.Set("User-Agent", defaultUserAgent)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	resp, err := c.client.Do(req)
+	opts.Profile.apply(req)
+	if opts.Conditional.empty() {
+		opts.Conditional = nil
+	}
+	opts.Conditional.apply(req)
+	resp, err := client.Do(req)
 	if err != nil {
+		if cert := certificateFromError(parsedURL.Hostname(), err); cert != nil {
+			logrus.Warnf("Certificate verification failed for %s: %s", targetURL, cert.Error)
+			return Result{Certificate: cert}, newFetchError("certificate verification failed", err)
+		}
 		// Check if it's an EOF error - some servers close connection immediately
 		errStr := err.Error()
 		if strings.Contains(errStr, "EOF") {
 			// EOF typically means connection closed before response - treat as network error
 			logrus.Warnf("Connection closed (EOF) for %s: %v", targetURL, err)
-			return Result{}, fmt.Errorf("connection closed (EOF): %w", err)
+			return Result{}, newFetchError("connection closed (EOF)", err)
 		}
-		logrus.Errorf("HTTP request failed for %s: %v", targetURL, err)
-		return Result{}, err
+		fetchErr := newFetchError("", err)
+		logrus.Errorf("HTTP request failed for %s (%s): %v", targetURL, fetchErr.Class, err)
+		return Result{}, fetchErr
 	}
 	defer resp.Body.Close()
 
 	logrus.Debugf("HTTP response received for %s: status=%d, content-type=%s", targetURL, resp.StatusCode, resp.Header.Get("Content-Type"))
 
+	res := Result{Outcome: OutcomeOK, Proxy: RedactProxy(opts.Proxy)}
+	res.Validators = validatorsOf(resp, opts.Conditional)
+	if opts.Snapshot {
+		res.Snapshot = newSnapshot(req, resp, opts.Profile)
+	}
+	if resp.TLS != nil {
+		// after redirects, the connection belongs to the final URL
+		host := parsedURL.Hostname()
+		if resp.Request != nil && resp.Request.URL != nil {
+			host = resp.Request.URL.Hostname()
+		}
+		res.Certificate = certificateInfo(host, resp.TLS)
+	}
+	// a 304 only means "unchanged" when the request was conditional
+	if resp.StatusCode == http.StatusNotModified && opts.Conditional != nil {
+		logrus.Infof("Page %s is unchanged since the last crawl", targetURL)
+		res.Outcome = OutcomeNotModified
+		if len(opts.RecheckLinks) > 0 {
+			res.Links = opts.RecheckLinks
+			c.checkPageLinks(ctx, &res, parsedURL, parsedURL, client, opts)
+		}
+		return res, nil
+	}
+
 	// Note: We parse HTML even for 4xx/5xx status codes, as error pages often contain HTML
 
 	// Check content-type to ensure we're parsing HTML
 	contentType := resp.Header.Get("Content-Type")
+	res.ContentType = contentType
+	if isBinaryType(contentType) {
+		logrus.Infof("Skipping %s: Content-Type %q is not HTML", targetURL, contentType)
+		res.Outcome = OutcomeNotHTML
+		return res, nil
+	}
 	if !strings.Contains(strings.ToLower(contentType), "text/html") && !strings.Contains(strings.ToLower(contentType), "application/xhtml+xml") {
 		// Not HTML, but continue parsing anyway as some servers don't set content-type correctly
 		logrus.Warnf("Content-Type header does not indicate HTML for URL %s: got %q", targetURL, contentType)
 	}
 
-	// Parse main document and collect metadata and links to check later
-	z := html.NewTokenizer(resp.Body)
-	res := Result{Headings: map[string]int{"h1": 0, "h2": 0, "h3": 0, "h4": 0, "h5": 0, "h6": 0}}
-	collectedLinks := make([]string, 0, 32)
-	var baseURL *url.URL
-	var htmlVersion *string
-	var titleBuilder strings.Builder
-	var inTitleTag bool
-
-	for {
-		tt := z.Next()
-		switch tt {
-		case html.ErrorToken:
-			// finalize inaccessible links count before returning
-			if baseURL == nil {
-				baseURL = parsedURL
-			}
-			logrus.Debugf("Parsed HTML for %s: found %d links, %d headings total", targetURL, len(collectedLinks), res.Headings["h1"]+res.Headings["h2"]+res.Headings["h3"]+res.Headings["h4"]+res.Headings["h5"]+res.Headings["h6"])
-			if len(collectedLinks) > 0 {
-				logrus.Infof("Checking accessibility of %d links for %s", len(collectedLinks), targetURL)
-			}
-			res.InaccessibleLinks = countInaccessibleLinks(ctx, baseURL, collectedLinks, c.client)
-			// Set title if we collected one
-			if titleBuilder.Len() > 0 {
-				title := strings.TrimSpace(titleBuilder.String())
-				res.Title = &title
-				logrus.Debugf("Extracted title for %s: %s", targetURL, title)
-			}
-			// Set HTML version if we detected one, otherwise default to HTML5
-			if htmlVersion == nil {
-				defaultVersion := "HTML5"
-				htmlVersion = &defaultVersion
-			}
-			res.HTMLVersion = htmlVersion
-			duration := time.Since(startTime)
-			logrus.Infof("Completed crawl for %s in %v: %d internal, %d external links, %d inaccessible, login form: %v",
-				targetURL, duration, res.InternalLinks, res.ExternalLinks, res.InaccessibleLinks, res.HasLoginForm)
-			return res, nil
-		case html.DoctypeToken:
-			// Try to detect HTML version from doctype
-			doctype := z.Token().Data
-			doctypeLower := strings.ToLower(doctype)
-			if strings.Contains(doctypeLower, "html5") || strings.Contains(doctypeLower, "html 5") {
-				version := "HTML5"
-				htmlVersion = &version
-			} else if strings.Contains(doctypeLower, "html 4.01 strict") {
-				version := "HTML 4.01 Strict"
-				htmlVersion = &version
-			} else if strings.Contains(doctypeLower, "html 4.01 transitional") {
-				version := "HTML 4.01 Transitional"
-				htmlVersion = &version
-			} else if strings.Contains(doctypeLower, "html 4.01") {
-				version := "HTML 4.01"
-				htmlVersion = &version
-			} else if strings.Contains(doctypeLower, "xhtml 1.0") {
-				version := "XHTML 1.0"
-				htmlVersion = &version
-			} else if strings.Contains(doctypeLower, "xhtml 1.1") {
-				version := "XHTML 1.1"
-				htmlVersion = &version
-			} else if strings.Contains(doctypeLower, "html") {
-				// Generic HTML, default to HTML5
-				version := "HTML5"
-				htmlVersion = &version
-			}
-		case html.StartTagToken, html.SelfClosingTagToken:
-			t := z.Token()
-			tn := strings.ToLower(t.Data)
-			if tn == "base" {
-				// Handle base tag for relative URL resolution
-				for _, a := range t.Attr {
-					if strings.ToLower(a.Key) == "href" {
-						baseHref := strings.TrimSpace(a.Val)
-						if baseHref != "" {
-							parsedBase, err := url.Parse(baseHref)
-							if err == nil {
-								baseURL = parsedURL.ResolveReference(parsedBase)
-								logrus.Debugf("Found base tag for %s: %s", targetURL, baseURL.String())
-							}
-						}
-					}
-				}
-			}
-			if tn == "title" {
-				// If we encounter a new title tag, reset and start collecting
-				inTitleTag = true
-				titleBuilder.Reset()
-				// Handle self-closing title tag (rare but possible)
-				if tt == html.SelfClosingTagToken {
-					inTitleTag = false
-				}
-			}
-			if tn == "h1" || tn == "h2" || tn == "h3" || tn == "h4" || tn == "h5" || tn == "h6" {
-				res.Headings[tn]++
-			}
-			if tn == "a" {
-				for _, a := range t.Attr {
-					if strings.ToLower(a.Key) == "href" {
-						href := strings.TrimSpace(a.Val)
-						if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
-							break
-						}
-						// Skip non-HTTP/HTTPS links (mailto:, tel:, etc.)
-						hrefLower := strings.ToLower(href)
-						if strings.HasPrefix(hrefLower, "mailto:") ||
-							strings.HasPrefix(hrefLower, "tel:") ||
-							strings.HasPrefix(hrefLower, "sms:") ||
-							strings.HasPrefix(hrefLower, "ftp:") ||
-							strings.HasPrefix(hrefLower, "file:") {
-							break
-						}
-						// Resolve relative URLs
-						resolvedHref := href
-						if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
-							// Relative URL - resolve against base
-							if baseURL == nil {
-								baseURL = parsedURL
-							}
-							hrefURL, err := url.Parse(href)
-							if err == nil {
-								resolvedURL := baseURL.ResolveReference(hrefURL)
-								resolvedHref = resolvedURL.String()
-							}
-						}
-						// Only process HTTP/HTTPS links
-						if strings.HasPrefix(resolvedHref, "http://") || strings.HasPrefix(resolvedHref, "https://") {
-							if isExternal(parsedURL, resolvedHref) {
-								res.ExternalLinks++
-							} else {
-								res.InternalLinks++
-							}
-							// Only collect HTTP/HTTPS links for accessibility checking
-							collectedLinks = append(collectedLinks, resolvedHref)
-						}
-					}
-				}
-			}
-			if tn == "input" {
-				for _, a := range t.Attr {
-					if strings.ToLower(a.Key) == "type" && strings.ToLower(a.Val) == "password" {
-						res.HasLoginForm = true
-						logrus.Debugf("Login form detected on %s (password input found)", targetURL)
-					}
-				}
-			}
-		case html.EndTagToken:
-			t := z.Token()
-			tn := strings.ToLower(t.Data)
-			if tn == "title" {
-				inTitleTag = false
-			}
-		case html.TextToken:
-			if inTitleTag {
-				titleBuilder.WriteString(z.Token().Data)
+	// Read the body once so the tokenizer and the analyzers can share it
+	body, truncated, binaryType, err := readBody(resp.Body, c.limits.MaxBodyBytes)
+	if err != nil {
+		logrus.Errorf("Failed to read response body for %s: %v", targetURL, err)
+		return Result{}, newFetchError("failed to read response body", err)
+	}
+	if res.Snapshot != nil {
+		res.Snapshot.Body, res.Snapshot.Truncated = body, truncated
+	}
+	if binaryType != "" {
+		logrus.Infof("Skipping %s: body looks like %s despite Content-Type %q", targetURL, binaryType, contentType)
+		res.Outcome = OutcomeNotHTML
+		res.ContentType = binaryType
+		return res, nil
+	}
+	res.BodyBytes = int64(len(body))
+	res.Truncated = truncated
+	if truncated {
+		logrus.Warnf("Response body of %s exceeds %d bytes; parsing the first part only", targetURL, c.limits.MaxBodyBytes)
+		res.Outcome = OutcomeTruncated
+	}
+	body, cs := decodeBody(body, contentType, truncated)
+	if cs.Mismatch {
+		logrus.Warnf("Charset mismatch for %s: header declares %s, document declares %s", targetURL, cs.HeaderCharset, cs.MetaCharset)
+	}
+	res.Charset = cs
+	// tokenizing, building the tree and the analyzers, network-bound ones included, share one
+	// deadline; the link checks below don't count towards it
+	actx := ctx
+	var deadline time.Time
+	if c.limits.MaxParseTime > 0 {
+		deadline = time.Now().Add(c.limits.MaxParseTime)
+		var cancel context.CancelFunc
+		actx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	page, complete := newPage(parsedURL, resp, body, deadline)
+	if !complete {
+		logrus.Warnf("Parsing %s exceeded %v; analysing the first %d bytes only", targetURL, c.limits.MaxParseTime, len(page.Body))
+		res.Outcome = OutcomeParseTimeout
+	}
+	page.Options = opts
+	page.Fetcher = client
+	page.BodyBytes, page.Truncated = res.BodyBytes, res.Truncated
+
+	runAnalyzers(actx, analyzers, page, &res)
+	if res.Outcome != OutcomeParseTimeout && (page.docCut || (actx.Err() != nil && ctx.Err() == nil)) {
+		logrus.Warnf("Analysing %s exceeded %v; findings are incomplete", targetURL, c.limits.MaxParseTime)
+		res.Outcome = OutcomeParseTimeout
+	}
+
+	c.checkPageLinks(ctx, &res, parsedURL, page.BaseURL, client, opts)
+	duration := time.Since(startTime)
+	logrus.Infof("Completed crawl for %s in %v: %d internal, %d external links, %d inaccessible %v, login form: %v",
+		targetURL, duration, res.InternalLinks, res.ExternalLinks, res.InaccessibleLinks, res.LinkErrors, res.HasLoginForm)
+	return res, nil
+}
+
+// checkPageLinks checks the links of the page at pageURL, relative ones resolved against
+// baseURL, and counts the failures into res
+func (c *Crawler) checkPageLinks(ctx context.Context, res *Result, pageURL, baseURL *url.URL, client Fetcher, opts Options) {
+	if len(res.Links) > 0 {
+		logrus.Infof("Checking accessibility of %d links for %s", len(res.Links), pageURL)
+	}
+	var decorateLink func(*http.Request)
+	if p := opts.Profile; p != nil && p.ApplyToLinks {
+		decorateLink = func(req *http.Request) {
+			// credentials only go to the page's own host
+			if strings.EqualFold(req.URL.Host, pageURL.Host) {
+				p.apply(req)
 			}
 		}
 	}
+	// links checked with credentials, or from where a proxy sits, may answer differently than
+	// they do for everyone else
+	cache := c.links
+	if decorateLink != nil || opts.Login != nil || opts.Proxy != nil {
+		cache = nil
+	}
+	linkClient := client
+	var recorder *exchangeRecorder
+	if res.Snapshot != nil && opts.SnapshotLinks {
+		recorder = &exchangeRecorder{Fetcher: client, profile: opts.Profile}
+		linkClient, cache = recorder, nil
+	}
+	res.LinkErrors = checkLinks(ctx, baseURL, res.Links, linkClient, decorateLink, cache)
+	if recorder != nil {
+		res.Snapshot.Links = recorder.exchanges
+	}
+	for class, n := range res.LinkErrors {
+		if c.inaccessible[class] {
+			res.InaccessibleLinks += n
+		}
+	}
 }
 
 func isExternal(baseURL *url.URL, href string) bool {
@@ -269,13 +358,15 @@ func isExternal(baseURL *url.URL, href string) bool {
 	return !strings.EqualFold(hrefURL.Host, baseURL.Host)
 }
 
-// countInaccessibleLinks visits the collected links and counts those returning HTTP 4xx/5xx.
-// Only HTTP status codes are considered; network errors are ignored for this metric.
+// checkLinks visits the collected links and counts the failed ones by error class (see
+// ClassifyError); a 4xx or 5xx answer counts as ErrorClassHTTPStatus.
 // Non-HTTP/HTTPS links (mailto:, tel:, etc.) are skipped.
 // Uses parallel processing with a worker pool to improve performance.
-func countInaccessibleLinks(ctx context.Context, baseURL *url.URL, hrefs []string, client Fetcher) int {
+// decorate, when set, is applied to every request before it is sent. cache, when set, answers
+// links checked recently, by this crawl or another.
+func checkLinks(ctx context.Context, baseURL *url.URL, hrefs []string, client Fetcher, decorate func(*http.Request), cache *LinkCache) map[string]int {
 	if len(hrefs) == 0 {
-		return 0
+		return nil
 	}
 
 	// Filter to only HTTP/HTTPS links
@@ -291,7 +382,7 @@ func countInaccessibleLinks(ctx context.Context, baseURL *url.URL, hrefs []strin
 	}
 
 	if len(httpLinks) == 0 {
-		return 0
+		return nil
 	}
 
 	logrus.Debugf("Checking %d HTTP/HTTPS links for accessibility (filtered from %d total links)", len(httpLinks), len(hrefs))
@@ -303,7 +394,7 @@ func countInaccessibleLinks(ctx context.Context, baseURL *url.URL, hrefs []strin
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
-	inaccessible := 0
+	counts := make(map[string]int)
 	linkChan := make(chan string, len(httpLinks))
 
 	// Send all links to channel
@@ -325,48 +416,59 @@ func countInaccessibleLinks(ctx context.Context, baseURL *url.URL, hrefs []strin
 				default:
 				}
 
-				// Create a shorter timeout per link (5 seconds) to avoid blocking
-				linkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-				isInaccessible := checkLinkAccessibility(linkCtx, baseURL, href, client)
-				cancel()
-
-				if isInaccessible {
-					mu.Lock()
-					inaccessible++
-					mu.Unlock()
+				var key string
+				if u, err := url.Parse(href); err == nil {
+					key = linkKey(baseURL.ResolveReference(u))
 				}
+				class := cache.check(ctx, key, func() (string, bool) {
+					// Create a shorter timeout per link (5 seconds) to avoid blocking
+					linkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+					defer cancel()
+					class := checkLinkAccessibility(linkCtx, baseURL, href, client, decorate)
+					return class, ctx.Err() == nil
+				})
+
+				// a link cut short by the crawl itself ending says nothing about the link
+				if class == "" || ctx.Err() != nil {
+					continue
+				}
+				mu.Lock()
+				counts[class]++
+				mu.Unlock()
 			}
 		}()
 	}
 
 	wg.Wait()
-	if inaccessible > 0 {
-		logrus.Infof("Found %d inaccessible links (4xx/5xx) out of %d checked", inaccessible, len(httpLinks))
+	if len(counts) == 0 {
+		return nil
 	}
-	return inaccessible
+	logrus.Infof("Found failing links out of %d checked: %v", len(httpLinks), counts)
+	return counts
 }
 
-// checkLinkAccessibility checks if a single link is inaccessible (returns 4xx/5xx).
-// Returns true if the link is inaccessible, false otherwise.
+// checkLinkAccessibility checks a single link and returns the error class it failed with,
+// or "" if it's accessible.
 // Note: href is expected to be HTTP/HTTPS (already filtered by caller).
-func checkLinkAccessibility(ctx context.Context, baseURL *url.URL, href string, client Fetcher) bool {
+func checkLinkAccessibility(ctx context.Context, baseURL *url.URL, href string, client Fetcher, decorate func(*http.Request)) string {
 	u, err := url.Parse(href)
 	if err != nil {
-		return false
+		return ""
 	}
 	abs := baseURL.ResolveReference(u)
 
 	// Prefer HEAD to save bandwidth; fall back to GET if method not allowed
 	req, err := http.NewRequestWithContext(ctx, http.MethodHead, abs.String(), nil)
 	if err != nil {
-		return false
+		return ""
+	}
+	if decorate != nil {
+		decorate(req)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		// Ignore network errors for this metric
-
-		return false
+		return ClassifyError(err)
 	}
 	defer resp.Body.Close()
 
@@ -375,32 +477,61 @@ func checkLinkAccessibility(ctx context.Context, baseURL *url.URL, href string,
 		resp.Body.Close()
 		reqGet, err := http.NewRequestWithContext(ctx, http.MethodGet, abs.String(), nil)
 		if err != nil {
-			return false
+			return ""
+		}
+		if decorate != nil {
+			decorate(reqGet)
 		}
 		resp, err = client.Do(reqGet)
 		if err != nil {
-			return false
+			return ClassifyError(err)
 		}
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode <= 599 {
+		return ErrorClassHTTPStatus
 	}
+	return ""
+}
+
+// ClientOption customises the client built by HTTPClient
+type ClientOption func(*clientConfig)
 
-	// Check status code and ensure we close the body
-	isInaccessible := resp.StatusCode >= 400 && resp.StatusCode <= 599
-	return isInaccessible
+type clientConfig struct {
+	guard *AddressGuard
 }
 
-// HTTPClient configures timeouts and disables HTTP/2 for better compatibility with some servers
-func HTTPClient(timeout time.Duration) *http.Client {
+// WithAddressGuard replaces the default guard, e.g. with one that has an allowlist
+func WithAddressGuard(g *AddressGuard) ClientOption {
+	return func(c *clientConfig) { c.guard = g }
+}
+
+// WithoutAddressGuard lets the client connect to any address, including loopback and
+// private networks. Only meant for tests and trusted setups.
+func WithoutAddressGuard() ClientOption {
+	return func(c *clientConfig) { c.guard = nil }
+}
+
+// HTTPClient configures timeouts and disables HTTP/2 for better compatibility with some servers.
+// Connections to non-public addresses are refused unless the options say otherwise.
+func HTTPClient(timeout time.Duration, opts ...ClientOption) *http.Client {
+	cfg := clientConfig{guard: &AddressGuard{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	dialer := &net.Dialer{
+		// Timeout: Maximum time to wait for establishing a TCP connection (10 seconds)
+		Timeout: 10 * time.Second,
+		// KeepAlive: How long to keep TCP connections alive for reuse (30 seconds)
+		KeepAlive: 30 * time.Second,
+	}
 	tr := &http.Transport{
-		// Proxy: Use HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables for proxy configuration
-		Proxy: http.ProxyFromEnvironment,
+		// Proxy: The crawl's proxy (Options.Proxy) if it has one, otherwise HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+		Proxy: proxyFunc(nil, http.ProxyFromEnvironment),
 
 		// DialContext: Network connection settings
-		DialContext: (&net.Dialer{
-			// Timeout: Maximum time to wait for establishing a TCP connection (10 seconds)
-			Timeout: 10 * time.Second,
-			// KeepAlive: How long to keep TCP connections alive for reuse (30 seconds)
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		DialContext: dialer.DialContext,
 
 		// TLSClientConfig: TLS/SSL security settings
 		TLSClientConfig: &tls.Config{
@@ -421,6 +552,21 @@ func HTTPClient(timeout time.Duration) *http.Client {
 		IdleConnTimeout: 90 * time.Second,
 	}
 
+	if cfg.guard != nil {
+		tr.DialContext = cfg.guard.DialContext(dialer)
+		// a proxy resolves and connects on our behalf, out of the guard's reach, so only the
+		// crawl's own proxies are used and the target is checked before it is handed over
+		tr.Proxy = proxyFunc(cfg.guard, nil)
+	}
+
+	// CheckRedirect: Same limit as the default, but with an error ClassifyError recognises
+	checkRedirect := func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return ErrTooManyRedirects
+		}
+		return nil
+	}
+
 	// Client.Timeout: Overall timeout for entire request
-	return &http.Client{Timeout: timeout, Transport: tr}
+	return &http.Client{Timeout: timeout, Transport: tr, CheckRedirect: checkRedirect}
 }
@@ -200,7 +200,7 @@ func TestCrawl_RealURLs(t *testing.T) {
 		},
 	}
 
-	c := New(HTTPClient(30 * time.Second))
+	c := New(HTTPClient(30*time.Second, WithoutAddressGuard()))
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -40,7 +40,7 @@ func TestCrawl_BasicExtraction(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	c := New(HTTPClient(5 * time.Second))
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -88,7 +88,7 @@ func TestCrawl_LoginFormDetection(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	c := New(HTTPClient(5 * time.Second))
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -113,7 +113,7 @@ func TestCrawl_HeadingsAllLevels(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	c := New(HTTPClient(5 * time.Second))
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -157,7 +157,7 @@ func TestCrawl_InaccessibleLinks_LocalE2E(t *testing.T) {
 	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
-	c := New(HTTPClient(5 * time.Second))
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -182,7 +182,7 @@ func TestCrawl_AbsoluteSameHostCountedAsInternal(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	c := New(HTTPClient(5 * time.Second))
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -229,7 +229,7 @@ func TestCrawl_InaccessibleLinksCount(t *testing.T) {
 	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
-	c := New(HTTPClient(5 * time.Second))
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -251,3 +251,35 @@ func tsURL(r *http.Request) string {
 	}
 	return scheme + "://" + r.Host
 }
+
+func TestCrawl_TitleIsLastNonEmpty(t *testing.T) {
+	cases := []struct {
+		name string
+		html string
+		want string // empty means no title
+	}{
+		{"last title wins", `<html><head><title>First</title><title> Second </title></head></html>`, "Second"},
+		{"empty title skipped", `<html><head><title>Kept</title><title>  </title></head></html>`, "Kept"},
+		{"only whitespace", `<html><head><title> </title></head></html>`, ""},
+		{"no title", `<html><head></head></html>`, ""},
+	}
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := serveHTML(t, tc.html)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			res, err := c.Crawl(ctx, ts.URL)
+			if err != nil {
+				t.Fatalf("crawl error: %v", err)
+			}
+			switch {
+			case tc.want == "" && res.Title != nil:
+				t.Fatalf("expected no title, got %q", *res.Title)
+			case tc.want != "" && (res.Title == nil || *res.Title != tc.want):
+				t.Fatalf("expected title %q, got %v", tc.want, res.Title)
+			}
+		})
+	}
+}
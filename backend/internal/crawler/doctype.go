@@ -0,0 +1,276 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Document modes, as the HTML parser decides them from the doctype
+const (
+	ModeNoQuirks      = "no-quirks"
+	ModeLimitedQuirks = "limited-quirks"
+	ModeQuirks        = "quirks"
+)
+
+// doctype is a parsed <!DOCTYPE> token
+type doctype struct {
+	Name      string
+	PublicID  string
+	SystemID  string
+	HasPublic bool
+	HasSystem bool
+	// Malformed is set when the token would make the parser force quirks mode
+	Malformed bool
+}
+
+// parseDoctype parses the contents of a doctype token, e.g.
+// `html PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"`
+func parseDoctype(data string) doctype {
+	var d doctype
+	s := strings.TrimSpace(data)
+	i := strings.IndexAny(s, " \t\n\f\r")
+	if i < 0 {
+		i = len(s)
+	}
+	d.Name, s = strings.ToLower(s[:i]), strings.TrimSpace(s[i:])
+	if d.Name == "" {
+		d.Malformed = true
+		return d
+	}
+
+	literal := func() (string, bool) {
+		if s == "" || (s[0] != '"' && s[0] != '\'') {
+			return "", false
+		}
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 {
+			// an unterminated identifier forces quirks mode
+			v := s[1:]
+			s = ""
+			d.Malformed = true
+			return v, true
+		}
+		v := s[1 : end+1]
+		s = strings.TrimSpace(s[end+2:])
+		return v, true
+	}
+
+	keyword := strings.ToUpper(firstWord(s))
+	switch keyword {
+	case "":
+	case "PUBLIC":
+		s = strings.TrimSpace(s[len(keyword):])
+		if d.PublicID, d.HasPublic = literal(); !d.HasPublic {
+			d.Malformed = true
+			return d
+		}
+		d.SystemID, d.HasSystem = literal()
+	case "SYSTEM":
+		s = strings.TrimSpace(s[len(keyword):])
+		if d.SystemID, d.HasSystem = literal(); !d.HasSystem {
+			d.Malformed = true
+		}
+	default:
+		d.Malformed = true
+	}
+	return d
+}
+
+func firstWord(s string) string {
+	if i := strings.IndexAny(s, " \t\n\f\r\"'"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// doctypeVersions maps public identifiers, without their language suffix ("EN"), to the version
+// they declare
+var doctypeVersions = map[string]string{
+	"-//w3c//dtd html 4.01//":                              "HTML 4.01 Strict",
+	"-//w3c//dtd html 4.01 transitional//":                 "HTML 4.01 Transitional",
+	"-//w3c//dtd html 4.01 frameset//":                     "HTML 4.01 Frameset",
+	"-//w3c//dtd html 4.0//":                               "HTML 4.0 Strict",
+	"-//w3c//dtd html 4.0 transitional//":                  "HTML 4.0 Transitional",
+	"-//w3c//dtd html 4.0 frameset//":                      "HTML 4.0 Frameset",
+	"-//w3c//dtd html 3.2 final//":                         "HTML 3.2",
+	"-//w3c//dtd html 3.2//":                               "HTML 3.2",
+	"-//ietf//dtd html 2.0//":                              "HTML 2.0",
+	"-//ietf//dtd html//":                                  "HTML 2.0",
+	"-//w3c//dtd xhtml 1.0 strict//":                       "XHTML 1.0 Strict",
+	"-//w3c//dtd xhtml 1.0 transitional//":                 "XHTML 1.0 Transitional",
+	"-//w3c//dtd xhtml 1.0 frameset//":                     "XHTML 1.0 Frameset",
+	"-//w3c//dtd xhtml 1.1//":                              "XHTML 1.1",
+	"-//w3c//dtd xhtml basic 1.0//":                        "XHTML Basic 1.0",
+	"-//w3c//dtd xhtml basic 1.1//":                        "XHTML Basic 1.1",
+	"-//w3c//dtd xhtml+rdfa 1.0//":                         "XHTML+RDFa 1.0",
+	"-//w3c//dtd xhtml+rdfa 1.1//":                         "XHTML+RDFa 1.1",
+	"-//w3c//dtd xhtml 1.1 plus mathml 2.0//":              "XHTML 1.1 plus MathML 2.0",
+	"-//wapforum//dtd xhtml mobile 1.0//":                  "XHTML Mobile 1.0",
+	"-//wapforum//dtd xhtml mobile 1.1//":                  "XHTML Mobile 1.1",
+	"-//wapforum//dtd xhtml mobile 1.2//":                  "XHTML Mobile 1.2",
+	"-//w3c//dtd xhtml 1.1 plus mathml 2.0 plus svg 1.1//": "XHTML 1.1 plus MathML 2.0 plus SVG 1.1",
+}
+
+// Version returns the HTML version the doctype declares, or "" when it isn't a known one
+func (d doctype) Version() string {
+	if d.Name != "html" {
+		return ""
+	}
+	if !d.HasPublic {
+		// <!DOCTYPE html> and the legacy-compat form used by XML generators
+		if !d.HasSystem || strings.EqualFold(d.SystemID, "about:legacy-compat") {
+			return "HTML5"
+		}
+		return ""
+	}
+	id := strings.ToLower(strings.TrimSpace(d.PublicID))
+	if i := strings.LastIndex(id, "//"); i >= 0 {
+		id = id[:i+2]
+	}
+	return doctypeVersions[id]
+}
+
+// quirksPublicIDs are the public identifier prefixes that trigger quirks mode
+// (HTML Standard, "the initial insertion mode")
+var quirksPublicIDs = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
+
+// documentMode returns the document mode a browser renders the page in. A nil doctype means the page
+// has none, which puts it in quirks mode.
+func documentMode(d *doctype) string {
+	if d == nil || d.Malformed || d.Name != "html" {
+		return ModeQuirks
+	}
+	public := strings.ToLower(d.PublicID)
+	system := strings.ToLower(d.SystemID)
+	switch public {
+	case "-//w3o//dtd w3 html strict 3.0//en//", "-/w3c/dtd html 4.0 transitional/en", "html":
+		return ModeQuirks
+	}
+	if system == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd" {
+		return ModeQuirks
+	}
+	for _, prefix := range quirksPublicIDs {
+		if strings.HasPrefix(public, prefix) {
+			return ModeQuirks
+		}
+	}
+	html401 := strings.HasPrefix(public, "-//w3c//dtd html 4.01 frameset//") ||
+		strings.HasPrefix(public, "-//w3c//dtd html 4.01 transitional//")
+	if html401 && !d.HasSystem {
+		return ModeQuirks
+	}
+	if html401 ||
+		strings.HasPrefix(public, "-//w3c//dtd xhtml 1.0 frameset//") ||
+		strings.HasPrefix(public, "-//w3c//dtd xhtml 1.0 transitional//") {
+		return ModeLimitedQuirks
+	}
+	return ModeNoQuirks
+}
+
+// documentDoctype returns the doctype the parser would use: one appearing before any element.
+// It is nil when there is none.
+func documentDoctype(tokens []html.Token) *doctype {
+	for _, t := range tokens {
+		switch t.Type {
+		case html.DoctypeToken:
+			d := parseDoctype(t.Data)
+			return &d
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			return nil
+		case html.TextToken:
+			if strings.TrimSpace(t.Data) != "" {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// DoctypeFindings are the HTML version and document mode a page's doctype declares
+type DoctypeFindings struct {
+	Version string // "" when the doctype is missing or not a known one
+	Mode    string
+}
+
+// DoctypeAnalyzer reports the HTML version and the rendering mode of the page
+type DoctypeAnalyzer struct{}
+
+func (DoctypeAnalyzer) Name() string { return "doctype" }
+
+func (DoctypeAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	dt := documentDoctype(page.Tokens)
+	out := DoctypeFindings{Mode: documentMode(dt)}
+	if dt != nil {
+		out.Version = dt.Version()
+	}
+	return out, nil
+}
+
+func (DoctypeAnalyzer) Apply(res *Result, findings any) any {
+	if dt, ok := findings.(DoctypeFindings); ok {
+		// the version is only known from a recognised doctype; without one it stays nil
+		if dt.Version != "" {
+			res.HTMLVersion = &dt.Version
+		}
+		res.DocumentMode = dt.Mode
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Where the encoding of a page was taken from, in the order the HTML spec consults them
+const (
+	CharsetFromBOM     = "bom"
+	CharsetFromHeader  = "header"
+	CharsetFromMeta    = "meta"
+	CharsetFromSniffed = "sniffed" // no declaration; the body is valid UTF-8
+	CharsetFromDefault = "default" // no declaration; decoded as windows-1252
+)
+
+// prescanBytes is how much of the document is searched for a <meta> charset declaration
+const prescanBytes = 1024
+
+// Charset describes how a page was decoded
+type Charset struct {
+	Name   string // canonical WHATWG name, e.g. "utf-8", "shift_jis", "windows-1251"
+	Source string
+	// HeaderCharset and MetaCharset are the encodings the Content-Type header and the document
+	// declare, when they declare a known one
+	HeaderCharset string
+	MetaCharset   string
+	// Mismatch is set when the header and the document declare different encodings
+	Mismatch bool
+}
+
+// decodeBody determines the encoding of an HTML response and transcodes its body to UTF-8.
+// A byte order mark wins over the Content-Type charset, which wins over a <meta> declaration.
+// A truncated body may end in the middle of a character, which doesn't count against UTF-8.
+func decodeBody(body []byte, contentType string, truncated bool) ([]byte, Charset) {
+	var cs Charset
+	var enc encoding.Encoding
+
+	headerEnc, headerName := headerCharset(contentType)
+	metaEnc, metaName := metaCharset(body[:min(len(body), prescanBytes)])
+	cs.HeaderCharset, cs.MetaCharset = headerName, metaName
+	cs.Mismatch = headerName != "" && metaName != "" && headerName != metaName
+
+	bomEnc, bomName, bomLen := bomCharset(body)
+	switch {
+	case bomEnc != nil:
+		enc, cs.Name, cs.Source = bomEnc, bomName, CharsetFromBOM
+		body = body[bomLen:]
+	case headerEnc != nil:
+		enc, cs.Name, cs.Source = headerEnc, headerName, CharsetFromHeader
+	case metaEnc != nil:
+		enc, cs.Name, cs.Source = metaEnc, metaName, CharsetFromMeta
+	case validUTF8(body, truncated):
+		enc, cs.Name, cs.Source = encoding.Nop, "utf-8", CharsetFromSniffed
+	default:
+		enc, cs.Name, cs.Source = charmap.Windows1252, "windows-1252", CharsetFromDefault
+	}
+
+	if enc == encoding.Nop {
+		return body, cs
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		// the decoders replace invalid sequences, so this only happens for broken encoders
+		return body, cs
+	}
+	return decoded, cs
+}
+
+// validUTF8 reports whether body is valid UTF-8, ignoring an incomplete character at the end
+// when the body was truncated
+func validUTF8(body []byte, truncated bool) bool {
+	if truncated {
+		// an incomplete character is at most UTFMax-1 bytes, starting at its lead byte
+		for i := 1; i < utf8.UTFMax && i <= len(body); i++ {
+			if tail := body[len(body)-i:]; utf8.RuneStart(tail[0]) {
+				if !utf8.FullRune(tail) {
+					body = body[:len(body)-i]
+				}
+				break
+			}
+		}
+	}
+	return utf8.Valid(body)
+}
+
+func bomCharset(body []byte) (encoding.Encoding, string, int) {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xEF, 0xBB, 0xBF}):
+		return encoding.Nop, "utf-8", 3
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		e, name := charset.Lookup("utf-16be")
+		return e, name, 2
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		e, name := charset.Lookup("utf-16le")
+		return e, name, 2
+	}
+	return nil, "", 0
+}
+
+func headerCharset(contentType string) (encoding.Encoding, string) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ""
+	}
+	return lookupCharset(params["charset"])
+}
+
+// metaCharset runs the spec's prescan over the start of a document, looking for
+// <meta charset> or <meta http-equiv="Content-Type" content="...; charset=...">
+func metaCharset(content []byte) (encoding.Encoding, string) {
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return nil, ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			if !strings.EqualFold(t.Data, "meta") {
+				continue
+			}
+			if v, ok := attr(t, "charset"); ok {
+				if e, name := lookupCharset(v); e != nil {
+					return e, name
+				}
+				continue
+			}
+			if equiv, _ := attr(t, "http-equiv"); strings.EqualFold(strings.TrimSpace(equiv), "content-type") {
+				content, _ := attr(t, "content")
+				if e, name := headerCharset(content); e != nil {
+					return e, name
+				}
+			}
+		}
+	}
+}
+
+// lookupCharset resolves a charset label. UTF-16 labels are treated as UTF-8 outside of a BOM,
+// as browsers do, since an ASCII-compatible declaration can't be UTF-16.
+func lookupCharset(label string) (encoding.Encoding, string) {
+	label = strings.TrimSpace(strings.Trim(label, `"'`))
+	if label == "" {
+		return nil, ""
+	}
+	e, name := charset.Lookup(label)
+	if e == nil {
+		return nil, ""
+	}
+	if strings.HasPrefix(name, "utf-16") || name == "utf-8" {
+		return encoding.Nop, "utf-8"
+	}
+	return e, name
+}
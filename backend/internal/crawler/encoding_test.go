@@ -0,0 +1,112 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// serveEncoded serves body encoded with enc under the given Content-Type
+func serveEncoded(t *testing.T, enc encoding.Encoding, contentType, body string) *httptest.Server {
+	t.Helper()
+	raw, err := enc.NewEncoder().Bytes([]byte(body))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(raw)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func crawlEncoded(t *testing.T, enc encoding.Encoding, contentType, body string) Result {
+	t.Helper()
+	ts := serveEncoded(t, enc, contentType, body)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: pageAnalyzers})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	return res
+}
+
+func TestCharset_Detection(t *testing.T) {
+	cases := []struct {
+		name        string
+		enc         encoding.Encoding
+		contentType string
+		body        string
+		title       string
+		charset     string
+		source      string
+	}{
+		{"shift_jis from header", japanese.ShiftJIS, "text/html; charset=Shift_JIS",
+			`<html><head><title>日本語のページ</title></head></html>`, "日本語のページ", "shift_jis", CharsetFromHeader},
+		{"windows-1251 from meta", charmap.Windows1251, "text/html",
+			`<html><head><meta charset="windows-1251"><title>Привет, мир</title></head></html>`, "Привет, мир", "windows-1251", CharsetFromMeta},
+		{"http-equiv", charmap.ISO8859_1, "text/html",
+			`<html><head><meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><title>Café déjà vu</title></head></html>`,
+			"Café déjà vu", "windows-1252", CharsetFromMeta},
+		{"undeclared utf-8", encoding.Nop, "text/html",
+			`<html><head><title>Grüße</title></head></html>`, "Grüße", "utf-8", CharsetFromSniffed},
+		{"undeclared legacy", charmap.Windows1252, "text/html",
+			`<html><head><title>Señor</title></head></html>`, "Señor", "windows-1252", CharsetFromDefault},
+		{"utf-8 bom overrides header", encoding.Nop, "text/html; charset=iso-8859-1",
+			"\xef\xbb\xbf<html><head><title>naïve</title></head></html>", "naïve", "utf-8", CharsetFromBOM},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := crawlEncoded(t, tc.enc, tc.contentType, tc.body)
+			if res.Title == nil || *res.Title != tc.title {
+				t.Errorf("expected title %q, got %v", tc.title, res.Title)
+			}
+			if res.Charset.Name != tc.charset || res.Charset.Source != tc.source {
+				t.Errorf("expected %s from %s, got %+v", tc.charset, tc.source, res.Charset)
+			}
+			if res.Charset.Mismatch {
+				t.Errorf("unexpected mismatch: %+v", res.Charset)
+			}
+		})
+	}
+}
+
+func TestCharset_HeaderMetaMismatch(t *testing.T) {
+	res := crawlEncoded(t, charmap.Windows1251, "text/html; charset=windows-1251",
+		`<html><head><meta charset="utf-8"><title>Новости</title></head></html>`)
+
+	if res.Title == nil || *res.Title != "Новости" {
+		t.Errorf("expected the header's encoding to win, got %v", res.Title)
+	}
+	if !res.Charset.Mismatch || res.Charset.HeaderCharset != "windows-1251" || res.Charset.MetaCharset != "utf-8" {
+		t.Errorf("expected a header/meta mismatch, got %+v", res.Charset)
+	}
+}
+
+func TestCharset_TruncatedMidCharacter(t *testing.T) {
+	head := `<html><head><title>Grüße</title></head><body>`
+	res := crawlWithLimits(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(head + strings.Repeat("ü", 1000)))
+	}, Limits{MaxBodyBytes: int64(len(head)) + 1}) // cuts the first ü after its lead byte
+
+	if !res.Truncated {
+		t.Fatal("expected the body to be truncated")
+	}
+	if res.Charset.Name != "utf-8" || res.Charset.Source != CharsetFromSniffed {
+		t.Errorf("expected utf-8 to be sniffed despite the cut character, got %+v", res.Charset)
+	}
+	if res.Title == nil || *res.Title != "Grüße" {
+		t.Errorf("expected the title to be decoded as utf-8, got %v", res.Title)
+	}
+}
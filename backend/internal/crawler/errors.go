@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Error classes of failed fetches and link checks
+const (
+	ErrorClassDNS        = "dns"                // the host name doesn't resolve
+	ErrorClassConnect    = "connect"            // refused, unreachable, or the proxy couldn't connect
+	ErrorClassTLS        = "tls"                // handshake or certificate verification failed
+	ErrorClassTimeout    = "timeout"            // no response in time
+	ErrorClassReset      = "reset"              // the connection was reset or closed mid-response
+	ErrorClassRedirects  = "too_many_redirects" // redirect loop or chain over the limit
+	ErrorClassHTTPStatus = "http_status"        // the server answered 4xx or 5xx
+	ErrorClassBlocked    = "blocked"            // refused by the address guard
+	ErrorClassOther      = "other"
+)
+
+// ErrorClasses lists every error class
+var ErrorClasses = []string{
+	ErrorClassDNS, ErrorClassConnect, ErrorClassTLS, ErrorClassTimeout, ErrorClassReset,
+	ErrorClassRedirects, ErrorClassHTTPStatus, ErrorClassBlocked, ErrorClassOther,
+}
+
+// DefaultInaccessibleClasses are the link error classes counted as inaccessible until
+// SetInaccessibleClasses is called. Links the guard refused, and unclassified errors, aren't.
+func DefaultInaccessibleClasses() []string {
+	return []string{
+		ErrorClassDNS, ErrorClassConnect, ErrorClassTLS, ErrorClassTimeout, ErrorClassReset,
+		ErrorClassRedirects, ErrorClassHTTPStatus,
+	}
+}
+
+// ErrTooManyRedirects is returned by clients from HTTPClient when a redirect chain is too long
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// maxRedirects matches the net/http default
+const maxRedirects = 10
+
+// FetchError is returned when the page itself can't be fetched
+type FetchError struct {
+	Class string // one of the ErrorClass constants
+	Err   error
+}
+
+func (e *FetchError) Error() string { return e.Err.Error() }
+func (e *FetchError) Unwrap() error { return e.Err }
+
+// newFetchError classifies err, keeping msg as context
+func newFetchError(msg string, err error) *FetchError {
+	if msg != "" {
+		return &FetchError{Class: ClassifyError(err), Err: fmt.Errorf("%s: %w", msg, err)}
+	}
+	return &FetchError{Class: ClassifyError(err), Err: err}
+}
+
+// ClassifyError returns the error class of a failed request
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var fetchErr *FetchError
+	if errors.As(err, &fetchErr) {
+		return fetchErr.Class
+	}
+	if errors.Is(err, ErrBlockedAddress) {
+		return ErrorClassBlocked
+	}
+	if errors.Is(err, ErrTooManyRedirects) || strings.Contains(err.Error(), "stopped after 10 redirects") {
+		return ErrorClassRedirects
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassDNS
+	}
+	if isTLSError(err) {
+		return ErrorClassTLS
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorClassReset
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, syscall.ENETUNREACH) || IsProxyError(err) {
+		return ErrorClassConnect
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return ErrorClassConnect
+	}
+	return ErrorClassOther
+}
+
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		unknownAuth  x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidCert  x509.CertificateInvalidError
+		echRejection *tls.ECHRejectionError
+	)
+	return errors.As(err, &verifyErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuth) || errors.As(err, &hostnameErr) || errors.As(err, &invalidCert) ||
+		errors.As(err, &echRejection) || strings.Contains(err.Error(), "tls: ")
+}
+
+// SetInaccessibleClasses chooses which link error classes count as inaccessible
+func (c *Crawler) SetInaccessibleClasses(classes []string) error {
+	known := classSet(ErrorClasses)
+	for _, class := range classes {
+		if !known[class] {
+			return fmt.Errorf("unknown error class %q", class)
+		}
+	}
+	c.inaccessible = classSet(classes)
+	return nil
+}
+
+func classSet(classes []string) map[string]bool {
+	set := make(map[string]bool, len(classes))
+	for _, class := range classes {
+		set[class] = true
+	}
+	return set
+}
@@ -0,0 +1,148 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// closedURL returns the URL of a port nothing listens on
+func closedURL(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr + "/"
+}
+
+// linkErrorSite serves a page linking to one failing link per error class
+func linkErrorSite(t *testing.T) *httptest.Server {
+	tlsSite := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(tlsSite.Close)
+	refused := closedURL(t)
+
+	mux := http.NewServeMux()
+	var ts *httptest.Server
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><body>
+			<a href="%[1]s/ok">ok</a>
+			<a href="%[1]s/missing">missing</a>
+			<a href="%[1]s/loop">loop</a>
+			<a href="%[1]s/reset">reset</a>
+			<a href="http://no-such-host.invalid/">dns</a>
+			<a href="%[2]s">refused</a>
+			<a href="%[3]s/">tls</a>
+		</body></html>`, ts.URL, refused, tlsSite.URL)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/reset", func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	})
+	ts = httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestCrawl_LinkErrorsByClass(t *testing.T) {
+	ts := linkErrorSite(t)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := c.Crawl(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	want := map[string]int{
+		ErrorClassHTTPStatus: 1, ErrorClassRedirects: 1, ErrorClassReset: 1,
+		ErrorClassDNS: 1, ErrorClassConnect: 1, ErrorClassTLS: 1,
+	}
+	for class, n := range want {
+		if res.LinkErrors[class] != n {
+			t.Errorf("expected %d %s link errors, got %v", n, class, res.LinkErrors)
+		}
+	}
+	if len(res.LinkErrors) != len(want) {
+		t.Errorf("unexpected link error classes: %v", res.LinkErrors)
+	}
+	if res.InaccessibleLinks != 6 {
+		t.Errorf("expected every failing link to count as inaccessible, got %d", res.InaccessibleLinks)
+	}
+}
+
+func TestCrawl_InaccessibleClassesConfigurable(t *testing.T) {
+	ts := linkErrorSite(t)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	if err := c.SetInaccessibleClasses([]string{ErrorClassHTTPStatus, ErrorClassDNS}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := c.Crawl(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.InaccessibleLinks != 2 {
+		t.Errorf("expected only http_status and dns links to count, got %d (%v)", res.InaccessibleLinks, res.LinkErrors)
+	}
+	if res.LinkErrors[ErrorClassConnect] != 1 {
+		t.Errorf("expected the other classes to be reported anyway, got %v", res.LinkErrors)
+	}
+
+	if err := c.SetInaccessibleClasses([]string{"nxdomain"}); err == nil {
+		t.Error("expected an unknown class to be rejected")
+	}
+}
+
+func TestCheckLinkAccessibility_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	baseURL, _ := url.Parse(ts.URL)
+	client := HTTPClient(5*time.Second, WithoutAddressGuard())
+	if class := checkLinkAccessibility(ctx, baseURL, ts.URL+"/slow", client, nil); class != ErrorClassTimeout {
+		t.Errorf("expected %s, got %q", ErrorClassTimeout, class)
+	}
+}
+
+func TestCrawl_FetchErrorClass(t *testing.T) {
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	_, err := c.Crawl(context.Background(), closedURL(t))
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Class != ErrorClassConnect {
+		t.Fatalf("expected a connect FetchError, got %#v", err)
+	}
+	if ClassifyError(fmt.Errorf("job: %w", err)) != ErrorClassConnect {
+		t.Error("expected the class to survive wrapping")
+	}
+
+	guarded := New(HTTPClient(5 * time.Second))
+	_, err = guarded.Crawl(context.Background(), "http://127.0.0.1/")
+	if ClassifyError(err) != ErrorClassBlocked {
+		t.Errorf("expected a blocked address to be classified as blocked, got %v", err)
+	}
+}
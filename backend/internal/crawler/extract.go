@@ -0,0 +1,215 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// Selector types of extraction rules
+const (
+	SelectorCSS   = "css"
+	SelectorXPath = "xpath"
+)
+
+// ExtractionRule describes a named value to pull out of a page
+type ExtractionRule struct {
+	Name      string
+	Type      string // SelectorCSS or SelectorXPath
+	Selector  string
+	Attribute string // attribute to read from matched elements; empty reads their text
+	Multiple  bool   // collect all matches instead of the first one
+	Regex     string // optional post-processing; keeps the first capture group or the whole match
+}
+
+type compiledRule struct {
+	ExtractionRule
+	css cascadia.SelectorGroup
+	re  *regexp.Regexp
+
+	mu    sync.Mutex // an xpath.Expr keeps evaluation state, so evaluations take turns
+	xpath *xpath.Expr
+}
+
+// ValidateRule checks that a rule compiles, so broken rules are rejected when they are saved
+func ValidateRule(r ExtractionRule) error {
+	_, err := compileRule(r)
+	return err
+}
+
+func compileRule(r ExtractionRule) (*compiledRule, error) {
+	if strings.TrimSpace(r.Name) == "" {
+		return nil, fmt.Errorf("rule name must not be empty")
+	}
+	if strings.TrimSpace(r.Selector) == "" {
+		return nil, fmt.Errorf("selector must not be empty")
+	}
+	c := &compiledRule{ExtractionRule: r}
+	var err error
+	switch r.Type {
+	case SelectorCSS:
+		if c.css, err = cascadia.ParseGroup(r.Selector); err != nil {
+			return nil, fmt.Errorf("invalid CSS selector: %w", err)
+		}
+	case SelectorXPath:
+		if c.xpath, err = xpath.Compile(r.Selector); err != nil {
+			return nil, fmt.Errorf("invalid XPath expression: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported selector type %q (want %s or %s)", r.Type, SelectorCSS, SelectorXPath)
+	}
+	if r.Regex != "" {
+		if c.re, err = regexp.Compile(r.Regex); err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// values evaluates the rule against a document. A relative XPath expression is evaluated
+// against the document node, so it needs to start from html to match anything.
+func (c *compiledRule) values(doc *html.Node) []string {
+	var raw []string
+	if c.css != nil {
+		for _, n := range cascadia.QueryAll(doc, c.css) {
+			raw = append(raw, c.read(n))
+		}
+	} else {
+		raw = c.evaluateXPath(doc)
+	}
+
+	out := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if c.re != nil {
+			m := c.re.FindStringSubmatch(v)
+			if m == nil {
+				continue
+			}
+			v = m[0]
+			if len(m) > 1 {
+				v = m[1]
+			}
+		}
+		out = append(out, v)
+		if !c.Multiple {
+			break
+		}
+	}
+	return out
+}
+
+// evaluateXPath returns the values the expression selects: attribute values, the text of
+// elements and text nodes, or the value of a string, number or boolean expression
+func (c *compiledRule) evaluateXPath(doc *html.Node) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch v := c.xpath.Evaluate(htmlquery.CreateXPathNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		var raw []string
+		for v.MoveNext() {
+			nav, ok := v.Current().(*htmlquery.NodeNavigator)
+			if !ok {
+				continue
+			}
+			if nav.NodeType() == xpath.AttributeNode {
+				raw = append(raw, strings.TrimSpace(nav.Value()))
+			} else {
+				raw = append(raw, c.read(nav.Current()))
+			}
+		}
+		return raw
+	case string:
+		return []string{strings.TrimSpace(v)}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	}
+	return nil
+}
+
+func (c *compiledRule) read(n *html.Node) string {
+	if c.Attribute != "" && n.Type == html.ElementNode {
+		v, _ := nodeAttr(n, c.Attribute)
+		return strings.TrimSpace(v)
+	}
+	return textContent(n)
+}
+
+// ExtractFindings maps rule names to extracted values: a string (or nil when nothing matched)
+// for single rules and a list of strings for multiple ones
+type ExtractFindings struct {
+	Values map[string]any    `json:"values"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// maxCompiledRules bounds the rules an ExtractAnalyzer keeps compiled; the cache starts over
+// when it is full
+const maxCompiledRules = 1024
+
+// ExtractAnalyzer evaluates the crawl's extraction rules on the parsed document. Compiled rules
+// are kept across crawls.
+type ExtractAnalyzer struct {
+	mu       sync.Mutex
+	compiled map[ExtractionRule]*compiledRule
+}
+
+func NewExtractAnalyzer() *ExtractAnalyzer {
+	return &ExtractAnalyzer{compiled: make(map[ExtractionRule]*compiledRule)}
+}
+
+func (*ExtractAnalyzer) Name() string { return "extract" }
+
+// compile returns the compiled form of r, compiling it on first use
+func (a *ExtractAnalyzer) compile(r ExtractionRule) (*compiledRule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if c, ok := a.compiled[r]; ok {
+		return c, nil
+	}
+	c, err := compileRule(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.compiled) >= maxCompiledRules {
+		clear(a.compiled)
+	}
+	a.compiled[r] = c
+	return c, nil
+}
+
+func (a *ExtractAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	out := ExtractFindings{Values: make(map[string]any, len(page.Options.Rules))}
+	if len(page.Options.Rules) == 0 {
+		return out, nil
+	}
+	doc := page.Document()
+	for _, r := range page.Options.Rules {
+		c, err := a.compile(r)
+		if err != nil {
+			if out.Errors == nil {
+				out.Errors = make(map[string]string)
+			}
+			out.Errors[r.Name] = err.Error()
+			continue
+		}
+		vals := c.values(doc)
+		switch {
+		case r.Multiple:
+			out.Values[r.Name] = vals
+		case len(vals) > 0:
+			out.Values[r.Name] = vals[0]
+		default:
+			out.Values[r.Name] = nil
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,188 @@
+package crawler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const productPage = `<!doctype html><html><head>
+  <meta name="author" content="Jane Doe">
+  <meta property="article:published_time" content="2024-05-01T10:00:00Z">
+</head><body>
+  <div class="product featured" id="main">
+    <h1 class="title">Blue Widget</h1>
+    <span class="price">Price: EUR 19.99</span>
+    <span data-sku="BW-001" class="sku">SKU</span>
+    <ul class="tags"><li>blue</li><li>widget</li><li>sale</li></ul>
+  </div>
+  <div class="product"><h1 class="title">Red Widget</h1><span class="price">EUR 5.00</span></div>
+</body></html>`
+
+func parseDoc(t *testing.T, s string) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return doc
+}
+
+// countMatches returns how many values a multiple-valued rule finds in doc
+func countMatches(t *testing.T, doc *html.Node, typ, sel string) int {
+	t.Helper()
+	c, err := compileRule(ExtractionRule{Name: "r", Type: typ, Selector: sel, Multiple: true})
+	if err != nil {
+		t.Fatalf("compile %q: %v", sel, err)
+	}
+	return len(c.values(doc))
+}
+
+func TestSelector_Matching(t *testing.T) {
+	doc := parseDoc(t, productPage)
+	cases := map[string]int{
+		"h1":                           2,
+		"div.product":                  2,
+		"div.product.featured":         1,
+		"#main > h1":                   1,
+		"div span.price":               2,
+		"[data-sku]":                   1,
+		"span[data-sku^=BW]":           1,
+		"meta[name='author']":          1,
+		"ul.tags li:first-child":       1,
+		"ul.tags li:nth-child(odd)":    2,
+		"ul.tags li:nth-child(2n+1)":   2,
+		"ul.tags li:nth-child(-n+2)":   2,
+		"ul.tags li:nth-last-child(1)": 1,
+		"ul.tags li:last-child":        1,
+		"div.product:not(.featured)":   1,
+		"h1 + span":                    2,
+		"h1 ~ ul":                      1,
+		"h1.title, ul":                 3,
+		"body > h1":                    0,
+	}
+	for sel, want := range cases {
+		if got := countMatches(t, doc, SelectorCSS, sel); got != want {
+			t.Errorf("%q: expected %d matches, got %d", sel, want, got)
+		}
+	}
+}
+
+func TestSelector_InvalidSyntax(t *testing.T) {
+	for _, sel := range []string{"", "div[", "div >", ".", "p,", "li:nth-child(2n+)"} {
+		if err := ValidateRule(ExtractionRule{Name: "r", Type: SelectorCSS, Selector: sel}); err == nil {
+			t.Errorf("expected error for %q", sel)
+		}
+	}
+}
+
+func TestXPath_Evaluate(t *testing.T) {
+	doc := parseDoc(t, productPage)
+	cases := map[string][]string{
+		"//h1/text()":                                                   {"Blue Widget", "Red Widget"},
+		"//span[@data-sku]/@data-sku":                                   {"BW-001"},
+		"//meta[@name='author']/@content":                               {"Jane Doe"},
+		"//div[@id='main']/ul/li[2]/text()":                             {"widget"},
+		"//ul/li[last()]/text()":                                        {"sale"},
+		"//ul/li[position()>1]":                                         {"widget", "sale"},
+		"//div[contains(@class,'featured')]/h1/text()":                  {"Blue Widget"},
+		"/html/body/div[2]/span/text()":                                 {"EUR 5.00"},
+		"//span[starts-with(text(),'Price') and @class='price']/text()": {"Price: EUR 19.99"},
+		"count(//li)":                                                   {"3"},
+		"normalize-space(//h1)":                                         {"Blue Widget"},
+		// relative paths start at the document node, not anywhere in it
+		"html/body/div/h1": {"Blue Widget", "Red Widget"},
+		"div/h1":           nil,
+	}
+	for expr, want := range cases {
+		c, err := compileRule(ExtractionRule{Name: "r", Type: SelectorXPath, Selector: expr, Multiple: true})
+		if err != nil {
+			t.Fatalf("compile %q: %v", expr, err)
+		}
+		got := c.values(doc)
+		if len(got) == 0 {
+			got = nil
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected %v, got %v", expr, want, got)
+		}
+	}
+}
+
+func TestXPath_InvalidSyntax(t *testing.T) {
+	for _, expr := range []string{"", "//div[", "//a/", "//div[@class='x'"} {
+		if err := ValidateRule(ExtractionRule{Name: "r", Type: SelectorXPath, Selector: expr}); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestCrawl_ExtractionRules(t *testing.T) {
+	ts := serveHTML(t, productPage)
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rules := []ExtractionRule{
+		{Name: "title", Type: SelectorCSS, Selector: "#main h1"},
+		{Name: "price", Type: SelectorCSS, Selector: ".price", Regex: `EUR ([0-9.]+)`, Multiple: true},
+		{Name: "sku", Type: SelectorCSS, Selector: "[data-sku]", Attribute: "data-sku"},
+		{Name: "author", Type: SelectorXPath, Selector: "//meta[@name='author']/@content"},
+		{Name: "published", Type: SelectorXPath, Selector: "//meta[@property='article:published_time']", Attribute: "content", Regex: `^\d{4}-\d{2}-\d{2}`},
+		{Name: "tags", Type: SelectorXPath, Selector: "//ul[@class='tags']/li", Multiple: true},
+		{Name: "missing", Type: SelectorCSS, Selector: ".nope"},
+		{Name: "broken", Type: SelectorCSS, Selector: "div["},
+	}
+	res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"extract"}, Rules: rules})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	out, ok := res.Findings["extract"].(ExtractFindings)
+	if !ok {
+		t.Fatalf("expected ExtractFindings, got %#v", res.Findings["extract"])
+	}
+	want := map[string]any{
+		"title":     "Blue Widget",
+		"price":     []string{"19.99", "5.00"},
+		"sku":       "BW-001",
+		"author":    "Jane Doe",
+		"published": "2024-05-01",
+		"tags":      []string{"blue", "widget", "sale"},
+		"missing":   nil,
+	}
+	if !reflect.DeepEqual(out.Values, want) {
+		t.Fatalf("unexpected values:\n got %#v\nwant %#v", out.Values, want)
+	}
+	if out.Errors["broken"] == "" {
+		t.Fatalf("expected compile error for broken rule, got %#v", out.Errors)
+	}
+}
+
+func TestCrawl_RulesRunWithoutExtractSelected(t *testing.T) {
+	ts := serveHTML(t, productPage)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rules := []ExtractionRule{{Name: "sku", Type: SelectorCSS, Selector: "[data-sku]", Attribute: "data-sku"}}
+	for range 2 {
+		res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"headings"}, Rules: rules})
+		if err != nil {
+			t.Fatalf("crawl error: %v", err)
+		}
+		out, ok := res.Findings["extract"].(ExtractFindings)
+		if !ok || out.Values["sku"] != "BW-001" {
+			t.Fatalf("expected the rules to be evaluated, got %#v", res.Findings["extract"])
+		}
+	}
+	// the second crawl reused the compiled rule
+	extract, _ := c.Analyzers().Resolve([]string{"extract"})
+	if n := len(extract[0].(*ExtractAnalyzer).compiled); n != 1 {
+		t.Errorf("expected 1 compiled rule, got %d", n)
+	}
+}
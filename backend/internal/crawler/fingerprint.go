@@ -0,0 +1,313 @@
+package crawler
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+//go:embed rules/technologies.json
+var defaultTechnologyRules []byte
+
+// TechnologyRule describes how to recognise a technology. Every pattern is a case-insensitive
+// regular expression, optionally followed by `\;`-separated tags: `version:\1` builds the version
+// from capture groups and `confidence:50` lowers how much the match counts (default 100).
+// An empty header pattern matches on the header's presence.
+type TechnologyRule struct {
+	Name     string            `json:"name"`
+	Category string            `json:"category"`
+	Headers  map[string]string `json:"headers,omitempty"` // header name -> pattern on its value
+	Meta     map[string]string `json:"meta,omitempty"`    // meta name -> pattern on its content
+	Scripts  []string          `json:"scripts,omitempty"` // patterns on script src URLs
+	Cookies  []string          `json:"cookies,omitempty"` // patterns on names of cookies set by the response
+	HTML     []string          `json:"html,omitempty"`    // patterns on the raw document
+}
+
+// Technology is a technology detected on a page
+type Technology struct {
+	Name       string   `json:"name"`
+	Category   string   `json:"category"`
+	Version    string   `json:"version,omitempty"`
+	Confidence int      `json:"confidence"` // 0-100
+	Evidence   []string `json:"evidence"`   // what matched, e.g. "header:Server", "script"
+}
+
+type FingerprintFindings struct {
+	Technologies []Technology `json:"technologies"`
+}
+
+type techPattern struct {
+	re         *regexp.Regexp
+	version    string
+	confidence int
+}
+
+// namedPattern is a pattern on a named header or meta tag
+type namedPattern struct {
+	name string
+	techPattern
+}
+
+type compiledTechnology struct {
+	name     string
+	category string
+	headers  []namedPattern // sorted by name so results are deterministic
+	meta     []namedPattern
+	scripts  []techPattern
+	cookies  []techPattern
+	html     []techPattern
+}
+
+// FingerprintAnalyzer detects CMSs, frameworks, analytics, CDNs and servers from a rule set
+type FingerprintAnalyzer struct {
+	techs []compiledTechnology
+}
+
+// defaultFingerprintAnalyzer compiles the bundled rule set
+func defaultFingerprintAnalyzer() *FingerprintAnalyzer {
+	a, err := NewFingerprintAnalyzer(DefaultTechnologyRules())
+	if err != nil {
+		panic(fmt.Sprintf("bundled technology rules are invalid: %v", err))
+	}
+	return a
+}
+
+// DefaultTechnologyRules returns the bundled rule set
+func DefaultTechnologyRules() []TechnologyRule {
+	rules, err := parseTechnologyRules(defaultTechnologyRules)
+	if err != nil {
+		panic(fmt.Sprintf("bundled technology rules are invalid: %v", err))
+	}
+	return rules
+}
+
+// LoadTechnologyRules reads a rule set from a JSON file holding a list of rules
+func LoadTechnologyRules(path string) ([]TechnologyRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseTechnologyRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func parseTechnologyRules(data []byte) ([]TechnologyRule, error) {
+	var rules []TechnologyRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid technology rules: %w", err)
+	}
+	return rules, nil
+}
+
+// NewFingerprintAnalyzer compiles a rule set. A rule replaces any earlier rule with the same name,
+// so a custom file appended to the defaults can override bundled rules.
+func NewFingerprintAnalyzer(rules []TechnologyRule) (*FingerprintAnalyzer, error) {
+	index := make(map[string]int, len(rules))
+	var techs []compiledTechnology
+	for _, r := range rules {
+		if strings.TrimSpace(r.Name) == "" {
+			return nil, fmt.Errorf("technology rule without a name")
+		}
+		t, err := compileTechnology(r)
+		if err != nil {
+			return nil, fmt.Errorf("technology %s: %w", r.Name, err)
+		}
+		if i, ok := index[r.Name]; ok {
+			techs[i] = t
+			continue
+		}
+		index[r.Name] = len(techs)
+		techs = append(techs, t)
+	}
+	return &FingerprintAnalyzer{techs: techs}, nil
+}
+
+func compileTechnology(r TechnologyRule) (compiledTechnology, error) {
+	t := compiledTechnology{name: r.Name, category: r.Category}
+	var err error
+	if t.headers, err = compileNamedPatterns(r.Headers, http.CanonicalHeaderKey); err != nil {
+		return t, err
+	}
+	if t.meta, err = compileNamedPatterns(r.Meta, strings.ToLower); err != nil {
+		return t, err
+	}
+	for _, group := range []struct {
+		src []string
+		dst *[]techPattern
+	}{{r.Scripts, &t.scripts}, {r.Cookies, &t.cookies}, {r.HTML, &t.html}} {
+		for _, p := range group.src {
+			c, err := compileTechPattern(p)
+			if err != nil {
+				return t, err
+			}
+			*group.dst = append(*group.dst, c)
+		}
+	}
+	return t, nil
+}
+
+func compileNamedPatterns(patterns map[string]string, normalize func(string) string) ([]namedPattern, error) {
+	out := make([]namedPattern, 0, len(patterns))
+	for name, p := range patterns {
+		c, err := compileTechPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, namedPattern{name: normalize(name), techPattern: c})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out, nil
+}
+
+func compileTechPattern(p string) (techPattern, error) {
+	parts := strings.Split(p, `\;`)
+	re, err := regexp.Compile("(?i)" + parts[0])
+	if err != nil {
+		return techPattern{}, fmt.Errorf("invalid pattern %q: %w", parts[0], err)
+	}
+	c := techPattern{re: re, confidence: 100}
+	for _, tag := range parts[1:] {
+		key, value, _ := strings.Cut(tag, ":")
+		switch key {
+		case "version":
+			c.version = value
+		case "confidence":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 || n > 100 {
+				return techPattern{}, fmt.Errorf("invalid confidence %q", value)
+			}
+			c.confidence = n
+		default:
+			return techPattern{}, fmt.Errorf("unknown pattern tag %q", key)
+		}
+	}
+	return c, nil
+}
+
+// match returns the version the pattern extracts and whether it matched
+func (p techPattern) match(s string) (string, bool) {
+	m := p.re.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	if p.version == "" {
+		return "", true
+	}
+	version := p.version
+	for i := len(m) - 1; i >= 1; i-- {
+		version = strings.ReplaceAll(version, `\`+strconv.Itoa(i), m[i])
+	}
+	return strings.TrimSpace(version), true
+}
+
+func (*FingerprintAnalyzer) Name() string { return "technologies" }
+
+func (a *FingerprintAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	meta := make(map[string][]string)
+	var scripts []string
+	for _, t := range page.Tokens {
+		if t.Type != html.StartTagToken && t.Type != html.SelfClosingTagToken {
+			continue
+		}
+		switch strings.ToLower(t.Data) {
+		case "meta":
+			name, _ := attr(t, "name")
+			if content, ok := attr(t, "content"); ok && name != "" {
+				meta[strings.ToLower(name)] = append(meta[strings.ToLower(name)], content)
+			}
+		case "script":
+			if src, ok := attr(t, "src"); ok && src != "" {
+				scripts = append(scripts, src)
+			}
+		}
+	}
+	cookies := responseCookies(page.Header)
+	body := string(page.Body)
+
+	out := FingerprintFindings{Technologies: []Technology{}}
+	for _, tech := range a.techs {
+		d := detection{}
+		for _, p := range tech.headers {
+			for _, v := range page.Header.Values(p.name) {
+				d.try(p.techPattern, v, "header:"+p.name)
+			}
+		}
+		for _, p := range tech.meta {
+			for _, v := range meta[p.name] {
+				d.try(p.techPattern, v, "meta:"+p.name)
+			}
+		}
+		for _, p := range tech.scripts {
+			for _, src := range scripts {
+				d.try(p, src, "script")
+			}
+		}
+		for _, p := range tech.cookies {
+			for _, c := range cookies {
+				d.try(p, c.Name, "cookie:"+c.Name)
+			}
+		}
+		for _, p := range tech.html {
+			d.try(p, body, "html")
+		}
+		if len(d.evidence) == 0 {
+			continue
+		}
+		out.Technologies = append(out.Technologies, Technology{
+			Name:       tech.name,
+			Category:   tech.category,
+			Version:    d.version,
+			Confidence: min(d.confidence, 100),
+			Evidence:   d.evidence,
+		})
+	}
+	sort.Slice(out.Technologies, func(i, j int) bool { return out.Technologies[i].Name < out.Technologies[j].Name })
+	return out, nil
+}
+
+// detection accumulates the matches of one technology; each piece of evidence counts once
+type detection struct {
+	version    string
+	confidence int
+	evidence   []string
+}
+
+func (d *detection) try(p techPattern, s, evidence string) {
+	version, ok := p.match(s)
+	if !ok {
+		return
+	}
+	if version != "" && d.version == "" {
+		d.version = version
+	}
+	for _, e := range d.evidence {
+		if e == evidence {
+			return
+		}
+	}
+	d.evidence = append(d.evidence, evidence)
+	d.confidence += p.confidence
+}
+
+// responseCookies parses the cookies set by a response
+func responseCookies(h http.Header) []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, line := range h.Values("Set-Cookie") {
+		if c, err := http.ParseSetCookie(line); err == nil {
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies
+}
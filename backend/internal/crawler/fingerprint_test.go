@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func fingerprint(t *testing.T, a *FingerprintAnalyzer, header http.Header, body string) map[string]Technology {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, vs := range header {
+			for _, v := range vs {
+				w.Header().Add(k, v)
+			}
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	if a != nil {
+		c.Analyzers().Register(a)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"technologies"}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	out, ok := res.Findings["technologies"].(FingerprintFindings)
+	if !ok {
+		t.Fatalf("unexpected findings: %#v", res.Findings["technologies"])
+	}
+	byName := make(map[string]Technology, len(out.Technologies))
+	for _, tech := range out.Technologies {
+		byName[tech.Name] = tech
+	}
+	return byName
+}
+
+func TestFingerprint_BundledRules(t *testing.T) {
+	header := http.Header{}
+	header.Set("Server", "nginx/1.25.3")
+	header.Set("X-Powered-By", "PHP/8.2.1")
+	header.Set("CF-RAY", "8123-AMS")
+	header.Add("Set-Cookie", "_ga=GA1.1.123; Path=/")
+
+	techs := fingerprint(t, nil, header, `<!doctype html><html><head>
+      <meta name="generator" content="WordPress 6.4.2">
+      <link rel="stylesheet" href="/wp-content/themes/x/style.css">
+      <script src="/wp-includes/js/jquery/jquery-3.7.1.min.js"></script>
+      <script async src="https://www.googletagmanager.com/gtag/js?id=G-1"></script>
+    </head><body></body></html>`)
+
+	want := map[string]string{
+		"WordPress":        "6.4.2",
+		"Nginx":            "1.25.3",
+		"PHP":              "8.2.1",
+		"jQuery":           "3.7.1",
+		"Cloudflare":       "",
+		"Google Analytics": "",
+	}
+	for name, version := range want {
+		tech, ok := techs[name]
+		if !ok {
+			t.Errorf("expected %s to be detected, got %v", name, techs)
+			continue
+		}
+		if tech.Version != version {
+			t.Errorf("%s: expected version %q, got %q", name, version, tech.Version)
+		}
+		if tech.Confidence != 100 {
+			t.Errorf("%s: expected confidence 100, got %d", name, tech.Confidence)
+		}
+	}
+	if wp := techs["WordPress"]; wp.Category != "CMS" || len(wp.Evidence) != 3 {
+		t.Errorf("unexpected WordPress detection: %+v", wp)
+	}
+	if _, ok := techs["Drupal"]; ok {
+		t.Error("Drupal should not be detected")
+	}
+}
+
+func TestFingerprint_CustomRulesOverrideBundled(t *testing.T) {
+	custom := []TechnologyRule{
+		{Name: "Acme CMS", Category: "CMS", HTML: []string{`acme-cms-v(\d+)\;version:\1\;confidence:40`}, Cookies: []string{"^acme_session$"}},
+		{Name: "Nginx", Category: "Web server", Headers: map[string]string{"x-served-by": "^edge"}},
+	}
+	a, err := NewFingerprintAnalyzer(append(DefaultTechnologyRules(), custom...))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	header := http.Header{}
+	header.Set("Server", "nginx")
+	header.Set("X-Served-By", "edge-42")
+
+	techs := fingerprint(t, a, header, `<html><body class="acme-cms-v3"></body></html>`)
+
+	if acme := techs["Acme CMS"]; acme.Version != "3" || acme.Confidence != 40 {
+		t.Errorf("unexpected custom detection: %+v", acme)
+	}
+	// the custom Nginx rule replaced the bundled Server header rule
+	if nginx := techs["Nginx"]; len(nginx.Evidence) != 1 || nginx.Evidence[0] != "header:X-Served-By" {
+		t.Errorf("expected overriding rule to match, got %+v", nginx)
+	}
+}
+
+func TestFingerprint_InvalidRules(t *testing.T) {
+	cases := []TechnologyRule{
+		{Name: "", HTML: []string{"x"}},
+		{Name: "Bad regex", HTML: []string{"("}},
+		{Name: "Bad tag", HTML: []string{`x\;colour:red`}},
+		{Name: "Bad confidence", HTML: []string{`x\;confidence:200`}},
+	}
+	for _, r := range cases {
+		if _, err := NewFingerprintAnalyzer([]TechnologyRule{r}); err == nil {
+			t.Errorf("expected error for rule %+v", r)
+		}
+	}
+}
+
+func TestLoadTechnologyRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "Acme", "category": "CMS", "meta": {"generator": "^Acme"}}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadTechnologyRules(path)
+	if err != nil || len(rules) != 1 || rules[0].Meta["generator"] != "^Acme" {
+		t.Fatalf("unexpected rules %+v (%v)", rules, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"name": "not a list"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTechnologyRules(path); err == nil {
+		t.Fatal("expected error for malformed file")
+	}
+}
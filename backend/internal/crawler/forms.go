@@ -0,0 +1,318 @@
+package crawler
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Form types
+const (
+	FormLogin          = "login"
+	FormSignup         = "signup"
+	FormPasswordChange = "password_change"
+	FormSearch         = "search"
+	FormNewsletter     = "newsletter"
+	FormPayment        = "payment"
+	FormContact        = "contact"
+	FormOther          = "other"
+)
+
+// FormField is a control of a form that submits a value
+type FormField struct {
+	Name         string `json:"name,omitempty"`
+	Type         string `json:"type"` // input type, or "select" / "textarea"
+	Autocomplete string `json:"autocomplete,omitempty"`
+	Required     bool   `json:"required"`
+}
+
+// Form is a form found on a page
+type Form struct {
+	Type   string      `json:"type"`
+	ID     string      `json:"id,omitempty"`
+	Action string      `json:"action"` // resolved; the page URL when the form has no action
+	Method string      `json:"method"`
+	Fields []FormField `json:"fields"`
+	// CSRFFields names the hidden fields that look like anti-forgery tokens
+	CSRFFields    []string `json:"csrf_fields"`
+	SubmitButtons []string `json:"submit_buttons"`
+	// Standalone is set for the pseudo-form grouping controls outside any <form> element
+	Standalone bool `json:"standalone"`
+	// Hidden holds the values of the hidden fields by name, which a submission sends along.
+	// They are often per-visitor tokens, so they aren't stored with the findings.
+	Hidden map[string]string `json:"-"`
+}
+
+var (
+	csrfFieldPattern     = regexp.MustCompile(`(?i)csrf|xsrf|authenticity_token|requestverificationtoken|^_token$|nonce`)
+	usernameFieldPattern = regexp.MustCompile(`(?i)user|login|email|e-mail|account|ident`)
+	confirmFieldPattern  = regexp.MustCompile(`(?i)confirm|repeat|again|verify|password_?2|pass_?2`)
+	cardFieldPattern     = regexp.MustCompile(`(?i)card.?(number|num|no)|cc.?(number|num)|cvc|cvv|csc|expir`)
+	searchFieldPattern   = regexp.MustCompile(`(?i)^(q|s|query|search|keywords?|term)$`)
+	signupWords          = regexp.MustCompile(`(?i)sign.?up|register|registration|create.?account|join`)
+	passwordChangeWords  = regexp.MustCompile(`(?i)(change|update|reset|new).?password`)
+	newsletterWords      = regexp.MustCompile(`(?i)newsletter|subscribe|mailing.?list`)
+	contactWords         = regexp.MustCompile(`(?i)contact|message|enquiry|inquiry|feedback`)
+)
+
+// nonDataInputs are input types that don't submit a typed value
+var nonDataInputs = map[string]bool{"submit": true, "button": true, "reset": true, "image": true}
+
+// FormsAnalyzer extracts and classifies the forms of a page. A login form among them sets
+// Result.HasLoginForm.
+type FormsAnalyzer struct{}
+
+func (FormsAnalyzer) Name() string { return "forms" }
+
+func (FormsAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	return analyzeForms(page), nil
+}
+
+func (FormsAnalyzer) Apply(res *Result, findings any) any {
+	forms, _ := findings.([]Form)
+	res.HasLoginForm = slices.ContainsFunc(forms, func(f Form) bool { return f.Type == FormLogin })
+	return findings
+}
+
+// analyzeForms extracts and classifies the forms of a page. Controls outside any form that
+// aren't attached to one with the form attribute are grouped into one standalone form.
+func analyzeForms(page *Page) []Form {
+	type formNode struct {
+		node     *html.Node
+		controls []*html.Node
+	}
+	var forms []*formNode
+	byID := make(map[string]*formNode)
+	var orphans []*html.Node
+
+	var walk func(n *html.Node, owner *formNode)
+	walk = func(n *html.Node, owner *formNode) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "form":
+				f := &formNode{node: c}
+				forms = append(forms, f)
+				if id, ok := nodeAttr(c, "id"); ok && id != "" {
+					byID[id] = f
+				}
+				walk(c, f)
+				continue
+			case "input", "select", "textarea", "button":
+				if owner != nil {
+					owner.controls = append(owner.controls, c)
+				} else {
+					orphans = append(orphans, c)
+				}
+			}
+			walk(c, owner)
+		}
+	}
+	walk(page.Document(), nil)
+
+	// controls can name their form with the form attribute
+	var standalone []*html.Node
+	for _, c := range orphans {
+		if id, ok := nodeAttr(c, "form"); ok {
+			if f, ok := byID[id]; ok {
+				f.controls = append(f.controls, c)
+				continue
+			}
+		}
+		standalone = append(standalone, c)
+	}
+
+	out := make([]Form, 0, len(forms)+1)
+	for _, f := range forms {
+		out = append(out, buildForm(page, f.node, f.controls))
+	}
+	if form := buildForm(page, nil, standalone); len(form.Fields) > 0 && hasVisibleField(form) {
+		form.Standalone = true
+		out = append(out, form)
+	}
+	return out
+}
+
+func buildForm(page *Page, n *html.Node, controls []*html.Node) Form {
+	form := Form{Method: "GET", Fields: []FormField{}, CSRFFields: []string{}, SubmitButtons: []string{}}
+	var hints []string // text the classifier looks for keywords in
+	if n != nil {
+		form.ID, _ = nodeAttr(n, "id")
+		if m, ok := nodeAttr(n, "method"); ok && strings.EqualFold(strings.TrimSpace(m), "post") {
+			form.Method = "POST"
+		}
+		// a form without an action submits to the page itself, whatever its base URL
+		action, _ := nodeAttr(n, "action")
+		if action = strings.TrimSpace(action); action == "" && page.URL != nil {
+			form.Action = page.URL.String()
+		} else if u, err := page.Resolve(action); err == nil {
+			form.Action = u.String()
+		}
+		for _, key := range []string{"id", "name", "class", "action", "aria-label"} {
+			if v, ok := nodeAttr(n, key); ok {
+				hints = append(hints, v)
+			}
+		}
+		if role, _ := nodeAttr(n, "role"); strings.EqualFold(role, "search") {
+			hints = append(hints, "search")
+		}
+	} else if page.URL != nil {
+		form.Action = page.URL.String()
+	}
+
+	for _, c := range controls {
+		name, _ := nodeAttr(c, "name")
+		switch c.Data {
+		case "button":
+			typ, _ := nodeAttr(c, "type")
+			if typ == "" || strings.EqualFold(typ, "submit") {
+				label := textContent(c)
+				if label == "" {
+					label, _ = nodeAttr(c, "value")
+				}
+				form.SubmitButtons = append(form.SubmitButtons, label)
+				hints = append(hints, label, name)
+			}
+			continue
+		case "input":
+			typ, _ := nodeAttr(c, "type")
+			typ = strings.ToLower(strings.TrimSpace(typ))
+			if typ == "" {
+				typ = "text"
+			}
+			if nonDataInputs[typ] {
+				if typ == "submit" || typ == "image" {
+					label, ok := nodeAttr(c, "value")
+					if !ok && typ == "image" {
+						label, _ = nodeAttr(c, "alt")
+					}
+					if !ok && typ == "submit" {
+						label = "Submit"
+					}
+					form.SubmitButtons = append(form.SubmitButtons, label)
+					hints = append(hints, label, name)
+				}
+				continue
+			}
+			if typ == "hidden" && name != "" {
+				if form.Hidden == nil {
+					form.Hidden = make(map[string]string)
+				}
+				form.Hidden[name], _ = nodeAttr(c, "value")
+				if csrfFieldPattern.MatchString(name) {
+					form.CSRFFields = append(form.CSRFFields, name)
+				}
+			}
+			form.Fields = append(form.Fields, newFormField(c, name, typ))
+		default:
+			form.Fields = append(form.Fields, newFormField(c, name, c.Data))
+		}
+		if placeholder, ok := nodeAttr(c, "placeholder"); ok {
+			hints = append(hints, placeholder)
+		}
+	}
+	form.Type = classifyForm(form, strings.Join(hints, " "))
+	return form
+}
+
+func newFormField(n *html.Node, name, typ string) FormField {
+	autocomplete, _ := nodeAttr(n, "autocomplete")
+	_, required := nodeAttr(n, "required")
+	return FormField{
+		Name:         name,
+		Type:         typ,
+		Autocomplete: strings.ToLower(strings.TrimSpace(autocomplete)),
+		Required:     required,
+	}
+}
+
+func hasVisibleField(f Form) bool {
+	for _, field := range f.Fields {
+		if field.Type != "hidden" {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyForm guesses what a form is for from its fields, their autocomplete hints and the
+// words used in its attributes and buttons. Password forms are told apart by the number of
+// password fields and the current-password / new-password hints browsers rely on.
+func classifyForm(f Form, hints string) string {
+	var passwords, newPasswords, currentPasswords, emails, textareas, visible int
+	var username, confirm, card, search bool
+	for _, field := range f.Fields {
+		if field.Type == "hidden" {
+			continue
+		}
+		visible++
+		tokens := strings.Fields(field.Autocomplete)
+		switch field.Type {
+		case "password":
+			passwords++
+			if confirmFieldPattern.MatchString(field.Name) {
+				confirm = true
+			}
+		case "textarea":
+			textareas++
+		case "search":
+			search = true
+		}
+		for _, t := range tokens {
+			switch {
+			case t == "new-password":
+				newPasswords++
+			case t == "current-password":
+				currentPasswords++
+			case t == "username" || t == "email":
+				username = true
+			case strings.HasPrefix(t, "cc-"):
+				card = true
+			}
+		}
+		if field.Type != "password" && usernameFieldPattern.MatchString(field.Name) {
+			username = true
+		}
+		if field.Type == "email" || (field.Type == "text" && strings.Contains(strings.ToLower(field.Name), "email")) {
+			emails++
+		}
+		if cardFieldPattern.MatchString(field.Name) {
+			card = true
+		}
+		if (field.Type == "text" || field.Type == "search") && searchFieldPattern.MatchString(field.Name) {
+			search = true
+		}
+	}
+
+	switch {
+	case card:
+		return FormPayment
+	case passwords > 0:
+		switch {
+		case currentPasswords > 0 && newPasswords > 0,
+			passwords >= 2 && !username && passwordChangeWords.MatchString(hints),
+			passwords == 3:
+			return FormPasswordChange
+		case newPasswords > 0 || passwords >= 2 || confirm || signupWords.MatchString(hints):
+			return FormSignup
+		}
+		return FormLogin
+	case search && visible <= 2:
+		return FormSearch
+	case textareas > 0 && (emails > 0 || contactWords.MatchString(hints)):
+		return FormContact
+	case emails > 0 && visible <= 2 && textareas == 0:
+		// a lone email field is a newsletter sign-up, whether or not it says so
+		return FormNewsletter
+	case emails > 0 && newsletterWords.MatchString(hints):
+		return FormNewsletter
+	case strings.Contains(strings.ToLower(hints), "search") && visible == 1:
+		return FormSearch
+	}
+	return FormOther
+}
@@ -0,0 +1,143 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+)
+
+// crawlForms crawls body with the forms analyzer and returns the result and the forms found
+func crawlForms(t *testing.T, body string) (Result, []Form) {
+	t.Helper()
+	res := crawlResult(t, body, Options{Analyzers: []string{"forms"}})
+	forms, _ := res.Findings["forms"].([]Form)
+	return res, forms
+}
+
+func TestForms_Classification(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"login", `<form method="post" action="/session"><input type="email" name="email" autocomplete="username">
+			<input type="password" name="password" autocomplete="current-password"><button>Log in</button></form>`, FormLogin},
+		{"signup by autocomplete", `<form><input name="email" type="email">
+			<input type="password" name="password" autocomplete="new-password"><button>Continue</button></form>`, FormSignup},
+		{"signup with confirmation", `<form><input name="username"><input type="password" name="password">
+			<input type="password" name="password_confirm"></form>`, FormSignup},
+		{"signup by wording", `<form action="/register"><input name="user"><input type="password" name="pw"></form>`, FormSignup},
+		{"password change", `<form><input type="password" name="old" autocomplete="current-password">
+			<input type="password" name="new" autocomplete="new-password">
+			<input type="password" name="new2" autocomplete="new-password"></form>`, FormPasswordChange},
+		{"search", `<form role="search" action="/search"><input type="search" name="q"><button>Go</button></form>`, FormSearch},
+		{"search by field name", `<form action="/find"><input name="q"></form>`, FormSearch},
+		{"newsletter", `<form class="newsletter"><input type="email" name="email" placeholder="you@example.com">
+			<button>Subscribe</button></form>`, FormNewsletter},
+		{"payment", `<form method="post"><input name="name" autocomplete="cc-name"><input name="number" autocomplete="cc-number">
+			<input name="cvc"></form>`, FormPayment},
+		{"contact", `<form method="post"><input name="name"><input type="email" name="email">
+			<textarea name="message"></textarea><button>Send</button></form>`, FormContact},
+		{"other", `<form><select name="lang"><option>en</option></select><input type="checkbox" name="remember"></form>`, FormOther},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, forms := crawlForms(t, `<html><body>`+tc.body+`</body></html>`)
+			if len(forms) != 1 {
+				t.Fatalf("expected 1 form, got %#v", forms)
+			}
+			if forms[0].Type != tc.want {
+				t.Errorf("expected %s, got %s (%#v)", tc.want, forms[0].Type, forms[0])
+			}
+			if res.HasLoginForm != (tc.want == FormLogin) {
+				t.Errorf("HasLoginForm = %v for a %s form", res.HasLoginForm, tc.want)
+			}
+		})
+	}
+}
+
+func TestForms_Structure(t *testing.T) {
+	res, forms := crawlForms(t, `<html><body>
+		<form id="login" method="POST" action="/session?next=%2F">
+			<input type="hidden" name="authenticity_token" value="abc">
+			<input type="hidden" name="return_to" value="/">
+			<label>User <input name="login" required></label>
+			<input type="password" name="password">
+			<input type="submit" value="Sign in">
+		</form>
+		<button form="login" type="submit">Sign in with a passkey</button>
+	</body></html>`)
+
+	if len(forms) != 1 {
+		t.Fatalf("expected 1 form, got %#v", forms)
+	}
+	f := forms[0]
+	if f.Type != FormLogin || !res.HasLoginForm {
+		t.Errorf("expected a login form, got %s", f.Type)
+	}
+	if f.ID != "login" || f.Method != "POST" || f.Standalone {
+		t.Errorf("unexpected form attributes: %#v", f)
+	}
+	if !strings.HasPrefix(f.Action, "http://") || !strings.HasSuffix(f.Action, "/session?next=%2F") {
+		t.Errorf("expected a resolved action, got %q", f.Action)
+	}
+	if len(f.Fields) != 4 {
+		t.Fatalf("expected 4 fields, got %#v", f.Fields)
+	}
+	if f.Fields[2].Name != "login" || f.Fields[2].Type != "text" || !f.Fields[2].Required {
+		t.Errorf("unexpected field: %#v", f.Fields[2])
+	}
+	if len(f.CSRFFields) != 1 || f.CSRFFields[0] != "authenticity_token" {
+		t.Errorf("expected the CSRF token field, got %v", f.CSRFFields)
+	}
+	if f.Hidden["authenticity_token"] != "abc" || f.Hidden["return_to"] != "/" {
+		t.Errorf("expected the hidden field values, got %v", f.Hidden)
+	}
+	if len(f.SubmitButtons) != 2 || f.SubmitButtons[0] != "Sign in" || f.SubmitButtons[1] != "Sign in with a passkey" {
+		t.Errorf("unexpected submit buttons: %v", f.SubmitButtons)
+	}
+}
+
+func TestForms_StandaloneControls(t *testing.T) {
+	res, forms := crawlForms(t, `<html><body>
+		<div id="app"><input name="user"><input type="password" name="pass"><button>Log in</button></div>
+	</body></html>`)
+
+	if len(forms) != 1 {
+		t.Fatalf("expected 1 standalone form, got %#v", forms)
+	}
+	if !forms[0].Standalone || forms[0].Method != "GET" {
+		t.Errorf("expected a standalone form, got %#v", forms[0])
+	}
+	if forms[0].Type != FormLogin || !res.HasLoginForm {
+		t.Errorf("expected the controls to classify as a login form, got %s", forms[0].Type)
+	}
+}
+
+func TestForms_SignupIsNotLogin(t *testing.T) {
+	res, forms := crawlForms(t, `<html><body>
+		<form action="/signup"><input type="email" name="email"><input type="password" name="password" autocomplete="new-password"></form>
+		<form><input type="search" name="q"></form>
+	</body></html>`)
+
+	if res.HasLoginForm {
+		t.Errorf("a signup form must not count as a login form: %#v", forms)
+	}
+	if len(forms) != 2 || forms[0].Type != FormSignup || forms[1].Type != FormSearch {
+		t.Errorf("unexpected forms: %#v", forms)
+	}
+}
+
+func TestForms_NoForms(t *testing.T) {
+	res, forms := crawlForms(t, `<html><body><p>nothing to fill in</p><input type="hidden" name="x"></body></html>`)
+	if len(forms) != 0 || res.HasLoginForm {
+		t.Errorf("expected no forms, got %#v", forms)
+	}
+}
+
+func TestForms_NotSelected(t *testing.T) {
+	res := crawlResult(t, `<html><body><form><input name="user"><input type="password" name="pw"></form></body></html>`,
+		Options{Analyzers: []string{"title"}})
+	if _, ok := res.Findings["forms"]; ok || res.HasLoginForm {
+		t.Errorf("expected no form analysis when forms isn't selected, got %#v", res)
+	}
+}
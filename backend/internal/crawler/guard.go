@@ -0,0 +1,141 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// ErrBlockedAddress is returned when a crawl would connect to a non-public address
+var ErrBlockedAddress = errors.New("address is not allowed")
+
+// blockedPrefixes are ranges that aren't reachable on the public internet, beyond what the
+// netip predicates cover
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),         // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),     // carrier-grade NAT, includes Alibaba's metadata service
+	netip.MustParsePrefix("192.0.0.0/24"),      // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),      // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),     // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"),   // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),    // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),       // reserved, includes broadcast
+	netip.MustParsePrefix("168.63.129.16/32"),  // Azure host services
+	netip.MustParsePrefix("64:ff9b:1::/48"),    // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),     // documentation
+	netip.MustParsePrefix("fd00:ec2::254/128"), // AWS metadata over IPv6 (also in fc00::/7)
+}
+
+// nat64Prefix is the well-known NAT64 prefix
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// AddressGuard rejects connections to loopback, private, link-local (cloud metadata), multicast
+// and reserved addresses. It checks the address actually dialed, after DNS resolution, so
+// redirects and DNS rebinding can't get around it.
+type AddressGuard struct {
+	allowPrefixes []netip.Prefix
+	allowHosts    []string // exact host names, or ".suffix" for "*.suffix" entries
+}
+
+// NewAddressGuard builds a guard with an allowlist for intranet sites that are crawled on
+// purpose. Entries are IP addresses, CIDR ranges, host names, or "*.example.com" wildcards.
+// An allowlisted host name may resolve to any address.
+func NewAddressGuard(allowlist []string) (*AddressGuard, error) {
+	g := &AddressGuard{}
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if p, err := netip.ParsePrefix(entry); err == nil {
+			g.allowPrefixes = append(g.allowPrefixes, p.Masked())
+			continue
+		}
+		if a, err := netip.ParseAddr(entry); err == nil {
+			g.allowPrefixes = append(g.allowPrefixes, netip.PrefixFrom(a.Unmap(), a.Unmap().BitLen()))
+			continue
+		}
+		host := strings.TrimPrefix(entry, "*")
+		if strings.ContainsAny(host, "/:* ") || strings.Trim(host, ".") == "" {
+			return nil, fmt.Errorf("invalid allowlist entry %q", entry)
+		}
+		g.allowHosts = append(g.allowHosts, host)
+	}
+	return g, nil
+}
+
+// allowsHost reports whether a host name is allowlisted
+func (g *AddressGuard) allowsHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, h := range g.allowHosts {
+		if strings.HasPrefix(h, ".") {
+			if strings.HasSuffix(host, h) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an ErrBlockedAddress error if addr may not be connected to
+func (g *AddressGuard) Check(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, p := range g.allowPrefixes {
+		if p.Contains(addr) {
+			return nil
+		}
+	}
+	if isPublicAddr(addr) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not a public address", ErrBlockedAddress, addr)
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	// NAT64 addresses embed an IPv4 address that has to be public as well
+	if nat64Prefix.Contains(addr) {
+		b := addr.As16()
+		return isPublicAddr(netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}))
+	}
+	return true
+}
+
+// DialContext dials like d, rejecting non-public addresses unless the host is allowlisted
+func (g *AddressGuard) DialContext(d *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	guarded := *d
+	guarded.Control = func(network, address string, _ syscall.RawConn) error {
+		ap, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("%w: unexpected address %q", ErrBlockedAddress, address)
+		}
+		return g.Check(ap.Addr())
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err == nil && g.allowsHost(host) {
+			return d.DialContext(ctx, network, address)
+		}
+		conn, err := guarded.DialContext(ctx, network, address)
+		if err != nil && errors.Is(err, ErrBlockedAddress) {
+			// report the host the crawl asked for, not only the address it resolved to
+			return nil, fmt.Errorf("connecting to %s: %w", host, err)
+		}
+		return conn, err
+	}
+}
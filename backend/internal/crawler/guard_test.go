@@ -0,0 +1,106 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddressGuard_Check(t *testing.T) {
+	g, err := NewAddressGuard(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocked := []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.100.100.200", "0.0.0.0", "224.0.0.1", "255.255.255.255", "fe80::1", "fc00::1",
+		"fd00:ec2::254", "::ffff:127.0.0.1", "64:ff9b::a00:1", "168.63.129.16",
+	}
+	for _, s := range blocked {
+		if err := g.Check(netip.MustParseAddr(s)); !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("expected %s to be blocked, got %v", s, err)
+		}
+	}
+	for _, s := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c7:1946", "64:ff9b::5db8:d822"} {
+		if err := g.Check(netip.MustParseAddr(s)); err != nil {
+			t.Errorf("expected %s to be allowed, got %v", s, err)
+		}
+	}
+}
+
+func TestAddressGuard_Allowlist(t *testing.T) {
+	g, err := NewAddressGuard([]string{"10.20.0.0/16", "192.168.1.5", "wiki.corp.example", "*.intranet.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(netip.MustParseAddr("10.20.3.4")); err != nil {
+		t.Errorf("expected the allowlisted range to pass, got %v", err)
+	}
+	if err := g.Check(netip.MustParseAddr("192.168.1.5")); err != nil {
+		t.Errorf("expected the allowlisted address to pass, got %v", err)
+	}
+	if err := g.Check(netip.MustParseAddr("10.21.0.1")); err == nil {
+		t.Error("expected addresses outside the allowlist to stay blocked")
+	}
+	for host, want := range map[string]bool{
+		"wiki.corp.example": true, "WIKI.corp.example.": true, "other.corp.example": false,
+		"a.intranet.example": true, "intranet.example": false, "evilintranet.example": false,
+	} {
+		if got := g.allowsHost(host); got != want {
+			t.Errorf("allowsHost(%s) = %v, want %v", host, got, want)
+		}
+	}
+
+	if _, err := NewAddressGuard([]string{"http://x/"}); err == nil {
+		t.Error("expected an invalid entry to be rejected")
+	}
+}
+
+func TestCrawl_BlocksPrivateAddresses(t *testing.T) {
+	ts := serveHTML(t, `<html><title>internal</title></html>`)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := New(HTTPClient(5*time.Second)).Crawl(ctx, ts.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected the loopback server to be blocked, got %v", err)
+	}
+
+	// localhost resolves to a loopback address, which is what gets checked
+	localURL := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+	if _, err := New(HTTPClient(5*time.Second)).Crawl(ctx, localURL); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected localhost to be blocked, got %v", err)
+	}
+
+	g, _ := NewAddressGuard([]string{"127.0.0.0/8"})
+	res, err := New(HTTPClient(5*time.Second, WithAddressGuard(g))).Crawl(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("expected the allowlisted server to be crawled, got %v", err)
+	}
+	if res.Title == nil || *res.Title != "internal" {
+		t.Errorf("unexpected title %v", res.Title)
+	}
+}
+
+func TestCrawl_BlocksRedirectToPrivateAddress(t *testing.T) {
+	internal := serveHTML(t, `<html><title>secret</title></html>`)
+	front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusFound)
+	}))
+	defer front.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// only the front server is allowlisted, by host name; the redirect target is dialed by address
+	frontURL := strings.Replace(front.URL, "127.0.0.1", "localhost", 1)
+	g, _ := NewAddressGuard([]string{"localhost"})
+	_, err := New(HTTPClient(5*time.Second, WithAddressGuard(g))).Crawl(ctx, frontURL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected the redirect to a private address to be blocked, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Crawl outcomes
+const (
+	OutcomeOK           = "ok"
+	OutcomeTruncated    = "truncated"     // the body exceeded MaxBodyBytes; only the first part was parsed
+	OutcomeParseTimeout = "parse_timeout" // parsing and analysis exceeded MaxParseTime; only part of the page was analysed
+	OutcomeNotHTML      = "not_html"      // the response is a PDF, image, archive or other binary; nothing was parsed
+	OutcomeNotModified  = "not_modified"  // a conditional fetch found the page unchanged; nothing was parsed
+)
+
+// Limits bound how much of a response the crawler reads and how long it spends parsing it
+type Limits struct {
+	MaxBodyBytes int64
+	MaxParseTime time.Duration
+}
+
+// DefaultLimits are used until SetLimits is called
+func DefaultLimits() Limits {
+	return Limits{MaxBodyBytes: 10 << 20, MaxParseTime: 15 * time.Second}
+}
+
+// sniffBytes is how much of the body content sniffing looks at
+const sniffBytes = 512
+
+// binaryTypes are media types that are never HTML, whatever the body looks like
+var binaryTypes = map[string]bool{
+	"application/pdf":              true,
+	"application/zip":              true,
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-tar":            true,
+	"application/x-bzip2":          true,
+	"application/x-xz":             true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/vnd.rar":          true,
+	"application/java-archive":     true,
+	"application/wasm":             true,
+	"application/msword":           true,
+	"application/vnd.ms-excel":     true,
+	"application/x-msdownload":     true,
+}
+
+// binaryTypePrefixes are media type families that are never HTML
+var binaryTypePrefixes = []string{"image/", "audio/", "video/", "font/", "application/vnd.openxmlformats-"}
+
+// isBinaryType reports whether a Content-Type (declared or sniffed) is clearly not a web page
+func isBinaryType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	}
+	if binaryTypes[mediaType] {
+		return true
+	}
+	for _, prefix := range binaryTypePrefixes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readBody reads at most limit bytes of a response body. Binary content is recognised from
+// its first bytes and not read any further; the sniffed type is returned in that case.
+func readBody(r io.Reader, limit int64) (body []byte, truncated bool, binaryType string, err error) {
+	br := bufio.NewReaderSize(r, sniffBytes)
+	head, err := br.Peek(sniffBytes)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, false, "", err
+	}
+	if sniffed := http.DetectContentType(head); isBinaryType(sniffed) {
+		return nil, false, sniffed, nil
+	}
+	body, err = io.ReadAll(io.LimitReader(br, limit+1))
+	if err != nil {
+		return nil, false, "", err
+	}
+	if int64(len(body)) > limit {
+		return body[:limit], true, "", nil
+	}
+	return body, false, "", nil
+}
+
+// parseChunk is how much of the body deadlineReader hands out at a time
+const parseChunk = 4 << 10
+
+// deadlineReader hands out r in small chunks and reports EOF once the deadline has passed.
+// html.Parse reads as it builds the tree, so it stops soon after the deadline with the tree
+// built so far.
+type deadlineReader struct {
+	r        io.Reader
+	deadline time.Time // zero for no limit
+	cut      bool      // the deadline ended the input early
+}
+
+func (d *deadlineReader) Read(p []byte) (int, error) {
+	if !d.deadline.IsZero() && time.Now().After(d.deadline) {
+		d.cut = true
+		return 0, io.EOF
+	}
+	return d.r.Read(p[:min(len(p), parseChunk)])
+}
+
+// tokenizeWithin tokenizes body until it ends or the deadline passes. It returns the tokens and
+// how many bytes of body they cover, which is less than len(body) when the deadline cut it short.
+func tokenizeWithin(body []byte, timeout time.Duration) ([]html.Token, int, bool) {
+	deadline := time.Now().Add(timeout)
+	z := html.NewTokenizer(bytes.NewReader(body))
+	tokens := make([]html.Token, 0, 256)
+	consumed := 0
+	for {
+		if z.Next() == html.ErrorToken {
+			return tokens, len(body), true
+		}
+		consumed += len(z.Raw())
+		tokens = append(tokens, z.Token())
+		// checking the clock on every token would dominate small pages
+		if timeout > 0 && len(tokens)%1024 == 0 && time.Now().After(deadline) {
+			return tokens, consumed, false
+		}
+	}
+}
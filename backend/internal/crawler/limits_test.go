@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func crawlWithLimits(t *testing.T, handler http.HandlerFunc, limits Limits) Result {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	c.SetLimits(limits)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: pageAnalyzers})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	return res
+}
+
+func TestLimits_TruncatesLargeBody(t *testing.T) {
+	res := crawlWithLimits(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>big</title></head><body>`))
+		_, _ = w.Write(bytes.Repeat([]byte("<p>filler</p>"), 10000))
+	}, Limits{MaxBodyBytes: 4096})
+
+	if res.Outcome != OutcomeTruncated || !res.Truncated || res.BodyBytes != 4096 {
+		t.Errorf("expected a truncated result of 4096 bytes, got %s / %v / %d", res.Outcome, res.Truncated, res.BodyBytes)
+	}
+	if res.Title == nil || *res.Title != "big" {
+		t.Errorf("expected the first part to be parsed, got title %v", res.Title)
+	}
+}
+
+func TestLimits_SkipsBinaryContentType(t *testing.T) {
+	res := crawlWithLimits(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7 <title>not a title</title>"))
+	}, Limits{})
+
+	if res.Outcome != OutcomeNotHTML || res.ContentType != "application/pdf" {
+		t.Errorf("expected not_html for a PDF, got %s / %s", res.Outcome, res.ContentType)
+	}
+	if res.Title != nil || res.BodyBytes != 0 {
+		t.Errorf("expected nothing to be parsed, got title %v and %d bytes", res.Title, res.BodyBytes)
+	}
+}
+
+func TestLimits_SniffsMislabelledBinary(t *testing.T) {
+	res := crawlWithLimits(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write(append([]byte("PK\x03\x04"), bytes.Repeat([]byte{0}, 2048)...))
+	}, Limits{})
+
+	if res.Outcome != OutcomeNotHTML || res.ContentType != "application/zip" {
+		t.Errorf("expected a zip served as text/html to be skipped, got %s / %s", res.Outcome, res.ContentType)
+	}
+}
+
+func TestLimits_ParseTimeout(t *testing.T) {
+	body := []byte(strings.Repeat("<div><span>x</span></div>", 200000))
+	tokens, n, complete := tokenizeWithin(body, time.Nanosecond)
+	if complete || n >= len(body) || len(tokens) == 0 {
+		t.Errorf("expected tokenizing to stop early, got complete=%v after %d of %d bytes", complete, n, len(body))
+	}
+
+	tokens, n, complete = tokenizeWithin(body[:1000], time.Minute)
+	if !complete || n != 1000 || len(tokens) == 0 {
+		t.Errorf("expected a small body to be tokenized completely, got complete=%v, %d bytes", complete, n)
+	}
+}
+
+// waitingAnalyzer stands in for a network-bound analyzer that runs until its context ends
+type waitingAnalyzer struct{}
+
+func (waitingAnalyzer) Name() string { return "waiting" }
+
+func (waitingAnalyzer) Analyze(ctx context.Context, _ *Page) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestLimits_ParseTimeoutBoundsAnalyzers(t *testing.T) {
+	ts := serveHTML(t, `<!doctype html><title>slow</title>`)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	c.SetLimits(Limits{MaxParseTime: 50 * time.Millisecond})
+	c.Analyzers().Register(waitingAnalyzer{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"waiting", "title"}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Outcome != OutcomeParseTimeout {
+		t.Errorf("expected %s, got %s", OutcomeParseTimeout, res.Outcome)
+	}
+	if _, ok := res.Findings["title"].(map[string]string); !ok || res.Title != nil {
+		t.Errorf("expected the title analyzer to be skipped, got %#v", res.Findings["title"])
+	}
+}
+
+func TestDeadlineReader_CutsTreeShort(t *testing.T) {
+	body := []byte("<html><body>" + strings.Repeat("<p>x</p>", 10000))
+	page := &Page{Body: body, deadline: time.Now().Add(-time.Second)}
+	if doc := page.Document(); doc == nil || !page.docCut {
+		t.Fatalf("expected the tree to be cut short")
+	}
+
+	page = &Page{Body: body}
+	paragraphs := 0
+	walkElements(page.Document(), func(n *html.Node) bool {
+		if n.Data == "p" {
+			paragraphs++
+		}
+		return true
+	})
+	if page.docCut || paragraphs != 10000 {
+		t.Errorf("expected the whole tree without a deadline, got %d paragraphs", paragraphs)
+	}
+}
+
+func TestLimits_HTMLIsOK(t *testing.T) {
+	res := crawlWithLimits(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<!doctype html><title>ok</title>`))
+	}, Limits{})
+
+	if res.Outcome != OutcomeOK || res.Truncated || res.BodyBytes != 32 {
+		t.Errorf("unexpected outcome %s / %v / %d", res.Outcome, res.Truncated, res.BodyBytes)
+	}
+}
@@ -0,0 +1,200 @@
+package crawler
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxLinkCacheEntries bounds the in-memory cache; expired entries are dropped first
+const maxLinkCacheEntries = 100_000
+
+// LinkStore persists link check outcomes so they survive restarts and are shared between
+// servers. class is "" for a link that was fine.
+type LinkStore interface {
+	LoadLink(ctx context.Context, key string) (class string, checkedAt time.Time, found bool, err error)
+	SaveLink(ctx context.Context, key, class string, checkedAt time.Time) error
+}
+
+// LinkCacheStats counts how link checks were answered
+type LinkCacheStats struct {
+	Hits         int64 `json:"hits"`         // answered from memory
+	StoreHits    int64 `json:"store_hits"`   // answered from the store
+	Misses       int64 `json:"misses"`       // checked over the network
+	Deduplicated int64 `json:"deduplicated"` // waited for the same check already running
+	StoreErrors  int64 `json:"store_errors"`
+	Entries      int   `json:"entries"`
+}
+
+type linkEntry struct {
+	class   string
+	expires time.Time
+}
+
+// linkCall is a check in flight; callers asking for the same link wait for it
+type linkCall struct {
+	done  chan struct{}
+	class string
+	ok    bool // false when the check was cut short and its outcome says nothing
+}
+
+// LinkCache remembers link check outcomes for a while so links shared by many pages, such as
+// navigation and footers, are checked once. Concurrent checks of the same link are folded into
+// one. It is safe for concurrent use.
+type LinkCache struct {
+	ttl   time.Duration
+	store LinkStore // optional
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]linkEntry
+	calls   map[string]*linkCall
+
+	hits, storeHits, misses, deduplicated, storeErrors atomic.Int64
+}
+
+// NewLinkCache returns a cache keeping outcomes for ttl, backed by store if it isn't nil
+func NewLinkCache(ttl time.Duration, store LinkStore) *LinkCache {
+	return &LinkCache{
+		ttl:     ttl,
+		store:   store,
+		now:     time.Now,
+		entries: make(map[string]linkEntry),
+		calls:   make(map[string]*linkCall),
+	}
+}
+
+// Stats reports how checks were answered since the cache was created
+func (lc *LinkCache) Stats() LinkCacheStats {
+	lc.mu.Lock()
+	entries := len(lc.entries)
+	lc.mu.Unlock()
+	return LinkCacheStats{
+		Hits:         lc.hits.Load(),
+		StoreHits:    lc.storeHits.Load(),
+		Misses:       lc.misses.Load(),
+		Deduplicated: lc.deduplicated.Load(),
+		StoreErrors:  lc.storeErrors.Load(),
+		Entries:      entries,
+	}
+}
+
+// check returns the outcome for key, calling fn when it isn't known yet. fn reports false
+// when its outcome shouldn't be kept. A nil cache calls fn every time.
+func (lc *LinkCache) check(ctx context.Context, key string, fn func() (string, bool)) string {
+	if lc == nil || key == "" {
+		class, _ := fn()
+		return class
+	}
+
+	lc.mu.Lock()
+	if e, ok := lc.entries[key]; ok && lc.now().Before(e.expires) {
+		lc.mu.Unlock()
+		lc.hits.Add(1)
+		return e.class
+	}
+	if call, ok := lc.calls[key]; ok {
+		lc.mu.Unlock()
+		lc.deduplicated.Add(1)
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return ""
+		}
+		if call.ok {
+			return call.class
+		}
+		class, _ := fn()
+		return class
+	}
+	call := &linkCall{done: make(chan struct{})}
+	lc.calls[key] = call
+	lc.mu.Unlock()
+
+	fromStore := false
+	checkedAt := lc.now()
+	if lc.store != nil {
+		class, at, found, err := lc.store.LoadLink(ctx, key)
+		if err != nil {
+			lc.storeErrors.Add(1)
+			logrus.WithError(err).Warn("Failed to load link check")
+		} else if found && lc.now().Before(at.Add(lc.ttl)) {
+			call.class, call.ok, checkedAt, fromStore = class, true, at, true
+			lc.storeHits.Add(1)
+		}
+	}
+	if !fromStore {
+		lc.misses.Add(1)
+		call.class, call.ok = fn()
+	}
+
+	lc.mu.Lock()
+	if call.ok {
+		if len(lc.entries) >= maxLinkCacheEntries {
+			lc.evict()
+		}
+		lc.entries[key] = linkEntry{class: call.class, expires: checkedAt.Add(lc.ttl)}
+	}
+	delete(lc.calls, key)
+	lc.mu.Unlock()
+	close(call.done)
+
+	if call.ok && !fromStore && lc.store != nil {
+		if err := lc.store.SaveLink(ctx, key, call.class, checkedAt); err != nil {
+			lc.storeErrors.Add(1)
+			logrus.WithError(err).Warn("Failed to save link check")
+		}
+	}
+	return call.class
+}
+
+// evict drops expired entries, or half of the cache if none have expired. Callers hold mu.
+func (lc *LinkCache) evict() {
+	now := lc.now()
+	for key, e := range lc.entries {
+		if !now.Before(e.expires) {
+			delete(lc.entries, key)
+		}
+	}
+	for key := range lc.entries {
+		if len(lc.entries) < maxLinkCacheEntries/2 {
+			break
+		}
+		delete(lc.entries, key)
+	}
+}
+
+// linkKey normalises an absolute http(s) URL into a cache key: scheme and host lowercased,
+// default ports and fragments dropped and an empty path made "/". It returns "" for anything
+// else.
+func linkKey(u *url.URL) string {
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host = strings.ToLower(u.Host)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	key := u.Scheme + "://" + host + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// SetLinkCache makes link checks go through cache; nil turns caching off
+func (c *Crawler) SetLinkCache(cache *LinkCache) { c.links = cache }
+
+// LinkCache returns the crawler's link cache, or nil if it has none
+func (c *Crawler) LinkCache() *LinkCache { return c.links }
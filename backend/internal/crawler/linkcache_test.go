@@ -0,0 +1,186 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLinkKey(t *testing.T) {
+	for raw, want := range map[string]string{
+		"HTTP://Example.COM":             "http://example.com/",
+		"https://example.com:443/a#frag": "https://example.com/a",
+		"http://example.com:8080/a?b=1":  "http://example.com:8080/a?b=1",
+		"http://[::1]:80/":               "http://[::1]/",
+		"mailto:someone@example.com":     "",
+	} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := linkKey(u); got != want {
+			t.Errorf("linkKey(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestCrawl_LinkCacheSharedAcrossCrawls(t *testing.T) {
+	var checks atomic.Int64
+	mux := http.NewServeMux()
+	var ts *httptest.Server
+	page := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><body><a href="%[1]s/footer">f</a><a href="%[1]s/gone">g</a></body></html>`, ts.URL)
+	}
+	mux.HandleFunc("/one", page)
+	mux.HandleFunc("/two", page)
+	mux.HandleFunc("/footer", func(w http.ResponseWriter, r *http.Request) { checks.Add(1) })
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		checks.Add(1)
+		http.NotFound(w, r)
+	})
+	ts = httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	c.SetLinkCache(NewLinkCache(time.Hour, nil))
+	for _, path := range []string{"/one", "/two"} {
+		res, err := c.Crawl(context.Background(), ts.URL+path)
+		if err != nil {
+			t.Fatalf("crawl error: %v", err)
+		}
+		if res.InaccessibleLinks != 1 {
+			t.Errorf("expected the cached 404 to still count, got %d", res.InaccessibleLinks)
+		}
+	}
+	if n := checks.Load(); n != 2 {
+		t.Errorf("expected each link to be checked once, got %d checks", n)
+	}
+	if st := c.LinkCache().Stats(); st.Misses != 2 || st.Hits != 2 || st.Entries != 2 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+
+	// links checked with credentials skip the cache
+	_, err := c.CrawlWithOptions(context.Background(), ts.URL+"/one", Options{
+		Profile: &RequestProfile{BearerToken: "t", ApplyToLinks: true},
+	})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if n := checks.Load(); n != 4 {
+		t.Errorf("expected an authenticated crawl to check its links again, got %d checks", n)
+	}
+}
+
+func TestLinkCache_SingleFlight(t *testing.T) {
+	lc := NewLinkCache(time.Hour, nil)
+	release := make(chan struct{})
+	var calls atomic.Int64
+	fn := func() (string, bool) {
+		calls.Add(1)
+		<-release
+		return ErrorClassDNS, true
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = lc.check(context.Background(), "http://a.example/", fn)
+		}()
+	}
+	// let every caller join the first check before it finishes
+	for lc.Stats().Deduplicated < 4 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected one check, got %d", n)
+	}
+	for _, class := range results {
+		if class != ErrorClassDNS {
+			t.Errorf("expected every caller to get the shared outcome, got %v", results)
+		}
+	}
+}
+
+func TestLinkCache_ExpiryAndCutShortChecks(t *testing.T) {
+	lc := NewLinkCache(time.Minute, nil)
+	now := time.Now()
+	lc.now = func() time.Time { return now }
+	var calls int
+	ok := func() (string, bool) { calls++; return "", true }
+
+	lc.check(context.Background(), "k", ok)
+	lc.check(context.Background(), "k", ok)
+	if calls != 1 {
+		t.Fatalf("expected the second check to be cached, got %d calls", calls)
+	}
+	now = now.Add(2 * time.Minute)
+	lc.check(context.Background(), "k", ok)
+	if calls != 2 {
+		t.Errorf("expected an expired entry to be checked again, got %d calls", calls)
+	}
+
+	cut := func() (string, bool) { calls++; return ErrorClassTimeout, false }
+	lc.check(context.Background(), "cut", cut)
+	lc.check(context.Background(), "cut", cut)
+	if calls != 4 {
+		t.Errorf("expected a cut-short check not to be kept, got %d calls", calls)
+	}
+}
+
+type memLinkStore struct {
+	mu    sync.Mutex
+	rows  map[string]string
+	at    map[string]time.Time
+	saves int
+}
+
+func (s *memLinkStore) LoadLink(_ context.Context, key string) (string, time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	class, ok := s.rows[key]
+	return class, s.at[key], ok, nil
+}
+
+func (s *memLinkStore) SaveLink(_ context.Context, key, class string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows[key], s.at[key] = class, at
+	s.saves++
+	return nil
+}
+
+func TestLinkCache_Store(t *testing.T) {
+	store := &memLinkStore{rows: map[string]string{}, at: map[string]time.Time{}}
+	store.rows["stored"], store.at["stored"] = ErrorClassTLS, time.Now()
+	store.rows["stale"], store.at["stale"] = ErrorClassTLS, time.Now().Add(-2*time.Hour)
+
+	lc := NewLinkCache(time.Hour, store)
+	var calls int
+	fn := func() (string, bool) { calls++; return "", true }
+
+	if class := lc.check(context.Background(), "stored", fn); class != ErrorClassTLS || calls != 0 {
+		t.Errorf("expected the stored outcome, got %q after %d checks", class, calls)
+	}
+	if class := lc.check(context.Background(), "stale", fn); class != "" || calls != 1 {
+		t.Errorf("expected a stale stored outcome to be checked again, got %q after %d checks", class, calls)
+	}
+	if store.saves != 1 || store.rows["stale"] != "" {
+		t.Errorf("expected the fresh outcome to be saved, got %d saves", store.saves)
+	}
+	if st := lc.Stats(); st.StoreHits != 1 || st.Misses != 1 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}
@@ -0,0 +1,368 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrLoginFailed is returned when a scripted login doesn't pass its success check
+var ErrLoginFailed = errors.New("login failed")
+
+// LoginRecipe describes how to log in to a site before crawling it
+type LoginRecipe struct {
+	// LoginURL is the page holding the login form. The form is submitted to its action; hidden
+	// fields such as CSRF tokens are sent along with Fields.
+	LoginURL string
+	// Fields are the values to submit, e.g. the username and password
+	Fields map[string]string
+	// SuccessCookie, when set, is a cookie the site sets once logged in
+	SuccessCookie string
+	// SuccessURL, when set, is where a successful login redirects to: an absolute URL or a path.
+	// Without either check, a login succeeds unless it lands back on the login page.
+	SuccessURL string
+}
+
+// ValidateLoginRecipe checks that a recipe can be executed
+func ValidateLoginRecipe(r LoginRecipe) error {
+	u, err := url.Parse(r.LoginURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid login URL %q", r.LoginURL)
+	}
+	if len(r.Fields) == 0 {
+		return errors.New("login recipe needs at least one field")
+	}
+	for name := range r.Fields {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("login field names must not be empty")
+		}
+	}
+	if r.SuccessURL != "" {
+		if _, err := url.Parse(r.SuccessURL); err != nil {
+			return fmt.Errorf("invalid success URL %q", r.SuccessURL)
+		}
+	}
+	return nil
+}
+
+// maxLogins bounds how often one crawl logs in, so a link that ends the session (a logout
+// link, say) can't make it log in over and over
+const maxLogins = 3
+
+// loginSessionTTL is how long later crawls with the same recipe reuse the cookies of a login.
+// A session the site ends sooner is noticed by the bounce check and logged in again.
+const loginSessionTTL = 10 * time.Minute
+
+// sessionCache keeps the cookie jars of recent logins, so crawls of the same site don't each
+// log in afresh. It is safe for concurrent use.
+type sessionCache struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	jars map[string]cachedJar
+}
+
+type cachedJar struct {
+	jar     http.CookieJar
+	expires time.Time
+}
+
+func newSessionCache(ttl time.Duration) *sessionCache {
+	return &sessionCache{ttl: ttl, jars: make(map[string]cachedJar)}
+}
+
+// open returns a logged-in session for recipe: one reusing the cookies of a recent login with
+// the same recipe, profile and proxy, or a fresh one that logs in first
+func (sc *sessionCache) open(ctx context.Context, client Fetcher, recipe *LoginRecipe, profile *RequestProfile, proxy *url.URL, maxBody int64) (*session, error) {
+	s, err := newSession(client, recipe, profile, maxBody)
+	if err != nil {
+		return nil, err
+	}
+	key := sessionKey(recipe, profile, proxy)
+	now := time.Now()
+
+	sc.mu.Lock()
+	cached, ok := sc.jars[key]
+	sc.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		s.client.Jar = cached.jar
+		return s, nil
+	}
+
+	if err := s.login(ctx); err != nil {
+		return nil, err
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for k, c := range sc.jars {
+		if !now.Before(c.expires) {
+			delete(sc.jars, k)
+		}
+	}
+	sc.jars[key] = cachedJar{jar: s.client.Jar, expires: now.Add(sc.ttl)}
+	return s, nil
+}
+
+// sessionKey identifies what a login depends on: the recipe, the profile its requests carry
+// and the proxy they go through
+func sessionKey(recipe *LoginRecipe, profile *RequestProfile, proxy *url.URL) string {
+	var proxyURL string
+	if proxy != nil {
+		proxyURL = proxy.String()
+	}
+	b, _ := json.Marshal(struct {
+		Recipe  *LoginRecipe
+		Profile *RequestProfile
+		Proxy   string
+	}{recipe, profile, proxyURL})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// session is a Fetcher that keeps the cookies of a scripted login. When a request bounces
+// back to the login page, it logs in again and retries the request once.
+type session struct {
+	client   *http.Client
+	recipe   *LoginRecipe
+	profile  *RequestProfile
+	loginURL *url.URL
+	maxBody  int64
+
+	mu     sync.Mutex
+	logins int
+}
+
+func newSession(client Fetcher, recipe *LoginRecipe, profile *RequestProfile, maxBody int64) (*session, error) {
+	hc, ok := client.(*http.Client)
+	if !ok {
+		return nil, fmt.Errorf("scripted login needs an *http.Client, got %T", client)
+	}
+	if err := ValidateLoginRecipe(*recipe); err != nil {
+		return nil, err
+	}
+	loginURL, _ := url.Parse(recipe.LoginURL)
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	withJar := *hc
+	withJar.Jar = jar
+	return &session{client: &withJar, recipe: recipe, profile: profile, loginURL: loginURL, maxBody: maxBody}, nil
+}
+
+// Do sends req with the session's cookies, logging in again if the site sent it to the login page
+func (s *session) Do(req *http.Request) (*http.Response, error) {
+	logins := s.loginCount()
+	// the client adds the jar's cookies to req itself; the retry must not resend the stale ones
+	header := req.Header.Clone()
+	resp, err := s.client.Do(req)
+	if err != nil || !s.bounced(req, resp) {
+		return resp, err
+	}
+	drain(resp)
+	logrus.Infof("Request for %s was sent to the login page; logging in again", req.URL)
+	if err := s.relogin(req.Context(), logins); err != nil {
+		return nil, err
+	}
+	retry := req.Clone(req.Context())
+	retry.Header = header
+	resp, err = s.client.Do(retry)
+	if err != nil {
+		return nil, err
+	}
+	if s.bounced(retry, resp) {
+		drain(resp)
+		return nil, fmt.Errorf("%w: %s still redirects to the login page", ErrLoginFailed, req.URL)
+	}
+	return resp, nil
+}
+
+func (s *session) loginCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// relogin logs in unless another request already did since seen logins were counted
+func (s *session) relogin(ctx context.Context, seen int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.logins != seen {
+		return nil
+	}
+	return s.loginLocked(ctx)
+}
+
+// login logs in for the first time
+func (s *session) login(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loginLocked(ctx)
+}
+
+func (s *session) loginLocked(ctx context.Context) error {
+	if s.logins >= maxLogins {
+		return fmt.Errorf("%w: logged in %d times already", ErrLoginFailed, s.logins)
+	}
+	s.logins++
+	if err := s.submitLogin(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrLoginFailed, err)
+	}
+	logrus.Infof("Logged in at %s", s.loginURL)
+	return nil
+}
+
+// submitLogin fetches the login page, fills in its form and submits it
+func (s *session) submitLogin(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.loginURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	s.decorate(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching the login page: %w", err)
+	}
+	body, truncated, _, err := readBody(resp.Body, s.maxBody)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("reading the login page: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("login page returned HTTP %d", resp.StatusCode)
+	}
+	body, _ = decodeBody(body, resp.Header.Get("Content-Type"), truncated)
+	formURL := resp.Request.URL
+	page, _ := newPage(formURL, resp, body, time.Time{})
+
+	// without a form, the fields are posted to the login page itself
+	action, method, values := formURL, http.MethodPost, url.Values{}
+	if form := loginForm(analyzeForms(page), s.recipe.Fields); form != nil {
+		if u, err := url.Parse(form.Action); err == nil && form.Action != "" {
+			action = u
+		}
+		method = form.Method
+		for name, value := range form.Hidden {
+			values.Set(name, value)
+		}
+	}
+	for name, value := range s.recipe.Fields {
+		values.Set(name, value)
+	}
+	var submit *http.Request
+	if method == http.MethodGet {
+		target := *action
+		target.RawQuery = values.Encode()
+		submit, err = http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	} else {
+		submit, err = http.NewRequestWithContext(ctx, http.MethodPost, action.String(), strings.NewReader(values.Encode()))
+		if err == nil {
+			submit.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	s.decorate(submit)
+	submit.Header.Set("Referer", formURL.String())
+	submit.Header.Set("Origin", formURL.Scheme+"://"+formURL.Host)
+	resp, err = s.client.Do(submit)
+	if err != nil {
+		return fmt.Errorf("submitting the login form: %w", err)
+	}
+	drain(resp)
+	landed := resp.Request.URL
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("login form returned HTTP %d", resp.StatusCode)
+	}
+
+	if s.recipe.SuccessCookie != "" && !s.hasCookie(s.recipe.SuccessCookie, landed) {
+		return fmt.Errorf("cookie %q was not set", s.recipe.SuccessCookie)
+	}
+	if s.recipe.SuccessURL != "" && !matchesURL(landed, s.recipe.SuccessURL) {
+		return fmt.Errorf("landed on %s instead of %s", landed, s.recipe.SuccessURL)
+	}
+	if s.recipe.SuccessCookie == "" && s.recipe.SuccessURL == "" && s.isLoginPage(landed) {
+		return errors.New("the site sent us back to the login page")
+	}
+	return nil
+}
+
+// decorate sets the default headers and the request profile on login requests
+func (s *session) decorate(req *http.Request) {
+	req.Header.Set("User-Agent", defaultUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	s.profile.apply(req)
+}
+
+func (s *session) hasCookie(name string, urls ...*url.URL) bool {
+	for _, u := range append(urls, s.loginURL) {
+		for _, c := range s.client.Jar.Cookies(u) {
+			if c.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isLoginPage reports whether u is the login page, ignoring the query (which often carries
+// a return URL)
+func (s *session) isLoginPage(u *url.URL) bool {
+	return strings.EqualFold(u.Host, s.loginURL.Host) &&
+		strings.TrimSuffix(u.Path, "/") == strings.TrimSuffix(s.loginURL.Path, "/")
+}
+
+// bounced reports whether a request for another page ended up on the login page
+func (s *session) bounced(req *http.Request, resp *http.Response) bool {
+	if resp.Request == nil || s.isLoginPage(req.URL) {
+		return false
+	}
+	return s.isLoginPage(resp.Request.URL)
+}
+
+// matchesURL compares where a login landed with the expected URL or path
+func matchesURL(landed *url.URL, want string) bool {
+	w, err := url.Parse(want)
+	if err != nil {
+		return false
+	}
+	if w.Host != "" && !strings.EqualFold(w.Host, landed.Host) {
+		return false
+	}
+	return strings.TrimSuffix(w.Path, "/") == strings.TrimSuffix(landed.Path, "/")
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// loginForm picks the form holding the recipe's fields, or else the first form with a
+// password field; nil when the page has neither
+func loginForm(forms []Form, fields map[string]string) *Form {
+	for _, match := range []func(FormField) bool{
+		func(f FormField) bool { _, ok := fields[f.Name]; return ok },
+		func(f FormField) bool { return f.Type == "password" },
+	} {
+		for i := range forms {
+			if !forms[i].Standalone && slices.ContainsFunc(forms[i].Fields, match) {
+				return &forms[i]
+			}
+		}
+	}
+	return nil
+}
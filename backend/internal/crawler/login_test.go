@@ -0,0 +1,217 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loginSite is a site whose pages need a session from its login form. Each session is good for
+// usesPerSession page requests (0 for unlimited).
+type loginSite struct {
+	usesPerSession int
+
+	mu       sync.Mutex
+	logins   int
+	sessions map[string]int // session id to requests served
+}
+
+func (s *loginSite) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Sign in</title></head><body>
+			<form action="/search"><input name="q"></form>
+			<form method="post" action="/session">
+				<input type="hidden" name="csrf" value="tok123">
+				<input name="user"><input type="password" name="pass">
+			</form></body></html>`)
+	})
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.PostFormValue("csrf") != "tok123" ||
+			r.PostFormValue("user") != "qa" || r.PostFormValue("pass") != "s3cret" {
+			http.Redirect(w, r, "/login?error=1", http.StatusSeeOther)
+			return
+		}
+		s.mu.Lock()
+		s.logins++
+		id := fmt.Sprintf("sid-%d", s.logins)
+		s.sessions[id] = 0
+		s.mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: id, Path: "/"})
+		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorize(r) {
+			http.Redirect(w, r, "/login?next="+r.URL.Path, http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/private":
+			fmt.Fprint(w, `<html><head><title>Private</title></head><body><a href="/other">other</a></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><head><title>Members</title></head></html>`)
+		}
+	})
+	return mux
+}
+
+// authorize counts a use of the request's session, unless the session is used up
+func (s *loginSite) authorize(r *http.Request) bool {
+	c, err := r.Cookie("sid")
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	used, ok := s.sessions[c.Value]
+	if !ok || (s.usesPerSession > 0 && used >= s.usesPerSession) {
+		return false
+	}
+	s.sessions[c.Value] = used + 1
+	return true
+}
+
+func (s *loginSite) loginCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+func crawlWithLogin(t *testing.T, site *loginSite, recipe func(base string) LoginRecipe) (Result, error) {
+	t.Helper()
+	site.sessions = map[string]int{}
+	ts := httptest.NewServer(site.handler())
+	t.Cleanup(ts.Close)
+	r := recipe(ts.URL)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.CrawlWithOptions(ctx, ts.URL+"/private", Options{Analyzers: pageAnalyzers, Login: &r})
+}
+
+func validRecipe(base string) LoginRecipe {
+	return LoginRecipe{
+		LoginURL:      base + "/login",
+		Fields:        map[string]string{"user": "qa", "pass": "s3cret"},
+		SuccessCookie: "sid",
+		SuccessURL:    "/dashboard",
+	}
+}
+
+func TestLogin_CrawlsProtectedPage(t *testing.T) {
+	site := &loginSite{}
+	res, err := crawlWithLogin(t, site, validRecipe)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Title == nil || *res.Title != "Private" {
+		t.Errorf("expected the protected page, got title %v", res.Title)
+	}
+	if res.InternalLinks != 1 || res.InaccessibleLinks != 0 {
+		t.Errorf("expected one accessible internal link, got %d internal, %d inaccessible", res.InternalLinks, res.InaccessibleLinks)
+	}
+	if n := site.loginCount(); n != 1 {
+		t.Errorf("expected one login, got %d", n)
+	}
+}
+
+func TestLogin_ReauthenticatesAfterBounce(t *testing.T) {
+	// the landing page and the protected page use up the session, so the link check bounces
+	site := &loginSite{usesPerSession: 2}
+	res, err := crawlWithLogin(t, site, validRecipe)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Title == nil || *res.Title != "Private" {
+		t.Errorf("expected the protected page, got title %v", res.Title)
+	}
+	if n := site.loginCount(); n != 2 {
+		t.Errorf("expected a second login after the bounce, got %d logins", n)
+	}
+}
+
+func TestLogin_ReusesSessionAcrossCrawls(t *testing.T) {
+	site := &loginSite{sessions: map[string]int{}}
+	ts := httptest.NewServer(site.handler())
+	t.Cleanup(ts.Close)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	recipe := validRecipe(ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		res, err := c.CrawlWithOptions(ctx, ts.URL+"/private", Options{Analyzers: []string{"title"}, Login: &recipe})
+		if err != nil {
+			t.Fatalf("crawl %d: %v", i, err)
+		}
+		if res.Title == nil || *res.Title != "Private" {
+			t.Errorf("crawl %d: expected the protected page, got title %v", i, res.Title)
+		}
+	}
+	if n := site.loginCount(); n != 1 {
+		t.Errorf("expected the second crawl to reuse the session, got %d logins", n)
+	}
+
+	// another recipe logs in on its own
+	other := validRecipe(ts.URL)
+	other.SuccessURL = ""
+	if _, err := c.CrawlWithOptions(ctx, ts.URL+"/private", Options{Analyzers: []string{"title"}, Login: &other}); err != nil {
+		t.Fatalf("crawl with another recipe: %v", err)
+	}
+	if n := site.loginCount(); n != 2 {
+		t.Errorf("expected another recipe to log in again, got %d logins", n)
+	}
+}
+
+func TestLogin_Failures(t *testing.T) {
+	cases := map[string]func(base string) LoginRecipe{
+		"wrong password": func(base string) LoginRecipe {
+			r := validRecipe(base)
+			r.Fields = map[string]string{"user": "qa", "pass": "nope"}
+			r.SuccessCookie, r.SuccessURL = "", ""
+			return r
+		},
+		"missing success cookie": func(base string) LoginRecipe {
+			r := validRecipe(base)
+			r.SuccessCookie = "remember_me"
+			return r
+		},
+		"wrong landing page": func(base string) LoginRecipe {
+			r := validRecipe(base)
+			r.SuccessURL = base + "/welcome"
+			return r
+		},
+	}
+	for name, recipe := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := crawlWithLogin(t, &loginSite{}, recipe)
+			if !errors.Is(err, ErrLoginFailed) {
+				t.Errorf("expected ErrLoginFailed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateLoginRecipe(t *testing.T) {
+	bad := []LoginRecipe{
+		{LoginURL: "ftp://example.com/login", Fields: map[string]string{"u": "x"}},
+		{LoginURL: "/login", Fields: map[string]string{"u": "x"}},
+		{LoginURL: "https://example.com/login"},
+		{LoginURL: "https://example.com/login", Fields: map[string]string{" ": "x"}},
+	}
+	for _, r := range bad {
+		if err := ValidateLoginRecipe(r); err == nil {
+			t.Errorf("expected %+v to be rejected", r)
+		}
+	}
+	if err := ValidateLoginRecipe(validRecipe("https://example.com")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
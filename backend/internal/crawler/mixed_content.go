@@ -0,0 +1,91 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Mixed content categories
+const (
+	MixedActive           = "active"
+	MixedPassive          = "passive"
+	MixedProtocolRelative = "protocol_relative"
+	MixedInsecureForm     = "insecure_form"
+)
+
+// MixedContentItem is one insecure reference on an HTTPS page
+type MixedContentItem struct {
+	Category  string `json:"category"`
+	Element   string `json:"element"`
+	Attribute string `json:"attribute"`
+	Kind      string `json:"kind,omitempty"` // resource kind; empty for forms
+	URL       string `json:"url"`
+}
+
+type MixedContentFindings struct {
+	// HTTPS is false for pages served over plain HTTP, which aren't checked
+	HTTPS            bool               `json:"https"`
+	Active           int                `json:"active"`
+	Passive          int                `json:"passive"`
+	ProtocolRelative int                `json:"protocol_relative"`
+	InsecureForms    int                `json:"insecure_forms"`
+	Items            []MixedContentItem `json:"items"`
+}
+
+// MixedContentAnalyzer reports http:// sub-resources and form targets on HTTPS pages, along with
+// protocol-relative references that silently depend on the page's scheme
+type MixedContentAnalyzer struct{}
+
+func (MixedContentAnalyzer) Name() string { return "mixed_content" }
+
+func (MixedContentAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	out := MixedContentFindings{HTTPS: page.TLS != nil, Items: []MixedContentItem{}}
+	if !out.HTTPS {
+		return out, nil
+	}
+
+	for _, r := range collectResources(page) {
+		item := MixedContentItem{Element: r.Element, Attribute: r.Attribute, Kind: r.Kind, URL: r.URL.String()}
+		switch {
+		case strings.HasPrefix(r.Raw, "//"):
+			item.Category = MixedProtocolRelative
+			item.URL = r.Raw
+			out.ProtocolRelative++
+		case r.URL.Scheme != "http":
+			continue
+		case r.Active:
+			item.Category = MixedActive
+			out.Active++
+		default:
+			item.Category = MixedPassive
+			out.Passive++
+		}
+		out.Items = append(out.Items, item)
+	}
+
+	walkElements(page.Document(), func(n *html.Node) bool {
+		var attribute string
+		switch n.Data {
+		case "form":
+			attribute = "action"
+		case "button", "input":
+			attribute = "formaction"
+		default:
+			return true
+		}
+		action, ok := nodeAttr(n, attribute)
+		if !ok {
+			return true
+		}
+		if u, err := page.Resolve(strings.TrimSpace(action)); err == nil && u.Scheme == "http" {
+			out.Items = append(out.Items, MixedContentItem{
+				Category: MixedInsecureForm, Element: n.Data, Attribute: attribute, URL: u.String(),
+			})
+			out.InsecureForms++
+		}
+		return true
+	})
+	return out, nil
+}
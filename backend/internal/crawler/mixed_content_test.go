@@ -0,0 +1,74 @@
+package crawler
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+const mixedContentPage = `<!doctype html><html><head>
+  <script src="http://cdn.example.com/app.js"></script>
+  <link rel="stylesheet" href="http://cdn.example.com/site.css">
+  <link rel="stylesheet" href="//cdn.example.com/theme.css">
+  <style>@font-face { src: url("http://fonts.example.com/a.woff2"); } body { background: url(/bg.png) }</style>
+</head><body>
+  <img src="http://img.example.com/a.png" srcset="/b.png 2x, http://img.example.com/c.png 3x">
+  <img src="data:image/png;base64,AAAA">
+  <video poster="http://img.example.com/poster.jpg"><source src="http://media.example.com/v.mp4"></video>
+  <iframe src="http://widgets.example.com/embed"></iframe>
+  <div style="background-image: url('http://img.example.com/d.png')"></div>
+  <form action="http://forms.example.com/submit"><button formaction="/secure">Go</button></form>
+  <form action="/search"></form>
+  <script src="/local.js"></script>
+</body></html>`
+
+func TestMixedContent_HTTPSPage(t *testing.T) {
+	ts := httptest.NewTLSServer(htmlHandler(mixedContentPage))
+	t.Cleanup(ts.Close)
+	out := crawlFindings[MixedContentFindings](t, ts, "mixed_content")
+
+	if !out.HTTPS {
+		t.Fatal("expected page to be detected as HTTPS")
+	}
+	// script, stylesheet, font, iframe
+	if out.Active != 4 {
+		t.Errorf("expected 4 active items, got %d", out.Active)
+	}
+	// img src, srcset candidate, poster, video source, inline style background
+	if out.Passive != 5 {
+		t.Errorf("expected 5 passive items, got %d", out.Passive)
+	}
+	if out.ProtocolRelative != 1 || out.InsecureForms != 1 {
+		t.Errorf("expected 1 protocol-relative and 1 insecure form, got %d/%d", out.ProtocolRelative, out.InsecureForms)
+	}
+	if len(out.Items) != 11 {
+		t.Fatalf("expected 11 items, got %d: %+v", len(out.Items), out.Items)
+	}
+
+	byURL := map[string]MixedContentItem{}
+	for _, item := range out.Items {
+		byURL[item.URL] = item
+	}
+	if item := byURL["http://cdn.example.com/app.js"]; item.Element != "script" || item.Attribute != "src" || item.Kind != ResourceScript {
+		t.Errorf("unexpected script item: %+v", item)
+	}
+	if item := byURL["http://fonts.example.com/a.woff2"]; item.Element != "style" || item.Kind != ResourceFont || item.Category != MixedActive {
+		t.Errorf("unexpected font item: %+v", item)
+	}
+	if item := byURL["http://img.example.com/d.png"]; item.Element != "div" || item.Attribute != "url()" {
+		t.Errorf("unexpected inline style item: %+v", item)
+	}
+	if item := byURL["//cdn.example.com/theme.css"]; item.Category != MixedProtocolRelative {
+		t.Errorf("unexpected protocol-relative item: %+v", item)
+	}
+	if item := byURL["http://forms.example.com/submit"]; item.Category != MixedInsecureForm || item.Element != "form" {
+		t.Errorf("unexpected form item: %+v", item)
+	}
+}
+
+func TestMixedContent_SkipsPlainHTTPPages(t *testing.T) {
+	out := crawlFindings[MixedContentFindings](t, mixedContentPage, "mixed_content")
+
+	if out.HTTPS || len(out.Items) != 0 {
+		t.Fatalf("expected plain HTTP page to be skipped, got %+v", out)
+	}
+}
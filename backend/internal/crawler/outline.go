@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Outline is what one walk over the document finds that several analyzers need: the title,
+// the headings in document order and the links
+type Outline struct {
+	// Title is the trimmed text of the last non-empty <title>, as browsers would show the last
+	// one parsed; nil when the page has none
+	Title *string
+	// Headings are the h1-h6 elements in document order
+	Headings []*html.Node
+	// Links are the HTTP(S) targets of <a href> elements, relative ones resolved against the
+	// base URL
+	Links []string
+}
+
+// outlineOf walks the document of page once
+func outlineOf(page *Page) *Outline {
+	o := &Outline{}
+	walkElements(page.Document(), func(n *html.Node) bool {
+		if n.Namespace != "" {
+			// <title> and <a> inside SVG aren't the page's
+			return true
+		}
+		switch n.Data {
+		case "title":
+			if title := strings.TrimSpace(textContent(n)); title != "" {
+				o.Title = &title
+			}
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			o.Headings = append(o.Headings, n)
+		case "a":
+			if href, ok := nodeAttr(n, "href"); ok {
+				if link := linkTarget(page, href); link != "" {
+					o.Links = append(o.Links, link)
+				}
+			}
+		}
+		return true
+	})
+	return o
+}
+
+// HeadingCounts returns the number of headings per level, keyed h1 to h6
+func (o *Outline) HeadingCounts() map[string]int {
+	counts := map[string]int{"h1": 0, "h2": 0, "h3": 0, "h4": 0, "h5": 0, "h6": 0}
+	for _, h := range o.Headings {
+		counts[h.Data]++
+	}
+	return counts
+}
+
+// headingLevel returns 1 to 6 for a heading element
+func headingLevel(n *html.Node) int {
+	return int(n.Data[1] - '0')
+}
+
+// linkTarget returns the URL an href points at, or "" for fragments, scripts and non-HTTP schemes
+func linkTarget(page *Page, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+		return ""
+	}
+	// Skip non-HTTP/HTTPS links (mailto:, tel:, etc.)
+	hrefLower := strings.ToLower(href)
+	for _, scheme := range []string{"mailto:", "tel:", "sms:", "ftp:", "file:"} {
+		if strings.HasPrefix(hrefLower, scheme) {
+			return ""
+		}
+	}
+	// Resolve relative URLs against the base
+	if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+		u, err := page.Resolve(href)
+		if err != nil {
+			return ""
+		}
+		href = u.String()
+	}
+	if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+		return ""
+	}
+	return href
+}
+
+// documentBase returns the URL relative references of a document resolve against: the first
+// <base href>, resolved against the page URL, or the page URL itself
+func documentBase(u *url.URL, tokens []html.Token) *url.URL {
+	for _, t := range tokens {
+		if (t.Type != html.StartTagToken && t.Type != html.SelfClosingTagToken) || !strings.EqualFold(t.Data, "base") {
+			continue
+		}
+		href, ok := attr(t, "href")
+		if !ok || href == "" {
+			continue
+		}
+		if base, err := url.Parse(href); err == nil {
+			return u.ResolveReference(base)
+		}
+	}
+	return u
+}
+
+// TitleAnalyzer reports the page title
+type TitleAnalyzer struct{}
+
+func (TitleAnalyzer) Name() string { return "title" }
+
+func (TitleAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	return page.Outline().Title, nil
+}
+
+func (TitleAnalyzer) Apply(res *Result, findings any) any {
+	res.Title, _ = findings.(*string)
+	return nil
+}
+
+// HeadingsAnalyzer counts the headings of each level
+type HeadingsAnalyzer struct{}
+
+func (HeadingsAnalyzer) Name() string { return "headings" }
+
+func (HeadingsAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	return page.Outline().HeadingCounts(), nil
+}
+
+func (HeadingsAnalyzer) Apply(res *Result, findings any) any {
+	res.Headings, _ = findings.(map[string]int)
+	return nil
+}
+
+// LinkFindings are the links of a page, which the crawler checks after the analyzers ran
+type LinkFindings struct {
+	Internal int
+	External int
+	URLs     []string
+}
+
+// LinksAnalyzer collects the links of a page and counts internal and external ones. Without it
+// the crawler has no links to check.
+type LinksAnalyzer struct{}
+
+func (LinksAnalyzer) Name() string { return "links" }
+
+func (LinksAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	links := page.Outline().Links
+	out := LinkFindings{URLs: links}
+	for _, href := range links {
+		if isExternal(page.URL, href) {
+			out.External++
+		} else {
+			out.Internal++
+		}
+	}
+	return out, nil
+}
+
+func (LinksAnalyzer) Apply(res *Result, findings any) any {
+	if links, ok := findings.(LinkFindings); ok {
+		res.InternalLinks, res.ExternalLinks, res.Links = links.Internal, links.External, links.URLs
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"net/http"
+	"sort"
+)
+
+// defaultUserAgent is sent unless a request profile overrides it
+const defaultUserAgent = "url-crawler/1.0"
+
+// RequestProfile customises the requests made for a URL
+type RequestProfile struct {
+	UserAgent      string
+	AcceptLanguage string
+	Headers        map[string]string
+	Cookies        map[string]string
+	BasicAuth      *BasicAuth
+	BearerToken    string
+	// ApplyToLinks sends the profile with the link checks of the page's own host as well.
+	// It is never sent to other hosts.
+	ApplyToLinks bool
+}
+
+// BasicAuth holds HTTP basic authentication credentials
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// apply sets the profile's headers, cookies and credentials on a request
+func (p *RequestProfile) apply(req *http.Request) {
+	if p == nil {
+		return
+	}
+	// custom headers go first so the dedicated fields win over them
+	names := make([]string, 0, len(p.Headers))
+	for name := range p.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		req.Header.Set(name, p.Headers[name])
+	}
+	if p.UserAgent != "" {
+		req.Header.Set("User-Agent", p.UserAgent)
+	}
+	if p.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", p.AcceptLanguage)
+	}
+	names = names[:0]
+	for name := range p.Cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		req.AddCookie(&http.Cookie{Name: name, Value: p.Cookies[name]})
+	}
+	switch {
+	case p.BasicAuth != nil:
+		req.SetBasicAuth(p.BasicAuth.Username, p.BasicAuth.Password)
+	case p.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+p.BearerToken)
+	}
+}
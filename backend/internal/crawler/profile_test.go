@@ -0,0 +1,150 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedRequest is what the test servers saw of a request
+type recordedRequest struct {
+	path    string
+	header  http.Header
+	cookies []*http.Cookie
+}
+
+type requestLog struct {
+	mu   sync.Mutex
+	reqs []recordedRequest
+}
+
+func (l *requestLog) record(r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reqs = append(l.reqs, recordedRequest{path: r.URL.Path, header: r.Header.Clone(), cookies: r.Cookies()})
+}
+
+func (l *requestLog) byPath(path string) []recordedRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []recordedRequest
+	for _, r := range l.reqs {
+		if r.path == path {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// serveProfilePage serves a page linking to /internal on its own host and to an external server
+func serveProfilePage(t *testing.T) (*httptest.Server, *requestLog, *requestLog) {
+	t.Helper()
+	external := &requestLog{}
+	ext := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		external.record(r)
+	}))
+	t.Cleanup(ext.Close)
+
+	site := &requestLog{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.record(r)
+		if r.URL.Path != "/" {
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><a href="/internal">in</a><a href="` + ext.URL + `/external">out</a></body></html>`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, site, external
+}
+
+func crawlWithProfile(t *testing.T, target string, profile *RequestProfile) {
+	t.Helper()
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.CrawlWithOptions(ctx, target, Options{Analyzers: pageAnalyzers, Profile: profile}); err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+}
+
+func TestRequestProfile_DefaultHeaders(t *testing.T) {
+	ts, site, _ := serveProfilePage(t)
+	crawlWithProfile(t, ts.URL, nil)
+
+	page := site.byPath("/")
+	if len(page) != 1 {
+		t.Fatalf("expected one page request, got %d", len(page))
+	}
+	if ua := page[0].header.Get("User-Agent"); ua != defaultUserAgent {
+		t.Errorf("expected default user agent, got %q", ua)
+	}
+	if page[0].header.Get("Authorization") != "" || len(page[0].cookies) != 0 {
+		t.Errorf("expected no credentials without a profile, got %v", page[0].header)
+	}
+}
+
+func TestRequestProfile_AppliedToPage(t *testing.T) {
+	ts, site, external := serveProfilePage(t)
+	crawlWithProfile(t, ts.URL, &RequestProfile{
+		UserAgent:      "staging-bot/2.0",
+		AcceptLanguage: "de-DE",
+		Headers:        map[string]string{"X-Env": "staging", "User-Agent": "overridden"},
+		Cookies:        map[string]string{"session": "abc123"},
+		BasicAuth:      &BasicAuth{Username: "qa", Password: "s3cret"},
+	})
+
+	page := site.byPath("/")
+	if len(page) != 1 {
+		t.Fatalf("expected one page request, got %d", len(page))
+	}
+	h := page[0].header
+	if h.Get("User-Agent") != "staging-bot/2.0" || h.Get("Accept-Language") != "de-DE" || h.Get("X-Env") != "staging" {
+		t.Errorf("profile headers not applied: %v", h)
+	}
+	req := &http.Request{Header: h}
+	if user, pass, ok := req.BasicAuth(); !ok || user != "qa" || pass != "s3cret" {
+		t.Errorf("expected basic auth, got %q", h.Get("Authorization"))
+	}
+	if len(page[0].cookies) != 1 || page[0].cookies[0].Name != "session" || page[0].cookies[0].Value != "abc123" {
+		t.Errorf("expected session cookie, got %v", page[0].cookies)
+	}
+
+	// link checks don't carry the profile unless asked to
+	for _, r := range append(site.byPath("/internal"), external.byPath("/external")...) {
+		if r.header.Get("Authorization") != "" || len(r.cookies) != 0 || r.header.Get("X-Env") != "" {
+			t.Errorf("link check %s carried the profile: %v", r.path, r.header)
+		}
+	}
+}
+
+func TestRequestProfile_AppliedToInternalLinksOnly(t *testing.T) {
+	ts, site, external := serveProfilePage(t)
+	crawlWithProfile(t, ts.URL, &RequestProfile{
+		BearerToken:  "tok",
+		Cookies:      map[string]string{"session": "abc123"},
+		ApplyToLinks: true,
+	})
+
+	internal := site.byPath("/internal")
+	if len(internal) == 0 {
+		t.Fatal("expected the internal link to be checked")
+	}
+	for _, r := range internal {
+		if r.header.Get("Authorization") != "Bearer tok" || len(r.cookies) != 1 {
+			t.Errorf("expected the internal link check to carry the profile, got %v", r.header)
+		}
+	}
+	ext := external.byPath("/external")
+	if len(ext) == 0 {
+		t.Fatal("expected the external link to be checked")
+	}
+	for _, r := range ext {
+		if r.header.Get("Authorization") != "" || len(r.cookies) != 0 {
+			t.Errorf("credentials leaked to an external host: %v", r.header)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoProxy is returned by a pool that has no proxies
+var ErrNoProxy = errors.New("no proxy available")
+
+// proxyContextKey carries the proxy of a crawl to the transport
+type proxyContextKey struct{}
+
+// withProxy makes requests sent with ctx by a client from HTTPClient go through proxy
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	if proxy == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+func proxyFromContext(ctx context.Context) *url.URL {
+	p, _ := ctx.Value(proxyContextKey{}).(*url.URL)
+	return p
+}
+
+// ParseProxy parses an http, https, socks5 or socks5h proxy URL, which may carry credentials
+func ParseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", RedactProxy(u))
+	}
+	return u, nil
+}
+
+// RedactProxy formats a proxy URL without its credentials, for results and logs
+func RedactProxy(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// IsProxyError reports whether err came from connecting to or through a proxy, as opposed to
+// from the site behind it
+func IsProxyError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks ")
+	}
+	return false
+}
+
+// Proxy health tracking
+const (
+	proxyMaxFailures     = 3                // consecutive proxy errors before a proxy is benched
+	proxyInitialCooldown = 30 * time.Second // first bench, doubled on every further failure
+	proxyMaxCooldown     = 10 * time.Minute
+)
+
+// ProxyStatus is the health of one proxy of a pool
+type ProxyStatus struct {
+	Proxy       string     `json:"proxy"` // without credentials
+	Healthy     bool       `json:"healthy"`
+	Failures    int        `json:"consecutive_failures"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastUsed    *time.Time `json:"last_used,omitempty"`
+	RetryAfter  *time.Time `json:"retry_after,omitempty"` // when a benched proxy is tried again
+	Successes   int64      `json:"successes"`
+	TotalErrors int64      `json:"errors"`
+}
+
+type proxyState struct {
+	url         *url.URL
+	failures    int
+	lastError   string
+	lastUsed    time.Time
+	benchedTill time.Time
+	successes   int64
+	errors      int64
+}
+
+// ProxyPool rotates through proxies round-robin, skipping proxies that failed repeatedly
+// until their cooldown has passed. It is safe for concurrent use.
+type ProxyPool struct {
+	mu      sync.Mutex
+	proxies []*proxyState
+	next    int
+	now     func() time.Time
+}
+
+// NewProxyPool builds a pool from proxy URLs (see ParseProxy)
+func NewProxyPool(rawURLs []string) (*ProxyPool, error) {
+	p := &ProxyPool{now: time.Now}
+	for _, raw := range rawURLs {
+		u, err := ParseProxy(raw)
+		if err != nil {
+			return nil, err
+		}
+		p.proxies = append(p.proxies, &proxyState{url: u})
+	}
+	if len(p.proxies) == 0 {
+		return nil, ErrNoProxy
+	}
+	return p, nil
+}
+
+// Pick returns the next healthy proxy. When every proxy is benched, the one that comes back
+// first is tried early rather than crawling without a proxy.
+func (p *ProxyPool) Pick() *url.URL {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var chosen *proxyState
+	for i := range p.proxies {
+		s := p.proxies[(p.next+i)%len(p.proxies)]
+		if !now.Before(s.benchedTill) {
+			chosen = s
+			p.next = (p.next + i + 1) % len(p.proxies)
+			break
+		}
+	}
+	if chosen == nil {
+		for _, s := range p.proxies {
+			if chosen == nil || s.benchedTill.Before(chosen.benchedTill) {
+				chosen = s
+			}
+		}
+	}
+	chosen.lastUsed = now
+	return chosen.url
+}
+
+// Report records the outcome of a crawl through proxy. Only proxy errors count against it;
+// a crawl that failed at the site still shows the proxy works.
+func (p *ProxyPool) Report(proxy *url.URL, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range p.proxies {
+		if s.url != proxy {
+			continue
+		}
+		if err == nil || !IsProxyError(err) {
+			s.failures = 0
+			s.benchedTill = time.Time{}
+			s.successes++
+			return
+		}
+		s.failures++
+		s.errors++
+		s.lastError = err.Error()
+		if s.failures >= proxyMaxFailures {
+			cooldown := proxyInitialCooldown << (s.failures - proxyMaxFailures)
+			if cooldown > proxyMaxCooldown || cooldown <= 0 {
+				cooldown = proxyMaxCooldown
+			}
+			s.benchedTill = p.now().Add(cooldown)
+		}
+		return
+	}
+}
+
+// Status reports the health of every proxy in the pool
+func (p *ProxyPool) Status() []ProxyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	out := make([]ProxyStatus, 0, len(p.proxies))
+	for _, s := range p.proxies {
+		st := ProxyStatus{
+			Proxy:       RedactProxy(s.url),
+			Healthy:     !now.Before(s.benchedTill),
+			Failures:    s.failures,
+			LastError:   s.lastError,
+			Successes:   s.successes,
+			TotalErrors: s.errors,
+		}
+		if !s.lastUsed.IsZero() {
+			used := s.lastUsed
+			st.LastUsed = &used
+		}
+		if !st.Healthy {
+			retry := s.benchedTill
+			st.RetryAfter = &retry
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// proxyFunc picks the proxy of a request for the transport: the crawl's proxy if it has one,
+// otherwise fallback. With a guard, the target is checked before the request is handed to
+// the proxy, since the guard can't see the connection the proxy makes.
+func proxyFunc(guard *AddressGuard, fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := proxyFromContext(req.Context())
+		if proxy == nil {
+			if fallback == nil {
+				return nil, nil
+			}
+			return fallback(req)
+		}
+		if guard != nil {
+			if err := guard.checkHost(req.Context(), req.URL.Hostname()); err != nil {
+				return nil, err
+			}
+		}
+		return proxy, nil
+	}
+}
+
+// checkHost resolves host and checks every address it resolves to. The proxy may resolve it
+// differently; this catches the obvious cases of a proxied crawl aimed at internal addresses.
+func (g *AddressGuard) checkHost(ctx context.Context, host string) error {
+	if g.allowsHost(host) {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.Check(addr)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s to check it: %v", ErrBlockedAddress, host, err)
+	}
+	for _, addr := range addrs {
+		if err := g.Check(addr); err != nil {
+			return fmt.Errorf("connecting to %s: %w", host, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// forwardProxy is a minimal HTTP forward proxy for plain http targets
+type forwardProxy struct {
+	mu        sync.Mutex
+	targets   []string
+	proxyAuth []string
+}
+
+func (p *forwardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.targets = append(p.targets, r.URL.String())
+	p.proxyAuth = append(p.proxyAuth, r.Header.Get("Proxy-Authorization"))
+	p.mu.Unlock()
+
+	out, err := http.NewRequestWithContext(r.Context(), r.Method, r.URL.String(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	out.Header = r.Header.Clone()
+	out.Header.Del("Proxy-Authorization")
+	resp, err := http.DefaultTransport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func TestProxy_CrawlGoesThroughProxy(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Geo</title></head><body><a href="/about">about</a></body></html>`)
+	}))
+	defer site.Close()
+	fp := &forwardProxy{}
+	proxySrv := httptest.NewServer(fp)
+	defer proxySrv.Close()
+
+	proxy, err := ParseProxy(strings.Replace(proxySrv.URL, "http://", "http://user:pw@", 1))
+	if err != nil {
+		t.Fatalf("parse proxy: %v", err)
+	}
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	c.SetLinkCache(NewLinkCache(time.Hour, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// a direct crawl fills the link cache, which the crawl through the proxy must not answer from
+	if _, err := c.Crawl(ctx, site.URL); err != nil {
+		t.Fatalf("direct crawl error: %v", err)
+	}
+	res, err := c.CrawlWithOptions(ctx, site.URL, Options{Analyzers: pageAnalyzers, Proxy: proxy})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+
+	if res.Title == nil || *res.Title != "Geo" {
+		t.Errorf("expected the page through the proxy, got title %v", res.Title)
+	}
+	if res.Proxy != proxySrv.URL {
+		t.Errorf("expected the redacted proxy %s, got %q", proxySrv.URL, res.Proxy)
+	}
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	if len(fp.targets) != 2 || fp.targets[0] != site.URL+"/" || fp.targets[1] != site.URL+"/about" {
+		t.Errorf("expected the page and its link to go through the proxy, got %v", fp.targets)
+	}
+	for _, auth := range fp.proxyAuth {
+		if !strings.HasPrefix(auth, "Basic ") {
+			t.Errorf("expected proxy credentials, got %q", auth)
+		}
+	}
+}
+
+func TestProxy_GuardChecksTargetBehindProxy(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer site.Close()
+	proxySrv := httptest.NewServer(&forwardProxy{})
+	defer proxySrv.Close()
+
+	// the proxy is reachable by name, the loopback target behind it isn't
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(proxySrv.URL, "http://"))
+	proxy, _ := url.Parse("http://localhost:" + port)
+	guard, err := NewAddressGuard([]string{"localhost"})
+	if err != nil {
+		t.Fatalf("guard: %v", err)
+	}
+	c := New(HTTPClient(5*time.Second, WithAddressGuard(guard)))
+	_, err = c.CrawlWithOptions(context.Background(), site.URL, Options{Analyzers: pageAnalyzers, Proxy: proxy})
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("expected the loopback target to be blocked, got %v", err)
+	}
+}
+
+func TestProxy_IsProxyError(t *testing.T) {
+	// nothing listens on the proxy's port once the server is closed
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	site := httptest.NewServer(http.NotFoundHandler())
+	defer site.Close()
+
+	proxy, _ := ParseProxy(closed.URL)
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	_, err := c.CrawlWithOptions(context.Background(), site.URL, Options{Analyzers: pageAnalyzers, Proxy: proxy})
+	if err == nil || !IsProxyError(err) {
+		t.Errorf("expected a proxy error, got %v", err)
+	}
+	if IsProxyError(errors.New("connection refused")) {
+		t.Error("plain errors are not proxy errors")
+	}
+}
+
+func TestParseProxy(t *testing.T) {
+	for _, raw := range []string{"http://p:3128", "https://p:443", "socks5://u:pw@p:1080", "socks5h://p:1080"} {
+		if _, err := ParseProxy(raw); err != nil {
+			t.Errorf("%s: unexpected error %v", raw, err)
+		}
+	}
+	for _, raw := range []string{"ftp://p:21", "p:3128", "http://", "http://p:3128/path"} {
+		if _, err := ParseProxy(raw); err == nil {
+			t.Errorf("%s: expected an error", raw)
+		}
+	}
+	u, _ := ParseProxy("socks5://user:secret@p:1080")
+	if got := RedactProxy(u); got != "socks5://p:1080" {
+		t.Errorf("expected credentials to be redacted, got %s", got)
+	}
+}
+
+func TestProxyPool_RotationAndHealth(t *testing.T) {
+	pool, err := NewProxyPool([]string{"http://a:1", "http://b:1", "http://c:1"})
+	if err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pool.now = func() time.Time { return now }
+
+	var picked []string
+	for range 4 {
+		picked = append(picked, pool.Pick().Host)
+	}
+	if strings.Join(picked, ",") != "a:1,b:1,c:1,a:1" {
+		t.Errorf("expected round-robin, got %v", picked)
+	}
+
+	proxyErr := &net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}
+	b := pool.proxies[1].url
+	// failures at the site don't count against the proxy
+	pool.Report(b, errors.New("crawl failed: HTTP 500"))
+	for range proxyMaxFailures {
+		pool.Report(b, proxyErr)
+	}
+	for range 4 {
+		if p := pool.Pick(); p == b {
+			t.Fatal("picked a benched proxy")
+		}
+	}
+	status := pool.Status()
+	if status[1].Healthy || status[1].Failures != proxyMaxFailures || status[1].RetryAfter == nil || status[1].LastError == "" {
+		t.Errorf("expected b to be benched, got %+v", status[1])
+	}
+
+	now = now.Add(proxyInitialCooldown)
+	pool.Report(b, nil)
+	if st := pool.Status()[1]; !st.Healthy || st.Failures != 0 {
+		t.Errorf("expected b to recover, got %+v", st)
+	}
+}
+
+func TestProxyPool_AllBenched(t *testing.T) {
+	pool, _ := NewProxyPool([]string{"http://a:1", "http://b:1"})
+	proxyErr := &net.OpError{Op: "socks connect", Net: "tcp", Err: errors.New("general failure")}
+	for _, s := range pool.proxies {
+		for range proxyMaxFailures {
+			pool.Report(s.url, proxyErr)
+		}
+	}
+	// b was benched last, so a comes back first and is tried early
+	if p := pool.Pick(); p.Host != "a:1" {
+		t.Errorf("expected the proxy that recovers first, got %s", p.Host)
+	}
+	if _, err := NewProxyPool(nil); !errors.Is(err, ErrNoProxy) {
+		t.Errorf("expected ErrNoProxy for an empty pool, got %v", err)
+	}
+}
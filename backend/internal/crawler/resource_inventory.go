@@ -0,0 +1,246 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// maxFetchedResources bounds how many resources are sized per page
+	maxFetchedResources = 200
+	// maxResourceBytes bounds how much of a resource is downloaded when HEAD gives no size
+	maxResourceBytes = 20 << 20
+	// largestResources is how many of the biggest resources are reported
+	largestResources = 10
+	resourceWorkers  = 8
+	resourceTimeout  = 10 * time.Second
+)
+
+// ResourceStats counts resources and their transfer size
+type ResourceStats struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+// ResourceEntry is a single sub-resource of a page
+type ResourceEntry struct {
+	URL        string `json:"url"`
+	Kind       string `json:"kind"`
+	ThirdParty bool   `json:"third_party"`
+	Bytes      int64  `json:"bytes"` // -1 when the size is unknown
+	// Capped is true when the resource was larger than the download cap; Bytes is the cap
+	Capped bool `json:"capped,omitempty"`
+}
+
+type ResourceFindings struct {
+	// Fetched is true when resources were requested to measure their size
+	Fetched        bool                     `json:"fetched"`
+	Count          int                      `json:"count"`
+	DocumentBytes  int64                    `json:"document_bytes"`
+	DocumentCapped bool                     `json:"document_capped"` // cut at the size limit with no Content-Length; DocumentBytes is the limit
+	TotalBytes     int64                    `json:"total_bytes"`     // document plus every sized resource
+	ByType         map[string]ResourceStats `json:"by_type"`
+	FirstParty     ResourceStats            `json:"first_party"`
+	ThirdParty     ResourceStats            `json:"third_party"`
+	Unsized        int                      `json:"unsized"` // resources whose size couldn't be determined
+	Capped         int                      `json:"capped"`  // resources only sized up to the download cap
+	Largest        []ResourceEntry          `json:"largest"`
+}
+
+// ResourceAnalyzer inventories the scripts, stylesheets, images, fonts, frames and media a page
+// loads. With Options.FetchResources set it also sizes them through the crawler's Fetcher (the
+// login session, if the crawl has one), using HEAD where the server reports a Content-Length.
+// Sizes are transfer sizes: compressed bodies are counted as they come over the wire. The page
+// itself is counted by its Content-Length, or by the bytes read when it announces none.
+type ResourceAnalyzer struct{}
+
+func (ResourceAnalyzer) Name() string { return "resources" }
+
+func (ResourceAnalyzer) Analyze(ctx context.Context, page *Page) (any, error) {
+	out := ResourceFindings{
+		DocumentBytes: page.BodyBytes,
+		ByType:        make(map[string]ResourceStats),
+		Largest:       []ResourceEntry{},
+	}
+	if n, err := strconv.ParseInt(page.Header.Get("Content-Length"), 10, 64); err == nil && n >= 0 {
+		out.DocumentBytes = n
+	} else {
+		out.DocumentCapped = page.Truncated
+	}
+	out.TotalBytes = out.DocumentBytes
+
+	// the same URL referenced twice is only loaded once
+	seen := make(map[string]bool)
+	var entries []ResourceEntry
+	for _, r := range collectResources(page) {
+		u := *r.URL
+		u.Fragment = ""
+		key := u.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, ResourceEntry{
+			URL:        key,
+			Kind:       r.Kind,
+			ThirdParty: isThirdParty(page.URL, &u),
+			Bytes:      -1,
+		})
+	}
+
+	out.Fetched = page.Options.FetchResources && page.Fetcher != nil
+	if out.Fetched {
+		sizeResources(ctx, page, entries[:min(len(entries), maxFetchedResources)])
+	}
+
+	for _, e := range entries {
+		out.Count++
+		stats := out.ByType[e.Kind]
+		stats.Count++
+		party := &out.FirstParty
+		if e.ThirdParty {
+			party = &out.ThirdParty
+		}
+		party.Count++
+		if e.Bytes >= 0 {
+			stats.Bytes += e.Bytes
+			party.Bytes += e.Bytes
+			out.TotalBytes += e.Bytes
+		} else {
+			out.Unsized++
+		}
+		if e.Capped {
+			out.Capped++
+		}
+		out.ByType[e.Kind] = stats
+	}
+
+	if out.Fetched {
+		sized := make([]ResourceEntry, 0, len(entries))
+		for _, e := range entries {
+			if e.Bytes >= 0 {
+				sized = append(sized, e)
+			}
+		}
+		sort.SliceStable(sized, func(i, j int) bool { return sized[i].Bytes > sized[j].Bytes })
+		out.Largest = append(out.Largest, sized[:min(len(sized), largestResources)]...)
+	}
+	return out, nil
+}
+
+// isThirdParty reports whether u belongs to a different site than the page,
+// comparing registrable domains so subdomains (cdn.example.com) count as first-party
+func isThirdParty(page, u *url.URL) bool {
+	a, b := strings.ToLower(page.Hostname()), strings.ToLower(u.Hostname())
+	if a == b {
+		return false
+	}
+	siteA, errA := publicsuffix.EffectiveTLDPlusOne(a)
+	siteB, errB := publicsuffix.EffectiveTLDPlusOne(b)
+	if errA != nil || errB != nil {
+		// IP addresses and single-label hosts only match themselves
+		return true
+	}
+	return siteA != siteB
+}
+
+// sizeResources fills in the transfer size of each entry using a small worker pool
+func sizeResources(ctx context.Context, page *Page, entries []ResourceEntry) {
+	var wg sync.WaitGroup
+	jobs := make(chan int, len(entries))
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+
+	for range min(resourceWorkers, len(entries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				reqCtx, cancel := context.WithTimeout(ctx, resourceTimeout)
+				entries[i].Bytes, entries[i].Capped = resourceSize(reqCtx, page, entries[i].URL)
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// resourceAcceptEncoding is sent with resource requests as a browser would. Setting it
+// ourselves stops the transport from decompressing, so bodies are counted compressed.
+const resourceAcceptEncoding = "gzip, deflate, br"
+
+// resourceRequest builds a request for a resource of page as a browser would send it: the
+// profile's user agent and language go everywhere, its headers, cookies and credentials only
+// to the page's own host
+func resourceRequest(ctx context.Context, page *Page, method, target string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", defaultUserAgent)
+	req.Header.Set("Accept-Encoding", resourceAcceptEncoding)
+	if p := page.Options.Profile; p != nil {
+		if page.URL != nil && strings.EqualFold(req.URL.Host, page.URL.Host) {
+			p.apply(req)
+		} else {
+			if p.UserAgent != "" {
+				req.Header.Set("User-Agent", p.UserAgent)
+			}
+			if p.AcceptLanguage != "" {
+				req.Header.Set("Accept-Language", p.AcceptLanguage)
+			}
+		}
+	}
+	return req, nil
+}
+
+// resourceSize returns the transfer size of a resource, or -1 if it can't be determined. HEAD
+// is tried first; servers that reject it or don't report a length are asked with GET, which
+// reads at most maxResourceBytes and reports capped when the resource is larger.
+func resourceSize(ctx context.Context, page *Page, target string) (size int64, capped bool) {
+	req, err := resourceRequest(ctx, page, http.MethodHead, target)
+	if err != nil {
+		return -1, false
+	}
+	if resp, err := page.Fetcher.Do(req); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode < 400 && resp.ContentLength >= 0 {
+			return resp.ContentLength, false
+		}
+	}
+
+	req, err = resourceRequest(ctx, page, http.MethodGet, target)
+	if err != nil {
+		return -1, false
+	}
+	resp, err := page.Fetcher.Do(req)
+	if err != nil {
+		return -1, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return -1, false
+	}
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxResourceBytes+1))
+	if err != nil {
+		return -1, false
+	}
+	if n > maxResourceBytes {
+		return maxResourceBytes, true
+	}
+	return n, false
+}
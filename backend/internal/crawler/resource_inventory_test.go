@@ -0,0 +1,261 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sizedHandler serves n bytes, optionally refusing HEAD requests
+func sizedHandler(n int, allowHead bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && !allowHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(n))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(strings.Repeat("x", n)))
+		}
+	}
+}
+
+func resourceServers(t *testing.T) (page *httptest.Server, body string) {
+	t.Helper()
+	third := httptest.NewServer(sizedHandler(2000, true))
+	t.Cleanup(third.Close)
+	thirdURL := strings.Replace(third.URL, "127.0.0.1", "localhost", 1)
+
+	body = fmt.Sprintf(`<!doctype html><html><head>
+      <script src="/app.js"></script>
+      <link rel="stylesheet" href="/style.css">
+      <script src="%s/lib.js"></script>
+    </head><body>
+      <img src="/img.png"><img src="/img.png#again">
+      <img src="/missing.png">
+    </body></html>`, thirdURL)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(body))
+	})
+	mux.Handle("/app.js", sizedHandler(1000, true))
+	mux.Handle("/style.css", sizedHandler(500, false))
+	mux.Handle("/img.png", sizedHandler(3000, true))
+	page = httptest.NewServer(mux)
+	t.Cleanup(page.Close)
+	return page, body
+}
+
+func TestResources_MeasuresPageWeight(t *testing.T) {
+	ts, body := resourceServers(t)
+
+	out := crawlFindings[ResourceFindings](t, ts, "resources", Options{FetchResources: true})
+
+	if !out.Fetched || out.Count != 5 || out.Unsized != 1 {
+		t.Fatalf("expected 5 resources with 1 unsized, got %+v", out)
+	}
+	if out.DocumentBytes != int64(len(body)) || out.TotalBytes != int64(len(body))+6500 {
+		t.Fatalf("unexpected totals: document %d, total %d", out.DocumentBytes, out.TotalBytes)
+	}
+	wantTypes := map[string]ResourceStats{
+		ResourceScript:     {Count: 2, Bytes: 3000},
+		ResourceStylesheet: {Count: 1, Bytes: 500},
+		ResourceImage:      {Count: 2, Bytes: 3000},
+	}
+	for kind, want := range wantTypes {
+		if out.ByType[kind] != want {
+			t.Errorf("%s: expected %+v, got %+v", kind, want, out.ByType[kind])
+		}
+	}
+	if out.FirstParty != (ResourceStats{Count: 4, Bytes: 4500}) || out.ThirdParty != (ResourceStats{Count: 1, Bytes: 2000}) {
+		t.Fatalf("unexpected party split: first %+v, third %+v", out.FirstParty, out.ThirdParty)
+	}
+	if len(out.Largest) != 4 || out.Largest[0].Bytes != 3000 || !strings.HasSuffix(out.Largest[0].URL, "/img.png") {
+		t.Fatalf("unexpected largest resources: %+v", out.Largest)
+	}
+	if !out.Largest[1].ThirdParty {
+		t.Fatalf("expected third-party script second, got %+v", out.Largest[1])
+	}
+}
+
+func TestResources_InventoryWithoutFetching(t *testing.T) {
+	ts, _ := resourceServers(t)
+
+	out := crawlFindings[ResourceFindings](t, ts, "resources")
+
+	if out.Fetched || out.Count != 5 || out.Unsized != 5 || len(out.Largest) != 0 {
+		t.Fatalf("expected unsized inventory, got %+v", out)
+	}
+	if out.ByType[ResourceScript].Count != 2 || out.ThirdParty.Count != 1 {
+		t.Fatalf("unexpected counts: %+v", out)
+	}
+}
+
+func TestResources_CountsCompressedBytesWithProfile(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, _ = zw.Write([]byte(strings.Repeat("x", 10000)))
+	_ = zw.Close()
+
+	thirdParty := &requestLog{}
+	third := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		thirdParty.record(r)
+		w.Header().Set("Content-Length", "10")
+	}))
+	t.Cleanup(third.Close)
+	thirdURL := strings.Replace(third.URL, "127.0.0.1", "localhost", 1)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", htmlHandler(`<html><head><script src="/app.js"></script><script src="`+thirdURL+`/lib.js"></script></head></html>`))
+	mux.HandleFunc("/app.js", func(w http.ResponseWriter, r *http.Request) {
+		// only the profile's header unlocks the script; HEAD gives no length
+		if r.Header.Get("X-Token") != "secret" || r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Accept-Encoding") == "" {
+			t.Error("expected an explicit Accept-Encoding")
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(compressed.Bytes())
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	profile := &RequestProfile{Headers: map[string]string{"X-Token": "secret"}}
+	out := crawlFindings[ResourceFindings](t, ts, "resources", Options{FetchResources: true, Profile: profile})
+
+	if got := out.ByType[ResourceScript]; got.Bytes != int64(compressed.Len())+10 {
+		t.Fatalf("expected %d compressed bytes plus 10, got %+v", compressed.Len(), got)
+	}
+	for _, r := range thirdParty.byPath("/lib.js") {
+		if token := r.header.Get("X-Token"); token != "" {
+			t.Errorf("the profile's headers must not go to other hosts, got %q", token)
+		}
+	}
+}
+
+func TestIsThirdParty(t *testing.T) {
+	page, _ := url.Parse("https://www.example.com/")
+	cases := map[string]bool{
+		"https://www.example.com/a.js":    false,
+		"https://cdn.example.com/a.js":    false,
+		"https://example.org/a.js":        true,
+		"https://example.com.evil.io/a":   true,
+		"https://user.github.io/a.js":     true,
+		"http://127.0.0.1:8080/script.js": true,
+	}
+	for raw, want := range cases {
+		u, _ := url.Parse(raw)
+		if got := isThirdParty(page, u); got != want {
+			t.Errorf("%s: expected %v, got %v", raw, want, got)
+		}
+	}
+}
+
+func TestSrcsetURLs(t *testing.T) {
+	cases := map[string][]string{
+		"/a.png 1x, /b.png 2x":                     {"/a.png", "/b.png"},
+		"/a.png,/b.png 2x":                         {"/a.png,/b.png"}, // no whitespace, one URL
+		" /a.png ,, /b.png 640w ":                  {"/a.png", "/b.png"},
+		"data:image/png;base64,AAAA 1x, /b.png 2x": {"data:image/png;base64,AAAA", "/b.png"},
+		"/a.png, /b.png":                           {"/a.png", "/b.png"},
+		"/a,b.png 1x":                              {"/a,b.png"},
+		"/a.png (unknown, descriptor), /b.png":     {"/a.png", "/b.png"},
+		"":                                         nil,
+	}
+	for in, want := range cases {
+		if got := srcsetURLs(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestResources_MarksCappedResources(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><video src="/movie.mp4"></video><img src="/small.png"></body></html>`))
+	})
+	mux.HandleFunc("/movie.mp4", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		// streamed without a Content-Length
+		chunk := bytes.Repeat([]byte("x"), 1<<20)
+		for range maxResourceBytes>>20 + 1 {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	})
+	mux.Handle("/small.png", sizedHandler(100, false))
+
+	out := crawlFindings[ResourceFindings](t, mux, "resources", Options{FetchResources: true})
+
+	if out.Capped != 1 || len(out.Largest) != 2 {
+		t.Fatalf("expected one capped resource, got %+v", out)
+	}
+	if e := out.Largest[0]; !e.Capped || e.Bytes != maxResourceBytes {
+		t.Fatalf("expected the video to be capped at %d bytes, got %+v", maxResourceBytes, e)
+	}
+	if out.Largest[1].Capped {
+		t.Fatalf("expected the image to be sized exactly, got %+v", out.Largest[1])
+	}
+}
+
+func TestResources_DocumentBytesOfTruncatedPage(t *testing.T) {
+	body := `<html><body>` + strings.Repeat("<p>filler</p>", 1000) + `</body></html>`
+	cases := []struct {
+		name          string
+		contentLength bool
+		wantBytes     int64
+		wantCapped    bool
+	}{
+		{"announced length", true, int64(len(body)), false},
+		{"streamed", false, 4096, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				if tc.contentLength {
+					w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				} else {
+					w.(http.Flusher).Flush()
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer ts.Close()
+			c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+			c.SetLimits(Limits{MaxBodyBytes: 4096})
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			res, err := c.CrawlWithOptions(ctx, ts.URL, Options{Analyzers: []string{"resources"}})
+			if err != nil {
+				t.Fatalf("crawl error: %v", err)
+			}
+
+			out, ok := res.Findings["resources"].(ResourceFindings)
+			if !ok || out.DocumentBytes != tc.wantBytes || out.DocumentCapped != tc.wantCapped {
+				t.Fatalf("expected %d document bytes (capped %v), got %+v", tc.wantBytes, tc.wantCapped, res.Findings["resources"])
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package crawler
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Resource kinds
+const (
+	ResourceScript     = "script"
+	ResourceStylesheet = "stylesheet"
+	ResourceImage      = "image"
+	ResourceFont       = "font"
+	ResourceIframe     = "iframe"
+	ResourceMedia      = "media"
+)
+
+// resourceRef is a sub-resource reference found in a page
+type resourceRef struct {
+	Element   string // tag name; CSS references report the <style> element or the element with the style attribute
+	Attribute string // attribute holding the reference; "url()" or "@import" for CSS
+	Kind      string
+	// Active resources can change the page (scripts, styles, frames, fonts). Passive ones
+	// (images, media) are what browsers still load as mixed content.
+	Active bool
+	Raw    string
+	URL    *url.URL // resolved against the page's base URL
+}
+
+var (
+	cssURLPattern    = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\)`)
+	cssImportPattern = regexp.MustCompile(`@import\s+(?:"([^"]*)"|'([^']*)')`)
+)
+
+var fontExtensions = map[string]bool{".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true}
+
+// collectResources returns the sub-resources a page loads, in document order.
+// References that can't be fetched over HTTP (data:, blob:, javascript:) are skipped.
+// CSS is only scanned inline, in <style> elements and style attributes: what external
+// stylesheets load (their url() and @import references) isn't collected.
+func collectResources(page *Page) []resourceRef {
+	var refs []resourceRef
+	add := func(n *html.Node, attribute, kind string, active bool, raw string) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return
+		}
+		u, err := page.Resolve(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		refs = append(refs, resourceRef{Element: n.Data, Attribute: attribute, Kind: kind, Active: active, Raw: raw, URL: u})
+	}
+	addAttr := func(n *html.Node, attribute, kind string, active bool) {
+		if v, ok := nodeAttr(n, attribute); ok {
+			add(n, attribute, kind, active, v)
+		}
+	}
+	addSrcset := func(n *html.Node, kind string) {
+		v, ok := nodeAttr(n, "srcset")
+		if !ok {
+			return
+		}
+		for _, u := range srcsetURLs(v) {
+			add(n, "srcset", kind, false, u)
+		}
+	}
+	addCSS := func(n *html.Node, css string) {
+		for _, m := range cssImportPattern.FindAllStringSubmatch(css, -1) {
+			add(n, "@import", ResourceStylesheet, true, m[1]+m[2])
+		}
+		for _, m := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+			ref := m[1] + m[2] + m[3]
+			if fontExtensions[strings.ToLower(path.Ext(strings.SplitN(ref, "?", 2)[0]))] {
+				add(n, "url()", ResourceFont, true, ref)
+			} else {
+				add(n, "url()", ResourceImage, false, ref)
+			}
+		}
+	}
+
+	walkElements(page.Document(), func(n *html.Node) bool {
+		switch n.Data {
+		case "script":
+			addAttr(n, "src", ResourceScript, true)
+		case "link":
+			rel, _ := nodeAttr(n, "rel")
+			for _, r := range strings.Fields(strings.ToLower(rel)) {
+				switch r {
+				case "stylesheet":
+					addAttr(n, "href", ResourceStylesheet, true)
+				case "icon", "apple-touch-icon":
+					addAttr(n, "href", ResourceImage, false)
+				case "preload", "modulepreload":
+					as, _ := nodeAttr(n, "as")
+					switch strings.ToLower(as) {
+					case "script", "":
+						addAttr(n, "href", ResourceScript, true)
+					case "style":
+						addAttr(n, "href", ResourceStylesheet, true)
+					case "font":
+						addAttr(n, "href", ResourceFont, true)
+					case "image":
+						addAttr(n, "href", ResourceImage, false)
+					}
+				}
+			}
+		case "img":
+			addAttr(n, "src", ResourceImage, false)
+			addSrcset(n, ResourceImage)
+		case "input":
+			if typ, _ := nodeAttr(n, "type"); strings.EqualFold(typ, "image") {
+				addAttr(n, "src", ResourceImage, false)
+			}
+		case "source":
+			if n.Parent != nil && (n.Parent.Data == "video" || n.Parent.Data == "audio") {
+				addAttr(n, "src", ResourceMedia, false)
+			} else {
+				addAttr(n, "src", ResourceImage, false)
+				addSrcset(n, ResourceImage)
+			}
+		case "video":
+			addAttr(n, "src", ResourceMedia, false)
+			addAttr(n, "poster", ResourceImage, false)
+		case "audio":
+			addAttr(n, "src", ResourceMedia, false)
+		case "track":
+			addAttr(n, "src", ResourceMedia, true)
+		case "iframe", "frame":
+			addAttr(n, "src", ResourceIframe, true)
+		case "embed":
+			addAttr(n, "src", ResourceMedia, true)
+		case "object":
+			addAttr(n, "data", ResourceMedia, true)
+		case "style":
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.TextNode {
+					addCSS(n, c.Data)
+				}
+			}
+		}
+		if style, ok := nodeAttr(n, "style"); ok {
+			addCSS(n, style)
+		}
+		return true
+	})
+	return refs
+}
+
+// srcsetURLs returns the candidate URLs of a srcset attribute, split as the HTML spec does: a
+// URL runs to the next whitespace, so commas inside it (as in data: URLs) don't end it, and
+// its descriptors run to the next comma outside parentheses
+func srcsetURLs(srcset string) []string {
+	const space = " \t\n\f\r"
+	var urls []string
+	s := srcset
+	for {
+		s = strings.TrimLeft(s, space+",")
+		if s == "" {
+			return urls
+		}
+		end := strings.IndexAny(s, space)
+		if end < 0 {
+			end = len(s)
+		}
+		u := s[:end]
+		s = s[end:]
+		if trimmed := strings.TrimRight(u, ","); trimmed != u {
+			// trailing commas end the candidate; it has no descriptors
+			urls = append(urls, trimmed)
+			continue
+		}
+		urls = append(urls, u)
+		depth := 0
+		i := 0
+	descriptors:
+		for ; i < len(s); i++ {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth = max(depth-1, 0)
+			case ',':
+				if depth == 0 {
+					break descriptors
+				}
+			}
+		}
+		s = s[i:]
+	}
+}
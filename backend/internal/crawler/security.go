@@ -0,0 +1,339 @@
+package crawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Check statuses
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+	// CheckNotApplicable marks a check that doesn't apply to the page, like HSTS over plain HTTP
+	CheckNotApplicable = "n/a"
+)
+
+// hstsMinMaxAge is the shortest HSTS max-age that passes (180 days)
+const hstsMinMaxAge = 180 * 24 * 60 * 60
+
+// defaultCertExpiryWarning is how close to expiry a certificate starts to warn by default
+const defaultCertExpiryWarning = 30 * 24 * time.Hour
+
+// HeaderCheck grades one security header
+type HeaderCheck struct {
+	Header  string `json:"header"`
+	Value   string `json:"value,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// CookieCheck lists the flags of a cookie set by the page and what is wrong with them
+type CookieCheck struct {
+	Name     string   `json:"name"`
+	Secure   bool     `json:"secure"`
+	HttpOnly bool     `json:"http_only"`
+	SameSite string   `json:"same_site"` // empty when the attribute is missing
+	Issues   []string `json:"issues"`
+}
+
+// TLSReport describes the connection and leaf certificate the page was served over
+type TLSReport struct {
+	Version      string    `json:"version"`
+	CipherSuite  string    `json:"cipher_suite"`
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SANs         []string  `json:"sans"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	DaysToExpiry int       `json:"days_to_expiry"`
+	Issues       []string  `json:"issues"`
+}
+
+type SecurityFindings struct {
+	// Grade is A to F, derived from Score
+	Grade   string        `json:"grade"`
+	Score   int           `json:"score"`
+	HTTPS   bool          `json:"https"`
+	Headers []HeaderCheck `json:"headers"`
+	Cookies []CookieCheck `json:"cookies"`
+	TLS     *TLSReport    `json:"tls"` // nil for plain HTTP
+}
+
+// score penalties
+const (
+	secHeaderFailPenalty = 15
+	secHeaderWarnPenalty = 5
+	secCookiePenalty     = 5
+	secCookiePenaltyCap  = 20
+	secTLSPenalty        = 20
+	secNoHTTPSPenalty    = 30
+)
+
+// SecurityAnalyzer grades the response's security headers, cookie flags and TLS connection
+type SecurityAnalyzer struct {
+	// ExpiryWarning is how close to expiry a certificate is reported; zero means 30 days
+	ExpiryWarning time.Duration
+}
+
+func (SecurityAnalyzer) Name() string { return "security" }
+
+func (a SecurityAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	out := SecurityFindings{HTTPS: page.TLS != nil, Cookies: []CookieCheck{}}
+	h := page.Header
+	if h == nil {
+		h = http.Header{}
+	}
+	csp := h.Get("Content-Security-Policy")
+	out.Headers = []HeaderCheck{
+		checkHSTS(h.Get("Strict-Transport-Security"), out.HTTPS),
+		checkCSP(csp, h.Get("Content-Security-Policy-Report-Only")),
+		checkFrameOptions(h.Get("X-Frame-Options"), csp),
+		checkContentTypeOptions(h.Get("X-Content-Type-Options")),
+		checkReferrerPolicy(h.Get("Referrer-Policy")),
+		checkPermissionsPolicy(h.Get("Permissions-Policy")),
+	}
+	for _, cookie := range responseCookies(h) {
+		out.Cookies = append(out.Cookies, checkCookie(cookie, out.HTTPS))
+	}
+	if page.TLS != nil {
+		warn := a.ExpiryWarning
+		if warn <= 0 {
+			warn = defaultCertExpiryWarning
+		}
+		out.TLS = tlsReport(page.TLS, time.Now(), warn)
+	}
+
+	out.Score = 100
+	if !out.HTTPS {
+		out.Score -= secNoHTTPSPenalty
+	}
+	for _, c := range out.Headers {
+		switch c.Status {
+		case CheckFail:
+			out.Score -= secHeaderFailPenalty
+		case CheckWarn:
+			out.Score -= secHeaderWarnPenalty
+		}
+	}
+	cookiePenalty := 0
+	for _, c := range out.Cookies {
+		cookiePenalty += secCookiePenalty * len(c.Issues)
+	}
+	out.Score -= min(cookiePenalty, secCookiePenaltyCap)
+	if out.TLS != nil {
+		out.Score -= secTLSPenalty * len(out.TLS.Issues)
+	}
+	out.Score = max(out.Score, 0)
+	out.Grade = securityGrade(out.Score)
+	return out, nil
+}
+
+func securityGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	}
+	return "F"
+}
+
+func checkHSTS(value string, https bool) HeaderCheck {
+	c := HeaderCheck{Header: "Strict-Transport-Security", Value: value}
+	switch {
+	case !https:
+		// browsers ignore the header over HTTP; the missing HTTPS is penalised once, on its own
+		c.Status, c.Message = CheckNotApplicable, "only applies to pages served over HTTPS"
+	case value == "":
+		c.Status, c.Message = CheckFail, "header is missing"
+	default:
+		maxAge := -1
+		for _, directive := range strings.Split(value, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if strings.EqualFold(k, "max-age") {
+				if n, err := strconv.Atoi(strings.Trim(v, `"`)); err == nil {
+					maxAge = n
+				}
+			}
+		}
+		switch {
+		case maxAge < 0:
+			c.Status, c.Message = CheckFail, "max-age is missing or invalid"
+		case maxAge < hstsMinMaxAge:
+			c.Status, c.Message = CheckWarn, fmt.Sprintf("max-age %d is shorter than 180 days", maxAge)
+		default:
+			c.Status = CheckPass
+		}
+	}
+	return c
+}
+
+func checkCSP(value, reportOnly string) HeaderCheck {
+	c := HeaderCheck{Header: "Content-Security-Policy", Value: value}
+	if value == "" {
+		if reportOnly != "" {
+			c.Value = reportOnly
+			c.Status, c.Message = CheckWarn, "policy is only reported, not enforced"
+			return c
+		}
+		c.Status, c.Message = CheckFail, "header is missing"
+		return c
+	}
+	for _, directive := range strings.Split(value, ";") {
+		fields := strings.Fields(strings.ToLower(directive))
+		if len(fields) == 0 || (fields[0] != "script-src" && fields[0] != "default-src") {
+			continue
+		}
+		for _, src := range fields[1:] {
+			if src == "'unsafe-inline'" || src == "'unsafe-eval'" {
+				c.Status, c.Message = CheckWarn, fmt.Sprintf("%s allows %s", fields[0], src)
+				return c
+			}
+		}
+	}
+	c.Status = CheckPass
+	return c
+}
+
+func checkFrameOptions(value, csp string) HeaderCheck {
+	c := HeaderCheck{Header: "X-Frame-Options", Value: value}
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "DENY", "SAMEORIGIN":
+		c.Status = CheckPass
+	case "":
+		if strings.Contains(strings.ToLower(csp), "frame-ancestors") {
+			c.Status, c.Message = CheckPass, "framing is restricted by CSP frame-ancestors"
+		} else {
+			c.Status, c.Message = CheckFail, "header is missing"
+		}
+	default:
+		c.Status, c.Message = CheckWarn, "unrecognised value"
+	}
+	return c
+}
+
+func checkContentTypeOptions(value string) HeaderCheck {
+	c := HeaderCheck{Header: "X-Content-Type-Options", Value: value}
+	switch {
+	case strings.EqualFold(strings.TrimSpace(value), "nosniff"):
+		c.Status = CheckPass
+	case value == "":
+		c.Status, c.Message = CheckFail, "header is missing"
+	default:
+		c.Status, c.Message = CheckFail, "value must be nosniff"
+	}
+	return c
+}
+
+func checkReferrerPolicy(value string) HeaderCheck {
+	c := HeaderCheck{Header: "Referrer-Policy", Value: value}
+	if value == "" {
+		c.Status, c.Message = CheckWarn, "header is missing; browsers fall back to their default policy"
+		return c
+	}
+	// the last recognised token of a comma-separated list wins
+	policies := strings.Split(value, ",")
+	policy := strings.ToLower(strings.TrimSpace(policies[len(policies)-1]))
+	switch policy {
+	case "unsafe-url":
+		c.Status, c.Message = CheckFail, "full URLs are sent to every origin"
+	case "no-referrer-when-downgrade", "origin", "origin-when-cross-origin":
+		c.Status, c.Message = CheckWarn, "paths or origins leak to other sites"
+	default:
+		c.Status = CheckPass
+	}
+	return c
+}
+
+func checkPermissionsPolicy(value string) HeaderCheck {
+	c := HeaderCheck{Header: "Permissions-Policy", Value: value}
+	if value == "" {
+		c.Status, c.Message = CheckWarn, "header is missing"
+		return c
+	}
+	c.Status = CheckPass
+	return c
+}
+
+func checkCookie(cookie *http.Cookie, https bool) CookieCheck {
+	c := CookieCheck{Name: cookie.Name, Secure: cookie.Secure, HttpOnly: cookie.HttpOnly, Issues: []string{}}
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		c.SameSite = "Lax"
+	case http.SameSiteStrictMode:
+		c.SameSite = "Strict"
+	case http.SameSiteNoneMode:
+		c.SameSite = "None"
+	}
+	if !cookie.Secure && https {
+		c.Issues = append(c.Issues, "missing Secure flag")
+	}
+	if !cookie.HttpOnly {
+		c.Issues = append(c.Issues, "missing HttpOnly flag")
+	}
+	switch {
+	case c.SameSite == "":
+		c.Issues = append(c.Issues, "missing SameSite attribute")
+	case c.SameSite == "None" && !cookie.Secure:
+		c.Issues = append(c.Issues, "SameSite=None requires the Secure flag")
+	}
+	return c
+}
+
+func tlsReport(state *tls.ConnectionState, now time.Time, warn time.Duration) *TLSReport {
+	r := &TLSReport{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		SANs:        []string{},
+		Issues:      []string{},
+	}
+	// there are no protocol version or cipher checks: the client refuses anything older than
+	// TLS 1.2 and never offers an insecure suite, so such a page fails its handshake instead
+	if len(state.PeerCertificates) == 0 {
+		return r
+	}
+	cert := state.PeerCertificates[0]
+	r.Subject = certName(cert.Subject.CommonName, cert.Subject.String())
+	r.Issuer = certName(cert.Issuer.CommonName, cert.Issuer.String())
+	r.SANs = certSANs(cert)
+	r.NotBefore = cert.NotBefore.UTC()
+	r.NotAfter = cert.NotAfter.UTC()
+	r.DaysToExpiry = int(cert.NotAfter.Sub(now).Hours() / 24)
+	switch {
+	case now.After(cert.NotAfter):
+		r.Issues = append(r.Issues, "certificate has expired")
+	case cert.NotAfter.Sub(now) <= warn:
+		r.Issues = append(r.Issues, fmt.Sprintf("certificate expires in %d days", r.DaysToExpiry))
+	}
+	return r
+}
+
+// certName prefers the common name and falls back to the full distinguished name
+func certName(commonName, dn string) string {
+	if commonName != "" {
+		return commonName
+	}
+	return dn
+}
+
+func certSANs(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+	return sans
+}
@@ -0,0 +1,124 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func headerStatuses(out SecurityFindings) map[string]string {
+	statuses := make(map[string]string, len(out.Headers))
+	for _, h := range out.Headers {
+		statuses[h.Header] = h.Status
+	}
+	return statuses
+}
+
+func TestSecurity_HardenedHTTPSSite(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		w.Header().Set("Permissions-Policy", "camera=()")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "x", Secure: true, HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer ts.Close()
+
+	out := crawlFindings[SecurityFindings](t, ts, "security")
+
+	for header, status := range headerStatuses(out) {
+		if status != CheckPass {
+			t.Errorf("%s: expected pass, got %s", header, status)
+		}
+	}
+	if out.Score != 100 || out.Grade != "A" || !out.HTTPS {
+		t.Fatalf("expected grade A over HTTPS, got %s (%d)", out.Grade, out.Score)
+	}
+	if len(out.Cookies) != 1 || len(out.Cookies[0].Issues) != 0 || out.Cookies[0].SameSite != "Strict" {
+		t.Fatalf("unexpected cookies: %+v", out.Cookies)
+	}
+	if out.TLS == nil {
+		t.Fatal("expected TLS report")
+	}
+	if out.TLS.Version != "TLS 1.3" || out.TLS.CipherSuite == "" || out.TLS.DaysToExpiry <= 0 {
+		t.Fatalf("unexpected TLS report: %+v", out.TLS)
+	}
+	if len(out.TLS.SANs) == 0 || out.TLS.Issuer == "" {
+		t.Fatalf("expected certificate details, got %+v", out.TLS)
+	}
+}
+
+func TestSecurity_BarePlainHTTPSite(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Content-Security-Policy", "script-src 'self' 'unsafe-inline'")
+		w.Header().Set("Referrer-Policy", "unsafe-url")
+		w.Header().Add("Set-Cookie", "tracking=1; SameSite=None")
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer ts.Close()
+
+	out := crawlFindings[SecurityFindings](t, ts, "security")
+
+	want := map[string]string{
+		"Strict-Transport-Security": CheckNotApplicable,
+		"Content-Security-Policy":   CheckWarn,
+		"X-Frame-Options":           CheckFail,
+		"X-Content-Type-Options":    CheckFail,
+		"Referrer-Policy":           CheckFail,
+		"Permissions-Policy":        CheckWarn,
+	}
+	got := headerStatuses(out)
+	for header, status := range want {
+		if got[header] != status {
+			t.Errorf("%s: expected %s, got %s", header, status, got[header])
+		}
+	}
+	if out.HTTPS || out.TLS != nil || out.Grade != "F" {
+		t.Fatalf("expected grade F without TLS, got %+v", out)
+	}
+	// the missing HTTPS costs once; HSTS doesn't add to it
+	if want := 100 - secNoHTTPSPenalty - 3*secHeaderFailPenalty - 2*secHeaderWarnPenalty - 2*secCookiePenalty; out.Score != want {
+		t.Fatalf("expected score %d, got %d", want, out.Score)
+	}
+	if len(out.Cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got %+v", out.Cookies)
+	}
+	// Secure isn't expected over plain HTTP, but SameSite=None still needs it
+	if issues := out.Cookies[0].Issues; len(issues) != 2 {
+		t.Fatalf("unexpected cookie issues: %v", issues)
+	}
+}
+
+func TestTLSReport_FlagsExpiringCertificate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "example.com"},
+		Issuer:    pkix.Name{Organization: []string{"Test CA"}},
+		DNSNames:  []string{"example.com", "www.example.com"},
+		NotBefore: now.AddDate(0, -3, 0),
+		NotAfter:  now.AddDate(0, 0, 10),
+	}
+	r := tlsReport(&tls.ConnectionState{
+		Version:          tls.VersionTLS12,
+		CipherSuite:      tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		PeerCertificates: []*x509.Certificate{cert},
+	}, now, 30*24*time.Hour)
+
+	if r.Version != "TLS 1.2" || r.Subject != "example.com" || r.Issuer != "O=Test CA" {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	if r.DaysToExpiry != 10 || len(r.SANs) != 2 {
+		t.Fatalf("unexpected certificate details: %+v", r)
+	}
+	if len(r.Issues) != 1 || r.Issues[0] != "certificate expires in 10 days" {
+		t.Fatalf("expected an expiry issue, got %v", r.Issues)
+	}
+}
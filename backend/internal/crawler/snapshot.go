@@ -0,0 +1,147 @@
+package crawler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// redactedHeaders carry credentials, which snapshots don't keep. The headers a request profile
+// adds are redacted as well, since that is where API keys and tokens go.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// Exchange is a request the crawler sent and the head of the response it got
+type Exchange struct {
+	Method string
+	URL    string // after redirects
+	// RequestHeader is what was sent, with the values of credential and profile headers redacted
+	RequestHeader http.Header
+	Proto         string // e.g. HTTP/1.1
+	Status        string // e.g. 200 OK
+	Header        http.Header
+	FetchedAt     time.Time
+}
+
+func newExchange(req *http.Request, resp *http.Response, profile *RequestProfile) Exchange {
+	// the response's request is the last one of any redirects
+	if resp.Request != nil {
+		req = resp.Request
+	}
+	reqHeader := req.Header.Clone()
+	if reqHeader == nil {
+		reqHeader = http.Header{}
+	}
+	redact := func(name string) {
+		if reqHeader.Get(name) != "" {
+			reqHeader.Set(name, "[redacted]")
+		}
+	}
+	for _, name := range redactedHeaders {
+		redact(name)
+	}
+	if profile != nil {
+		for name := range profile.Headers {
+			redact(name)
+		}
+	}
+	return Exchange{
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		RequestHeader: reqHeader,
+		Proto:         resp.Proto,
+		Status:        resp.Status,
+		Header:        resp.Header.Clone(),
+		FetchedAt:     time.Now(),
+	}
+}
+
+// WriteRequest writes the request as an HTTP request message
+func (e *Exchange) WriteRequest(w io.Writer) error {
+	req, err := http.NewRequest(e.Method, e.URL, nil)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %s %s\r\n", e.Method, req.URL.RequestURI(), e.proto())
+	fmt.Fprintf(bw, "Host: %s\r\n", req.URL.Host)
+	if err := e.RequestHeader.Write(bw); err != nil {
+		return err
+	}
+	bw.WriteString("\r\n")
+	return bw.Flush()
+}
+
+// WriteResponse writes the response as an HTTP response message with body
+func (e *Exchange) WriteResponse(w io.Writer, body []byte) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	fmt.Fprintf(bw, "%s %s\r\n", e.proto(), e.Status)
+	if err := e.Header.Write(bw); err != nil {
+		return cw.n, err
+	}
+	bw.WriteString("\r\n")
+	bw.Write(body)
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func (e *Exchange) proto() string {
+	if e.Proto == "" {
+		return "HTTP/1.1"
+	}
+	return e.Proto
+}
+
+// Snapshot is the response a crawl received for its page: the headers as sent and the body
+// as read, before it was transcoded. The transport has already undone any Content-Encoding.
+type Snapshot struct {
+	Exchange
+	Body []byte // empty when the page wasn't read, e.g. for a 304 or a non-HTML Content-Type
+	// Truncated says the body was cut at the size limit
+	Truncated bool
+	// Links are the link checks of the page, when Options.SnapshotLinks asks for them
+	Links []Exchange
+}
+
+func newSnapshot(req *http.Request, resp *http.Response, profile *RequestProfile) *Snapshot {
+	return &Snapshot{Exchange: newExchange(req, resp, profile)}
+}
+
+// WriteTo writes s as an HTTP response message: status line, headers, a blank line and the body
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	return s.WriteResponse(w, s.Body)
+}
+
+// exchangeRecorder records every exchange of the requests it forwards
+type exchangeRecorder struct {
+	Fetcher
+	profile   *RequestProfile // whose headers are redacted
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+func (r *exchangeRecorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.Fetcher.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	ex := newExchange(req, resp, r.profile)
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, ex)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,128 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCrawl_Snapshot(t *testing.T) {
+	// windows-1252 "café"; the snapshot keeps the bytes as received, not the decoded text
+	page := []byte("<html><head><title>caf\xe9</title></head></html>")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=windows-1252")
+		w.Header().Set("X-Served-By", "cache-1")
+		_, _ = w.Write(page)
+	}))
+	defer ts.Close()
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	res, err := c.Crawl(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Snapshot != nil {
+		t.Error("expected no snapshot unless asked for")
+	}
+
+	res, err = c.CrawlWithOptions(context.Background(), ts.URL, Options{Snapshot: true})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Snapshot == nil {
+		t.Fatal("expected a snapshot")
+	}
+	if !bytes.Equal(res.Snapshot.Body, page) {
+		t.Errorf("expected the raw body, got %q", res.Snapshot.Body)
+	}
+
+	var buf bytes.Buffer
+	if _, err := res.Snapshot.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(&buf), nil)
+	if err != nil {
+		t.Fatalf("expected a parseable HTTP message: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Served-By") != "cache-1" || !bytes.Equal(body, page) {
+		t.Errorf("unexpected message: %d %v %q", resp.StatusCode, resp.Header, body)
+	}
+}
+
+func TestCrawl_SnapshotOfUnreadPage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer ts.Close()
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	res, err := c.CrawlWithOptions(context.Background(), ts.URL, Options{Snapshot: true, Conditional: &Validators{ETag: `"v1"`}})
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	if res.Snapshot == nil || res.Snapshot.Status != "304 Not Modified" || len(res.Snapshot.Body) != 0 {
+		t.Errorf("expected a headers-only snapshot of the 304, got %+v", res.Snapshot)
+	}
+}
+
+func TestCrawl_SnapshotLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><a href="/about">about</a></body></html>`))
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Page", "about")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := New(HTTPClient(5*time.Second, WithoutAddressGuard()))
+	c.SetLinkCache(NewLinkCache(time.Hour, nil))
+	profile := &RequestProfile{BearerToken: "secret", Headers: map[string]string{"x-api-key": "k3y"}, ApplyToLinks: true}
+	for range 2 {
+		res, err := c.CrawlWithOptions(context.Background(), ts.URL, Options{Snapshot: true, SnapshotLinks: true, Profile: profile})
+		if err != nil {
+			t.Fatalf("crawl error: %v", err)
+		}
+		snap := res.Snapshot
+		for _, name := range []string{"Authorization", "X-Api-Key"} {
+			if got := snap.RequestHeader.Get(name); got != "[redacted]" {
+				t.Errorf("expected %s to be redacted, got %q", name, got)
+			}
+		}
+		if snap.Method != http.MethodGet || snap.URL != ts.URL || snap.FetchedAt.IsZero() {
+			t.Errorf("unexpected request of the page: %s %s at %v", snap.Method, snap.URL, snap.FetchedAt)
+		}
+		// recorded link checks skip the cache, so the second crawl records the link again
+		if len(snap.Links) != 1 {
+			t.Fatalf("expected one recorded link check, got %d", len(snap.Links))
+		}
+		link := snap.Links[0]
+		if link.Method != http.MethodHead || link.URL != ts.URL+"/about" || link.Header.Get("X-Page") != "about" {
+			t.Errorf("unexpected link exchange %+v", link)
+		}
+		if got := link.RequestHeader.Get("X-Api-Key"); got != "[redacted]" {
+			t.Errorf("expected the profile header of the link check to be redacted, got %q", got)
+		}
+	}
+
+	var req bytes.Buffer
+	res, _ := c.CrawlWithOptions(context.Background(), ts.URL, Options{Snapshot: true})
+	if err := res.Snapshot.WriteRequest(&req); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := http.ReadRequest(bufio.NewReader(&req))
+	if err != nil {
+		t.Fatalf("expected a parseable request: %v", err)
+	}
+	if parsed.Method != http.MethodGet || parsed.Host != ts.Listener.Addr().String() || parsed.UserAgent() != defaultUserAgent {
+		t.Errorf("unexpected request %s %s %v", parsed.Method, parsed.Host, parsed.Header)
+	}
+}
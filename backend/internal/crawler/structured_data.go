@@ -0,0 +1,407 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Structured data formats
+const (
+	FormatJSONLD    = "json-ld"
+	FormatMicrodata = "microdata"
+	FormatRDFa      = "rdfa"
+)
+
+// Entity is a schema.org item normalised from any of the supported formats.
+// Types are stripped of the schema.org prefix; nested items are Entities themselves.
+type Entity struct {
+	Format     string         `json:"format"`
+	Types      []string       `json:"types"`
+	ID         string         `json:"id,omitempty"`
+	Properties map[string]any `json:"properties"`
+}
+
+// StructuredDataError is a block that could not be parsed
+type StructuredDataError struct {
+	Format  string `json:"format"`
+	Block   int    `json:"block"` // 0-based index of the block within its format
+	Message string `json:"message"`
+}
+
+// StructuredDataIssue reports required properties missing from an entity
+type StructuredDataIssue struct {
+	Entity  int      `json:"entity"` // index into Entities
+	Type    string   `json:"type"`
+	Missing []string `json:"missing"`
+}
+
+type StructuredDataFindings struct {
+	Entities []Entity              `json:"entities"`
+	Errors   []StructuredDataError `json:"errors"`
+	Issues   []StructuredDataIssue `json:"issues"`
+	// Eligible lists the validated types that have all required properties
+	Eligible []string `json:"eligible"`
+}
+
+// requiredProperties lists, per type, the properties needed for rich results.
+// Each requirement is a set of alternatives of which at least one must be present.
+var requiredProperties = map[string][][]string{
+	"Article":        {{"headline"}, {"author"}, {"datePublished"}},
+	"NewsArticle":    {{"headline"}, {"author"}, {"datePublished"}},
+	"BlogPosting":    {{"headline"}, {"author"}, {"datePublished"}},
+	"Product":        {{"name"}, {"offers", "review", "aggregateRating"}},
+	"Organization":   {{"name"}, {"url"}},
+	"BreadcrumbList": {{"itemListElement"}},
+	"ListItem":       {{"position"}, {"name", "item"}},
+}
+
+// StructuredDataAnalyzer extracts JSON-LD, Microdata and RDFa items and validates common types
+type StructuredDataAnalyzer struct{}
+
+func (StructuredDataAnalyzer) Name() string { return "structured_data" }
+
+func (StructuredDataAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	out := StructuredDataFindings{
+		Entities: []Entity{},
+		Errors:   []StructuredDataError{},
+		Issues:   []StructuredDataIssue{},
+		Eligible: []string{},
+	}
+	doc := page.Document()
+
+	entities, errs := extractJSONLD(doc)
+	out.Entities = append(out.Entities, entities...)
+	out.Errors = append(out.Errors, errs...)
+	out.Entities = append(out.Entities, extractMicrodata(doc)...)
+	out.Entities = append(out.Entities, extractRDFa(doc)...)
+
+	// a type is eligible when at least one entity of that type is complete
+	eligible := make(map[string]bool)
+	for i, e := range out.Entities {
+		for _, typ := range e.Types {
+			reqs, ok := requiredProperties[typ]
+			if !ok {
+				continue
+			}
+			missing := missingProperties(e, reqs)
+			if typ == "BreadcrumbList" {
+				missing = append(missing, breadcrumbIssues(e)...)
+			}
+			if len(missing) > 0 {
+				out.Issues = append(out.Issues, StructuredDataIssue{Entity: i, Type: typ, Missing: missing})
+				continue
+			}
+			eligible[typ] = true
+		}
+	}
+	for typ := range eligible {
+		out.Eligible = append(out.Eligible, typ)
+	}
+	sort.Strings(out.Eligible)
+	return out, nil
+}
+
+func missingProperties(e Entity, reqs [][]string) []string {
+	var missing []string
+	for _, alternatives := range reqs {
+		found := false
+		for _, p := range alternatives {
+			if hasValue(e.Properties[p]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, strings.Join(alternatives, "|"))
+		}
+	}
+	return missing
+}
+
+// breadcrumbIssues validates the ListItems of a BreadcrumbList
+func breadcrumbIssues(e Entity) []string {
+	var missing []string
+	for i, v := range asList(e.Properties["itemListElement"]) {
+		item, ok := v.(Entity)
+		if !ok {
+			missing = append(missing, fmt.Sprintf("itemListElement[%d]", i))
+			continue
+		}
+		for _, m := range missingProperties(item, requiredProperties["ListItem"]) {
+			missing = append(missing, fmt.Sprintf("itemListElement[%d].%s", i, m))
+		}
+	}
+	return missing
+}
+
+func hasValue(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(t) != ""
+	case []any:
+		return len(t) > 0
+	}
+	return true
+}
+
+func asList(v any) []any {
+	if l, ok := v.([]any); ok {
+		return l
+	}
+	if v == nil {
+		return nil
+	}
+	return []any{v}
+}
+
+// normalizeType strips the schema.org vocabulary prefix from a type or property IRI
+func normalizeType(t string) string {
+	t = strings.TrimSpace(t)
+	for _, prefix := range []string{"https://schema.org/", "http://schema.org/", "schema:"} {
+		if strings.HasPrefix(t, prefix) {
+			return t[len(prefix):]
+		}
+	}
+	return t
+}
+
+func extractJSONLD(doc *html.Node) ([]Entity, []StructuredDataError) {
+	var entities []Entity
+	var errs []StructuredDataError
+	block := 0
+	walkElements(doc, func(n *html.Node) bool {
+		if n.Data != "script" {
+			return true
+		}
+		typ, _ := nodeAttr(n, "type")
+		if !strings.EqualFold(strings.TrimSpace(typ), "application/ld+json") {
+			return false
+		}
+		var raw strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			raw.WriteString(c.Data)
+		}
+		var v any
+		if err := json.Unmarshal([]byte(raw.String()), &v); err != nil {
+			errs = append(errs, StructuredDataError{Format: FormatJSONLD, Block: block, Message: err.Error()})
+		} else {
+			for _, item := range jsonLDItems(v) {
+				if e, ok := jsonLDEntity(item); ok {
+					entities = append(entities, e)
+				}
+			}
+		}
+		block++
+		return false
+	})
+	return entities, errs
+}
+
+// jsonLDItems flattens top-level arrays and @graph containers
+func jsonLDItems(v any) []map[string]any {
+	switch t := v.(type) {
+	case []any:
+		var out []map[string]any
+		for _, item := range t {
+			out = append(out, jsonLDItems(item)...)
+		}
+		return out
+	case map[string]any:
+		if graph, ok := t["@graph"]; ok {
+			return jsonLDItems(graph)
+		}
+		return []map[string]any{t}
+	}
+	return nil
+}
+
+func jsonLDEntity(m map[string]any) (Entity, bool) {
+	e := Entity{Format: FormatJSONLD, Types: []string{}, Properties: make(map[string]any)}
+	for _, t := range asList(m["@type"]) {
+		if s, ok := t.(string); ok {
+			e.Types = append(e.Types, normalizeType(s))
+		}
+	}
+	if id, ok := m["@id"].(string); ok {
+		e.ID = id
+	}
+	for k, v := range m {
+		if strings.HasPrefix(k, "@") {
+			continue
+		}
+		e.Properties[normalizeType(k)] = jsonLDValue(v)
+	}
+	return e, len(e.Types) > 0 || len(e.Properties) > 0
+}
+
+func jsonLDValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		if val, ok := t["@value"]; ok {
+			return val
+		}
+		if e, ok := jsonLDEntity(t); ok {
+			return e
+		}
+		return nil
+	case []any:
+		out := make([]any, 0, len(t))
+		for _, item := range t {
+			out = append(out, jsonLDValue(item))
+		}
+		return out
+	}
+	return v
+}
+
+func extractMicrodata(doc *html.Node) []Entity {
+	var entities []Entity
+	walkElements(doc, func(n *html.Node) bool {
+		if _, scope := nodeAttr(n, "itemscope"); !scope {
+			return true
+		}
+		if _, prop := nodeAttr(n, "itemprop"); prop {
+			// nested items are handled as property values of their parent
+			return true
+		}
+		entities = append(entities, microdataEntity(n))
+		return false
+	})
+	return entities
+}
+
+func microdataEntity(n *html.Node) Entity {
+	e := Entity{Format: FormatMicrodata, Types: []string{}, Properties: make(map[string]any)}
+	itemType, _ := nodeAttr(n, "itemtype")
+	for _, t := range strings.Fields(itemType) {
+		e.Types = append(e.Types, normalizeType(t))
+	}
+	e.ID, _ = nodeAttr(n, "itemid")
+
+	var collect func(p *html.Node)
+	collect = func(p *html.Node) {
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			_, scope := nodeAttr(c, "itemscope")
+			if props, ok := nodeAttr(c, "itemprop"); ok {
+				var val any
+				if scope {
+					val = microdataEntity(c)
+				} else {
+					val = elementValue(c)
+				}
+				for _, name := range strings.Fields(props) {
+					addProperty(e.Properties, normalizeType(name), val)
+				}
+			}
+			if !scope {
+				collect(c)
+			}
+		}
+	}
+	collect(n)
+	return e
+}
+
+func extractRDFa(doc *html.Node) []Entity {
+	var entities []Entity
+	walkElements(doc, func(n *html.Node) bool {
+		if _, typed := nodeAttr(n, "typeof"); !typed {
+			return true
+		}
+		if _, prop := nodeAttr(n, "property"); prop {
+			return true
+		}
+		entities = append(entities, rdfaEntity(n))
+		return false
+	})
+	return entities
+}
+
+func rdfaEntity(n *html.Node) Entity {
+	e := Entity{Format: FormatRDFa, Types: []string{}, Properties: make(map[string]any)}
+	typeOf, _ := nodeAttr(n, "typeof")
+	for _, t := range strings.Fields(typeOf) {
+		e.Types = append(e.Types, normalizeType(t))
+	}
+	e.ID, _ = nodeAttr(n, "resource")
+
+	var collect func(p *html.Node)
+	collect = func(p *html.Node) {
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			_, typed := nodeAttr(c, "typeof")
+			if props, ok := nodeAttr(c, "property"); ok {
+				var val any
+				if typed {
+					val = rdfaEntity(c)
+				} else if content, ok := nodeAttr(c, "content"); ok {
+					val = content
+				} else {
+					val = elementValue(c)
+				}
+				for _, name := range strings.Fields(props) {
+					addProperty(e.Properties, normalizeType(name), val)
+				}
+			}
+			if !typed {
+				collect(c)
+			}
+		}
+	}
+	collect(n)
+	return e
+}
+
+// elementValue returns the property value of an element per the Microdata rules
+func elementValue(n *html.Node) any {
+	var key string
+	switch n.Data {
+	case "meta":
+		key = "content"
+	case "a", "area", "link":
+		key = "href"
+	case "img", "audio", "embed", "iframe", "source", "track", "video":
+		key = "src"
+	case "object":
+		key = "data"
+	case "data", "meter":
+		key = "value"
+	case "time":
+		key = "datetime"
+	}
+	if key != "" {
+		if v, ok := nodeAttr(n, key); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	if v, ok := nodeAttr(n, "content"); ok {
+		return strings.TrimSpace(v)
+	}
+	return textContent(n)
+}
+
+// addProperty adds a value, turning repeated properties into lists
+func addProperty(props map[string]any, name string, val any) {
+	existing, ok := props[name]
+	if !ok {
+		props[name] = val
+		return
+	}
+	if l, ok := existing.([]any); ok {
+		props[name] = append(l, val)
+		return
+	}
+	props[name] = []any{existing, val}
+}
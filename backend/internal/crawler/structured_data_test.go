@@ -0,0 +1,148 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// crawlFindings crawls page with the analyzer alone and returns its findings. page is as for
+// crawlResult; opts, if given, sets further crawl options.
+func crawlFindings[T any](t *testing.T, page any, analyzer string, opts ...Options) T {
+	t.Helper()
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Analyzers = []string{analyzer}
+	res := crawlResult(t, page, o)
+	out, ok := res.Findings[analyzer].(T)
+	if !ok {
+		t.Fatalf("unexpected %s findings: %#v", analyzer, res.Findings[analyzer])
+	}
+	return out
+}
+
+// crawlResult crawls page with opts. page is the HTML body to serve, an http.Handler or a
+// running *httptest.Server; TLS servers are crawled with their own client, which trusts their
+// certificate.
+func crawlResult(t *testing.T, page any, opts Options) Result {
+	t.Helper()
+	var ts *httptest.Server
+	switch p := page.(type) {
+	case string:
+		ts = serveHTML(t, p)
+	case *httptest.Server:
+		ts = p
+	case http.Handler:
+		ts = httptest.NewServer(p)
+		t.Cleanup(ts.Close)
+	default:
+		t.Fatalf("can't serve %T", page)
+	}
+	client := HTTPClient(5*time.Second, WithoutAddressGuard())
+	if ts.TLS != nil {
+		client = ts.Client()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := New(client).CrawlWithOptions(ctx, ts.URL, opts)
+	if err != nil {
+		t.Fatalf("crawl error: %v", err)
+	}
+	return res
+}
+
+func TestStructuredData_JSONLD(t *testing.T) {
+	out := crawlFindings[StructuredDataFindings](t, `<!doctype html><html><head>
+      <script type="application/ld+json">
+        {"@context": "https://schema.org", "@graph": [
+          {"@type": "Article", "headline": "Hello", "author": {"@type": "Person", "name": "Jane"}, "datePublished": "2024-01-01"},
+          {"@type": "https://schema.org/Organization", "name": "ACME"}
+        ]}
+      </script>
+      <script type="application/ld+json">{"@type": "Product", "name": </script>
+    </head><body></body></html>`, "structured_data")
+
+	if len(out.Entities) != 2 {
+		t.Fatalf("expected 2 entities, got %#v", out.Entities)
+	}
+	article := out.Entities[0]
+	if !reflect.DeepEqual(article.Types, []string{"Article"}) || article.Properties["headline"] != "Hello" {
+		t.Fatalf("unexpected article: %#v", article)
+	}
+	if author, ok := article.Properties["author"].(Entity); !ok || author.Properties["name"] != "Jane" {
+		t.Fatalf("expected nested author entity, got %#v", article.Properties["author"])
+	}
+	if !reflect.DeepEqual(out.Entities[1].Types, []string{"Organization"}) {
+		t.Fatalf("expected normalized Organization type, got %#v", out.Entities[1].Types)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].Block != 1 || out.Errors[0].Format != FormatJSONLD {
+		t.Fatalf("expected parse error for second block, got %#v", out.Errors)
+	}
+	if len(out.Issues) != 1 || out.Issues[0].Type != "Organization" || !reflect.DeepEqual(out.Issues[0].Missing, []string{"url"}) {
+		t.Fatalf("expected missing url on Organization, got %#v", out.Issues)
+	}
+	if !reflect.DeepEqual(out.Eligible, []string{"Article"}) {
+		t.Fatalf("expected Article to be eligible, got %#v", out.Eligible)
+	}
+}
+
+func TestStructuredData_MicrodataProduct(t *testing.T) {
+	out := crawlFindings[StructuredDataFindings](t, `<!doctype html><html><body>
+      <div itemscope itemtype="https://schema.org/Product">
+        <h1 itemprop="name">Widget</h1>
+        <img itemprop="image" src="/w.png">
+        <div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+          <meta itemprop="priceCurrency" content="EUR"><span itemprop="price">9.99</span>
+        </div>
+      </div>
+    </body></html>`, "structured_data")
+
+	if len(out.Entities) != 1 {
+		t.Fatalf("expected 1 entity, got %#v", out.Entities)
+	}
+	p := out.Entities[0]
+	if p.Format != FormatMicrodata || p.Properties["name"] != "Widget" || p.Properties["image"] != "/w.png" {
+		t.Fatalf("unexpected product: %#v", p)
+	}
+	offer, ok := p.Properties["offers"].(Entity)
+	if !ok || offer.Properties["price"] != "9.99" || offer.Properties["priceCurrency"] != "EUR" {
+		t.Fatalf("unexpected offer: %#v", p.Properties["offers"])
+	}
+	if len(out.Issues) != 0 || !reflect.DeepEqual(out.Eligible, []string{"Product"}) {
+		t.Fatalf("expected valid product, got issues %#v eligible %#v", out.Issues, out.Eligible)
+	}
+}
+
+func TestStructuredData_RDFaBreadcrumbs(t *testing.T) {
+	out := crawlFindings[StructuredDataFindings](t, `<!doctype html><html><body>
+      <ol vocab="https://schema.org/" typeof="BreadcrumbList">
+        <li property="itemListElement" typeof="ListItem">
+          <a property="item" href="/books"><span property="name">Books</span></a>
+          <meta property="position" content="1">
+        </li>
+        <li property="itemListElement" typeof="ListItem">
+          <span property="name">Fiction</span>
+        </li>
+      </ol>
+    </body></html>`, "structured_data")
+
+	if len(out.Entities) != 1 || out.Entities[0].Format != FormatRDFa {
+		t.Fatalf("expected 1 RDFa entity, got %#v", out.Entities)
+	}
+	items := asList(out.Entities[0].Properties["itemListElement"])
+	if len(items) != 2 {
+		t.Fatalf("expected 2 list items, got %#v", items)
+	}
+	if len(out.Issues) != 1 || !reflect.DeepEqual(out.Issues[0].Missing, []string{"itemListElement[1].position"}) {
+		t.Fatalf("expected missing position on second crumb, got %#v", out.Issues)
+	}
+	if len(out.Eligible) != 0 {
+		t.Fatalf("expected nothing eligible, got %#v", out.Eligible)
+	}
+}
@@ -0,0 +1,277 @@
+package crawler
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed rules/trackers.json
+var defaultTrackerList []byte
+
+// TrackerList is the data the tracker analyzer classifies pages with
+type TrackerList struct {
+	Trackers        []TrackerDomain  `json:"trackers"`
+	ConsentManagers []ConsentManager `json:"consent_managers"`
+	// TrackingCookies are patterns on names of cookies that are not strictly necessary
+	TrackingCookies []string `json:"tracking_cookies"`
+}
+
+// TrackerDomain matches the domain and all of its subdomains
+type TrackerDomain struct {
+	Domain   string `json:"domain"`
+	Company  string `json:"company"`
+	Category string `json:"category"`
+}
+
+// ConsentManager is recognised by the hosts its scripts are served from or by script URL patterns
+type ConsentManager struct {
+	Name    string   `json:"name"`
+	Domains []string `json:"domains"`
+	Scripts []string `json:"scripts,omitempty"`
+}
+
+// ThirdPartyHost is a third-party host the page loads resources from
+type ThirdPartyHost struct {
+	Host     string   `json:"host"`
+	Requests int      `json:"requests"`
+	Kinds    []string `json:"kinds"`
+	Tracker  bool     `json:"tracker"`
+	Company  string   `json:"company,omitempty"`
+	Category string   `json:"category,omitempty"`
+}
+
+// CookieRecord is a cookie set by the page's response
+type CookieRecord struct {
+	Name     string     `json:"name"`
+	Domain   string     `json:"domain"`
+	Path     string     `json:"path,omitempty"`
+	Expires  *time.Time `json:"expires"` // nil for session cookies
+	Tracking bool       `json:"tracking"`
+}
+
+type TrackerFindings struct {
+	ThirdPartyHosts []ThirdPartyHost `json:"third_party_hosts"`
+	Trackers        int              `json:"trackers"` // number of third-party hosts that are known trackers
+	Cookies         []CookieRecord   `json:"cookies"`
+	ConsentManager  string           `json:"consent_manager,omitempty"`
+	// CookiesWithoutConsent is set when tracking cookies are set and no consent manager is present
+	CookiesWithoutConsent bool `json:"cookies_without_consent"`
+}
+
+type compiledConsentManager struct {
+	name    string
+	domains []string
+	scripts []*regexp.Regexp
+}
+
+// TrackerAnalyzer inventories third-party hosts and cookies, classifying them against a tracker list
+type TrackerAnalyzer struct {
+	trackers        []TrackerDomain
+	consentManagers []compiledConsentManager
+	trackingCookies []*regexp.Regexp
+}
+
+// DefaultTrackerList returns the bundled tracker list
+func DefaultTrackerList() TrackerList {
+	list, err := parseTrackerList(defaultTrackerList)
+	if err != nil {
+		panic(fmt.Sprintf("bundled tracker list is invalid: %v", err))
+	}
+	return list
+}
+
+// LoadTrackerList reads a tracker list from a JSON file in the format of the bundled one
+func LoadTrackerList(path string) (TrackerList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TrackerList{}, err
+	}
+	list, err := parseTrackerList(data)
+	if err != nil {
+		return TrackerList{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
+
+// Extend returns the list with the entries of custom appended; NewTrackerAnalyzer lets them
+// override entries of l for the same domain or consent manager
+func (l TrackerList) Extend(custom TrackerList) TrackerList {
+	return TrackerList{
+		Trackers:        append(slices.Clone(l.Trackers), custom.Trackers...),
+		ConsentManagers: append(slices.Clone(l.ConsentManagers), custom.ConsentManagers...),
+		TrackingCookies: append(slices.Clone(l.TrackingCookies), custom.TrackingCookies...),
+	}
+}
+
+func parseTrackerList(data []byte) (TrackerList, error) {
+	var list TrackerList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return TrackerList{}, fmt.Errorf("invalid tracker list: %w", err)
+	}
+	return list, nil
+}
+
+func defaultTrackerAnalyzer() *TrackerAnalyzer {
+	a, err := NewTrackerAnalyzer(DefaultTrackerList())
+	if err != nil {
+		panic(fmt.Sprintf("bundled tracker list is invalid: %v", err))
+	}
+	return a
+}
+
+// NewTrackerAnalyzer compiles a tracker list. A tracker replaces any earlier one for the same
+// domain and a consent manager any earlier one with the same name, so a custom list extending
+// the defaults can override bundled entries.
+func NewTrackerAnalyzer(list TrackerList) (*TrackerAnalyzer, error) {
+	a := &TrackerAnalyzer{}
+	domains := make(map[string]int, len(list.Trackers))
+	for _, t := range list.Trackers {
+		t.Domain = strings.ToLower(strings.TrimSpace(t.Domain))
+		if t.Domain == "" {
+			return nil, fmt.Errorf("tracker without a domain")
+		}
+		if i, ok := domains[t.Domain]; ok {
+			a.trackers[i] = t
+			continue
+		}
+		domains[t.Domain] = len(a.trackers)
+		a.trackers = append(a.trackers, t)
+	}
+	// the most specific domain wins, e.g. connect.facebook.net over facebook.net
+	sort.SliceStable(a.trackers, func(i, j int) bool { return len(a.trackers[i].Domain) > len(a.trackers[j].Domain) })
+
+	managers := make(map[string]int, len(list.ConsentManagers))
+	for _, cm := range list.ConsentManagers {
+		c := compiledConsentManager{name: cm.Name}
+		for _, d := range cm.Domains {
+			c.domains = append(c.domains, strings.ToLower(strings.TrimSpace(d)))
+		}
+		for _, p := range cm.Scripts {
+			re, err := regexp.Compile("(?i)" + p)
+			if err != nil {
+				return nil, fmt.Errorf("consent manager %s: invalid pattern %q: %w", cm.Name, p, err)
+			}
+			c.scripts = append(c.scripts, re)
+		}
+		if i, ok := managers[cm.Name]; ok {
+			a.consentManagers[i] = c
+			continue
+		}
+		managers[cm.Name] = len(a.consentManagers)
+		a.consentManagers = append(a.consentManagers, c)
+	}
+	for _, p := range list.TrackingCookies {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tracking cookie pattern %q: %w", p, err)
+		}
+		a.trackingCookies = append(a.trackingCookies, re)
+	}
+	return a, nil
+}
+
+func (*TrackerAnalyzer) Name() string { return "trackers" }
+
+func (a *TrackerAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	out := TrackerFindings{ThirdPartyHosts: []ThirdPartyHost{}, Cookies: []CookieRecord{}}
+
+	hosts := make(map[string]*ThirdPartyHost)
+	for _, r := range collectResources(page) {
+		host := strings.ToLower(r.URL.Hostname())
+		if r.Kind == ResourceScript && out.ConsentManager == "" {
+			out.ConsentManager = a.consentManager(host, r.URL.String())
+		}
+		if !isThirdParty(page.URL, r.URL) {
+			continue
+		}
+		h, ok := hosts[host]
+		if !ok {
+			h = &ThirdPartyHost{Host: host, Kinds: []string{}}
+			if t, ok := a.tracker(host); ok {
+				h.Tracker, h.Company, h.Category = true, t.Company, t.Category
+				out.Trackers++
+			}
+			hosts[host] = h
+		}
+		h.Requests++
+		if !slices.Contains(h.Kinds, r.Kind) {
+			h.Kinds = append(h.Kinds, r.Kind)
+		}
+	}
+	for _, h := range hosts {
+		out.ThirdPartyHosts = append(out.ThirdPartyHosts, *h)
+	}
+	sort.Slice(out.ThirdPartyHosts, func(i, j int) bool { return out.ThirdPartyHosts[i].Host < out.ThirdPartyHosts[j].Host })
+
+	tracking := 0
+	now := time.Now()
+	for _, c := range responseCookies(page.Header) {
+		if c.MaxAge < 0 || (c.MaxAge == 0 && !c.Expires.IsZero() && !c.Expires.After(now)) {
+			// the page deletes the cookie rather than setting it
+			continue
+		}
+		rec := CookieRecord{Name: c.Name, Domain: strings.TrimPrefix(c.Domain, "."), Path: c.Path}
+		if rec.Domain == "" {
+			// host-only cookie
+			rec.Domain = page.URL.Hostname()
+		}
+		switch {
+		case c.MaxAge > 0:
+			expires := now.Add(time.Duration(c.MaxAge) * time.Second).UTC()
+			rec.Expires = &expires
+		case c.MaxAge == 0 && !c.Expires.IsZero():
+			expires := c.Expires.UTC()
+			rec.Expires = &expires
+		}
+		for _, re := range a.trackingCookies {
+			if re.MatchString(c.Name) {
+				rec.Tracking = true
+				tracking++
+				break
+			}
+		}
+		out.Cookies = append(out.Cookies, rec)
+	}
+	out.CookiesWithoutConsent = tracking > 0 && out.ConsentManager == ""
+	return out, nil
+}
+
+// tracker looks up the tracker a host belongs to
+func (a *TrackerAnalyzer) tracker(host string) (TrackerDomain, bool) {
+	for _, t := range a.trackers {
+		if matchesDomain(host, t.Domain) {
+			return t, true
+		}
+	}
+	return TrackerDomain{}, false
+}
+
+// consentManager returns the name of the consent manager a script belongs to, if any
+func (a *TrackerAnalyzer) consentManager(host, src string) string {
+	for _, cm := range a.consentManagers {
+		for _, d := range cm.domains {
+			if matchesDomain(host, d) {
+				return cm.name
+			}
+		}
+		for _, re := range cm.scripts {
+			if re.MatchString(src) {
+				return cm.name
+			}
+		}
+	}
+	return ""
+}
+
+// matchesDomain reports whether host is domain or one of its subdomains
+func matchesDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,133 @@
+package crawler
+
+import (
+	"net/http"
+	"testing"
+)
+
+// withCookies serves body as an HTML page that sets cookies
+func withCookies(body string, cookies ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, c := range cookies {
+			w.Header().Add("Set-Cookie", c)
+		}
+		htmlHandler(body)(w, r)
+	}
+}
+
+const trackerPage = `<!doctype html><html><head>
+  <script src="https://www.googletagmanager.com/gtag/js?id=G-1"></script>
+  <script src="https://connect.facebook.net/en_US/fbevents.js"></script>
+  <script src="/app.js"></script>
+</head><body>
+  <img src="https://www.facebook.com/tr?id=1&ev=PageView">
+  <img src="https://cdn.example-images.com/a.png"><img src="https://cdn.example-images.com/b.png">
+  <iframe src="https://www.youtube.com/embed/x"></iframe>
+</body></html>`
+
+func TestTrackers_ClassifiesThirdPartyHostsAndCookies(t *testing.T) {
+	out := crawlFindings[TrackerFindings](t, withCookies(trackerPage,
+		"_ga=GA1.1.1; Max-Age=63072000; Domain=.127.0.0.1; Path=/",
+		"session=abc; HttpOnly",
+		"_fbp=fb.1.1; Expires=Wed, 01 Jan 2031 00:00:00 GMT",
+	), "trackers")
+
+	hosts := map[string]ThirdPartyHost{}
+	for _, h := range out.ThirdPartyHosts {
+		hosts[h.Host] = h
+	}
+	if len(hosts) != 5 || out.Trackers != 3 {
+		t.Fatalf("expected 5 third-party hosts with 3 trackers, got %d/%d: %+v", len(hosts), out.Trackers, out.ThirdPartyHosts)
+	}
+	if h := hosts["connect.facebook.net"]; !h.Tracker || h.Category != "advertising" || h.Company != "Meta" {
+		t.Errorf("unexpected facebook pixel host: %+v", h)
+	}
+	if h := hosts["www.facebook.com"]; !h.Tracker || h.Category != "social" || h.Kinds[0] != ResourceImage {
+		t.Errorf("unexpected facebook host: %+v", h)
+	}
+	if h := hosts["cdn.example-images.com"]; h.Tracker || h.Requests != 2 {
+		t.Errorf("unexpected image CDN host: %+v", h)
+	}
+
+	if len(out.Cookies) != 3 {
+		t.Fatalf("expected 3 cookies, got %+v", out.Cookies)
+	}
+	ga, session, fbp := out.Cookies[0], out.Cookies[1], out.Cookies[2]
+	if !ga.Tracking || ga.Expires == nil || ga.Domain != "127.0.0.1" || ga.Path != "/" {
+		t.Errorf("unexpected _ga cookie: %+v", ga)
+	}
+	if session.Tracking || session.Expires != nil || session.Domain != "127.0.0.1" {
+		t.Errorf("unexpected session cookie: %+v", session)
+	}
+	if !fbp.Tracking || fbp.Expires == nil || fbp.Expires.Year() != 2031 {
+		t.Errorf("unexpected _fbp cookie: %+v", fbp)
+	}
+	if out.ConsentManager != "" || !out.CookiesWithoutConsent {
+		t.Errorf("expected tracking cookies without consent to be flagged, got %+v", out)
+	}
+}
+
+func TestTrackers_ConsentManagerPresent(t *testing.T) {
+	out := crawlFindings[TrackerFindings](t, withCookies(`<html><head>
+      <script src="https://cdn.cookielaw.org/scripttemplates/otSDKStub.js"></script>
+    </head></html>`, "_ga=GA1.1.1"), "trackers")
+
+	if out.ConsentManager != "OneTrust" || out.CookiesWithoutConsent {
+		t.Fatalf("expected OneTrust without flag, got %+v", out)
+	}
+}
+
+func TestTrackers_EssentialCookiesOnly(t *testing.T) {
+	out := crawlFindings[TrackerFindings](t, withCookies(trackerPage, "session=abc"), "trackers")
+
+	if out.CookiesWithoutConsent {
+		t.Fatal("essential cookies must not be flagged")
+	}
+}
+
+func TestTrackers_SkipsDeletedCookiesAndIgnoresCase(t *testing.T) {
+	out := crawlFindings[TrackerFindings](t, withCookies(`<html></html>`,
+		"_GA=GA1.1.1; Max-Age=3600",
+		"_fbp=; Max-Age=0",
+		"_gid=; Expires=Thu, 01 Jan 1970 00:00:00 GMT",
+	), "trackers")
+
+	if len(out.Cookies) != 1 || out.Cookies[0].Name != "_GA" || !out.Cookies[0].Tracking {
+		t.Fatalf("expected only the _GA tracking cookie, got %+v", out.Cookies)
+	}
+}
+
+func TestTrackerList_ExtendOverridesBundledEntries(t *testing.T) {
+	custom := TrackerList{
+		Trackers:        []TrackerDomain{{Domain: "Hotjar.com", Company: "Contentsquare", Category: "analytics"}, {Domain: "tracker.example", Category: "analytics"}},
+		ConsentManagers: []ConsentManager{{Name: "OneTrust", Domains: []string{"consent.example"}}},
+	}
+	a, err := NewTrackerAnalyzer(DefaultTrackerList().Extend(custom))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tr, ok := a.tracker("static.hotjar.com"); !ok || tr.Company != "Contentsquare" {
+		t.Errorf("expected the custom hotjar entry, got %+v", tr)
+	}
+	if _, ok := a.tracker("tracker.example"); !ok {
+		t.Error("expected the custom tracker to be added")
+	}
+	if a.consentManager("cdn.cookielaw.org", "https://cdn.cookielaw.org/x.js") != "" {
+		t.Error("expected the custom OneTrust entry to replace the bundled one")
+	}
+	if a.consentManager("consent.example", "https://consent.example/x.js") != "OneTrust" {
+		t.Error("expected the custom OneTrust domain")
+	}
+	if n := len(a.trackers); n != len(DefaultTrackerList().Trackers)+1 {
+		t.Errorf("expected one tracker more than the bundled list, got %d", n)
+	}
+}
+
+func TestNewTrackerAnalyzer_InvalidList(t *testing.T) {
+	if _, err := NewTrackerAnalyzer(TrackerList{Trackers: []TrackerDomain{{Domain: " "}}}); err == nil {
+		t.Error("expected error for empty domain")
+	}
+	if _, err := NewTrackerAnalyzer(TrackerList{TrackingCookies: []string{"("}}); err == nil {
+		t.Error("expected error for invalid cookie pattern")
+	}
+}
@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Validation issue kinds
+const (
+	IssueMissingDoctype = "missing_doctype"
+	IssueUnknownDoctype = "unknown_doctype"
+	IssueUnclosed       = "unclosed"
+	IssueMisnested      = "misnested"
+	IssueStrayEndTag    = "stray_end_tag"
+	IssueDuplicate      = "duplicate"
+	IssueObsolete       = "obsolete"
+	IssueSelfClosing    = "self_closing_non_void"
+)
+
+// maxValidationIssues bounds how many issues are listed; all of them are counted
+const maxValidationIssues = 100
+
+type ValidationIssue struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"` // error or warning
+	Element  string `json:"element,omitempty"`
+	Message  string `json:"message"`
+}
+
+type ValidationFindings struct {
+	Doctype  string            `json:"doctype"` // declared version; empty when missing or unknown
+	Mode     string            `json:"mode"`
+	Valid    bool              `json:"valid"` // no errors; warnings are allowed
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Issues   []ValidationIssue `json:"issues"`
+}
+
+// voidElements never have content or an end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+	"param": true, "keygen": true, "basefont": true, "bgsound": true, "frame": true,
+}
+
+// optionalEndTags are elements whose end tag may be omitted
+var optionalEndTags = map[string]bool{
+	"html": true, "head": true, "body": true, "p": true, "li": true, "dt": true, "dd": true,
+	"option": true, "optgroup": true, "tr": true, "td": true, "th": true, "thead": true,
+	"tbody": true, "tfoot": true, "colgroup": true, "caption": true, "rt": true, "rp": true,
+}
+
+// uniqueElements may appear only once per document
+var uniqueElements = map[string]bool{"html": true, "head": true, "body": true, "title": true}
+
+// obsoleteElements are elements the HTML Standard lists as obsolete
+var obsoleteElements = map[string]bool{
+	"acronym": true, "applet": true, "basefont": true, "bgsound": true, "big": true, "blink": true,
+	"center": true, "dir": true, "font": true, "frame": true, "frameset": true, "isindex": true,
+	"keygen": true, "listing": true, "marquee": true, "menuitem": true, "multicol": true,
+	"nextid": true, "nobr": true, "noembed": true, "noframes": true, "plaintext": true,
+	"rb": true, "rtc": true, "spacer": true, "strike": true, "tt": true, "xmp": true,
+}
+
+// ValidationAnalyzer checks the document's doctype and tag structure. It works on the token
+// stream rather than the parsed tree, since the parser silently repairs the problems it looks for.
+type ValidationAnalyzer struct{}
+
+func (ValidationAnalyzer) Name() string { return "validation" }
+
+func (ValidationAnalyzer) Analyze(_ context.Context, page *Page) (any, error) {
+	out := ValidationFindings{Issues: []ValidationIssue{}}
+	add := func(kind, severity, element, message string) {
+		if severity == "error" {
+			out.Errors++
+		} else {
+			out.Warnings++
+		}
+		if len(out.Issues) < maxValidationIssues {
+			out.Issues = append(out.Issues, ValidationIssue{Kind: kind, Severity: severity, Element: element, Message: message})
+		}
+	}
+
+	dt := documentDoctype(page.Tokens)
+	out.Mode = documentMode(dt)
+	switch {
+	case dt == nil:
+		add(IssueMissingDoctype, "warning", "", "document has no doctype and renders in quirks mode")
+	default:
+		out.Doctype = dt.Version()
+		if out.Doctype == "" {
+			add(IssueUnknownDoctype, "warning", "", fmt.Sprintf("unrecognised doctype (renders in %s mode)", out.Mode))
+		}
+	}
+
+	var stack []string
+	seen := make(map[string]int)
+	foreign := 0 // depth inside <svg> or <math>, where XML rules apply
+	for _, t := range page.Tokens {
+		name := strings.ToLower(t.Data)
+		switch t.Type {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if foreign == 0 {
+				if uniqueElements[name] {
+					seen[name]++
+					if seen[name] == 2 {
+						add(IssueDuplicate, "error", name, fmt.Sprintf("<%s> appears more than once", name))
+					}
+				}
+				if obsoleteElements[name] {
+					add(IssueObsolete, "warning", name, fmt.Sprintf("<%s> is obsolete", name))
+				}
+			}
+			if voidElements[name] && foreign == 0 {
+				continue
+			}
+			if t.Type == html.SelfClosingTagToken {
+				// <svg/> and <math/> start foreign content, where the slash closes the element
+				if foreign > 0 || name == "svg" || name == "math" {
+					continue
+				}
+				add(IssueSelfClosing, "warning", name, fmt.Sprintf("<%s/> is not self-closing in HTML; the element stays open", name))
+			}
+			if name == "svg" || name == "math" || foreign > 0 {
+				foreign++
+			}
+			stack = append(stack, name)
+		case html.EndTagToken:
+			if voidElements[name] && foreign == 0 {
+				if name != "br" {
+					add(IssueStrayEndTag, "error", name, fmt.Sprintf("</%s> closes a void element", name))
+				}
+				continue
+			}
+			i := len(stack) - 1
+			for i >= 0 && stack[i] != name {
+				i--
+			}
+			if i < 0 {
+				add(IssueStrayEndTag, "error", name, fmt.Sprintf("</%s> has no open <%s>", name, name))
+				continue
+			}
+			// everything opened after the element is closed implicitly
+			for _, open := range stack[i+1:] {
+				switch {
+				case optionalEndTags[open]:
+				case name == "body" || name == "html":
+					add(IssueUnclosed, "error", open, fmt.Sprintf("<%s> is never closed", open))
+				default:
+					add(IssueMisnested, "error", open, fmt.Sprintf("<%s> is still open when </%s> closes its parent", open, name))
+				}
+			}
+			if foreign > 0 {
+				foreign = max(foreign-(len(stack)-i), 0)
+			}
+			stack = stack[:i]
+		}
+	}
+	for _, open := range stack {
+		if !optionalEndTags[open] {
+			add(IssueUnclosed, "error", open, fmt.Sprintf("<%s> is never closed", open))
+		}
+	}
+	out.Valid = out.Errors == 0
+	return out, nil
+}
@@ -0,0 +1,114 @@
+package crawler
+
+import (
+	"testing"
+)
+
+func TestDoctype_VersionAndMode(t *testing.T) {
+	cases := []struct {
+		doctype string
+		version string
+		mode    string
+	}{
+		{`<!DOCTYPE html>`, "HTML5", ModeNoQuirks},
+		{`<!doctype HTML SYSTEM "about:legacy-compat">`, "HTML5", ModeNoQuirks},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`, "HTML 4.01 Strict", ModeNoQuirks},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`, "HTML 4.01 Transitional", ModeLimitedQuirks},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN">`, "HTML 4.01 Transitional", ModeQuirks},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN" "http://www.w3.org/TR/html4/frameset.dtd">`, "HTML 4.01 Frameset", ModeLimitedQuirks},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN"
+			"http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`, "XHTML 1.0 Strict", ModeNoQuirks},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">`, "XHTML 1.0 Transitional", ModeLimitedQuirks},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Frameset//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-frameset.dtd">`, "XHTML 1.0 Frameset", ModeLimitedQuirks},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd">`, "XHTML 1.1", ModeNoQuirks},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`, "HTML 3.2", ModeQuirks},
+		{`<!DOCTYPE html PUBLIC "-//Example//DTD Something//EN">`, "", ModeNoQuirks},
+		{`<!DOCTYPE svg>`, "", ModeQuirks},
+		{`<!DOCTYPE>`, "", ModeQuirks},
+	}
+	for _, tc := range cases {
+		d := documentDoctype(tokenize([]byte(tc.doctype + `<html><body></body></html>`)))
+		if d == nil {
+			t.Fatalf("%s: doctype not found", tc.doctype)
+		}
+		if got := d.Version(); got != tc.version {
+			t.Errorf("%s: expected version %q, got %q", tc.doctype, tc.version, got)
+		}
+		if got := documentMode(d); got != tc.mode {
+			t.Errorf("%s: expected mode %s, got %s", tc.doctype, tc.mode, got)
+		}
+	}
+}
+
+func TestCrawl_MissingDoctypeIsQuirks(t *testing.T) {
+	res := crawlResult(t, `<html><head><title>t</title></head><body><p>x</p></body></html>`, Options{Analyzers: []string{"doctype"}})
+	if res.HTMLVersion != nil {
+		t.Errorf("expected no HTML version without a doctype, got %q", *res.HTMLVersion)
+	}
+	if res.DocumentMode != ModeQuirks {
+		t.Errorf("expected quirks mode, got %s", res.DocumentMode)
+	}
+}
+
+func TestCrawl_DoctypeAfterContentIsIgnored(t *testing.T) {
+	res := crawlResult(t, `<p>early</p><!DOCTYPE html><html><body></body></html>`, Options{Analyzers: []string{"doctype"}})
+	if res.HTMLVersion != nil || res.DocumentMode != ModeQuirks {
+		t.Errorf("expected the late doctype to be ignored, got %v / %s", res.HTMLVersion, res.DocumentMode)
+	}
+}
+
+func TestValidation_ValidDocument(t *testing.T) {
+	out := crawlFindings[ValidationFindings](t, `<!doctype html><html><head><title>ok</title>
+		<meta charset="utf-8"><link rel="stylesheet" href="a.css"></head>
+		<body><ul><li>one<li>two</ul><p>para<p>other <br/>
+		<svg><circle r="1"/><path d="M0 0"/></svg>
+		<span><svg/><math/>empty foreign roots</span>
+		<table><tr><td>a<td>b</table></body></html>`, "validation")
+
+	if !out.Valid || out.Errors != 0 || out.Warnings != 0 {
+		t.Errorf("expected a valid document, got %+v", out)
+	}
+	if out.Doctype != "HTML5" || out.Mode != ModeNoQuirks {
+		t.Errorf("unexpected doctype %q / mode %s", out.Doctype, out.Mode)
+	}
+}
+
+func TestValidation_Issues(t *testing.T) {
+	out := crawlFindings[ValidationFindings](t, `<html><head><title>a</title><title>b</title></head>
+		<body><div><span>text</div>
+		<center><font>old</font></center>
+		<div/>
+		</em>
+		<section>never closed
+		</body></html>`, "validation")
+
+	if out.Valid {
+		t.Fatal("expected the document to be invalid")
+	}
+	if out.Mode != ModeQuirks {
+		t.Errorf("expected quirks mode, got %s", out.Mode)
+	}
+	want := map[string]string{
+		IssueMissingDoctype: "",
+		IssueDuplicate:      "title",
+		IssueMisnested:      "span",
+		IssueObsolete:       "center",
+		IssueSelfClosing:    "div",
+		IssueStrayEndTag:    "em",
+		IssueUnclosed:       "section",
+	}
+	for kind, element := range want {
+		found := false
+		for _, issue := range out.Issues {
+			if issue.Kind == kind && issue.Element == element {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a %s issue on %q, got %+v", kind, element, out.Issues)
+		}
+	}
+	if out.Warnings != 4 { // missing doctype, <center>, <font>, <div/>
+		t.Errorf("expected 4 warnings, got %d", out.Warnings)
+	}
+}
@@ -14,9 +14,9 @@ type JobRepository struct {
 	mock.Mock
 }
 
-// Enqueue provides a mock function with given fields: ctx, urlID
-func (_m *JobRepository) Enqueue(ctx context.Context, urlID int64) (*models.CrawlJob, error) {
-	ret := _m.Called(ctx, urlID)
+// Enqueue provides a mock function with given fields: ctx, urlID, opts
+func (_m *JobRepository) Enqueue(ctx context.Context, urlID int64, opts models.JobOptions) (*models.CrawlJob, error) {
+	ret := _m.Called(ctx, urlID, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Enqueue")
@@ -24,19 +24,19 @@ func (_m *JobRepository) Enqueue(ctx context.Context, urlID int64) (*models.Craw
 
 	var r0 *models.CrawlJob
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) (*models.CrawlJob, error)); ok {
-		return rf(ctx, urlID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, models.JobOptions) (*models.CrawlJob, error)); ok {
+		return rf(ctx, urlID, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.CrawlJob); ok {
-		r0 = rf(ctx, urlID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, models.JobOptions) *models.CrawlJob); ok {
+		r0 = rf(ctx, urlID, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.CrawlJob)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, urlID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, models.JobOptions) error); ok {
+		r1 = rf(ctx, urlID, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
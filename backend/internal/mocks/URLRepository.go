@@ -74,9 +74,9 @@ func (_m *URLRepository) GetByID(ctx context.Context, id int64) (*models.URL, er
 	return r0, r1
 }
 
-// List provides a mock function with given fields: ctx, page, limit, sortBy, order
-func (_m *URLRepository) List(ctx context.Context, page int, limit int, sortBy string, order string) ([]models.URL, int64, error) {
-	ret := _m.Called(ctx, page, limit, sortBy, order)
+// List provides a mock function with given fields: ctx, page, limit, sortBy, order, filter
+func (_m *URLRepository) List(ctx context.Context, page int, limit int, sortBy string, order string, filter models.URLFilter) ([]models.URL, int64, error) {
+	ret := _m.Called(ctx, page, limit, sortBy, order, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
@@ -85,25 +85,25 @@ func (_m *URLRepository) List(ctx context.Context, page int, limit int, sortBy s
 	var r0 []models.URL
 	var r1 int64
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, string) ([]models.URL, int64, error)); ok {
-		return rf(ctx, page, limit, sortBy, order)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, string, models.URLFilter) ([]models.URL, int64, error)); ok {
+		return rf(ctx, page, limit, sortBy, order, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, string) []models.URL); ok {
-		r0 = rf(ctx, page, limit, sortBy, order)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, string, models.URLFilter) []models.URL); ok {
+		r0 = rf(ctx, page, limit, sortBy, order, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.URL)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, string, string) int64); ok {
-		r1 = rf(ctx, page, limit, sortBy, order)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, string, string, models.URLFilter) int64); ok {
+		r1 = rf(ctx, page, limit, sortBy, order, filter)
 	} else {
 		r1 = ret.Get(1).(int64)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int, string, string) error); ok {
-		r2 = rf(ctx, page, limit, sortBy, order)
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, string, string, models.URLFilter) error); ok {
+		r2 = rf(ctx, page, limit, sortBy, order, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -111,6 +111,36 @@ func (_m *URLRepository) List(ctx context.Context, page int, limit int, sortBy s
 	return r0, r1, r2
 }
 
+// ListByTag provides a mock function with given fields: ctx, tag
+func (_m *URLRepository) ListByTag(ctx context.Context, tag string) ([]models.URL, error) {
+	ret := _m.Called(ctx, tag)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByTag")
+	}
+
+	var r0 []models.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.URL, error)); ok {
+		return rf(ctx, tag)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.URL); ok {
+		r0 = rf(ctx, tag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tag)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewURLRepository creates a new instance of URLRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewURLRepository(t interface {
@@ -5,8 +5,9 @@ type CreateURLRequest struct {
 }
 
 type URLResponse struct {
-	ID  int64  `json:"id"`
-	URL string `json:"url"`
+	ID   int64    `json:"id"`
+	URL  string   `json:"url"`
+	Tags []string `json:"tags"`
 }
 
 type URLListResponse struct {
@@ -16,22 +17,192 @@ type URLListResponse struct {
 	Limit int           `json:"limit"`
 }
 
+// TagURLsRequest assigns or removes tags for a set of URLs
+type TagURLsRequest struct {
+	Tags   []string `json:"tags" binding:"required"`
+	URLIDs []int64  `json:"url_ids" binding:"required"`
+}
+
+type TagResponse struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	URLCount int64  `json:"url_count"`
+}
+
+// CreateExtractionRuleRequest defines a rule for a single URL (url_id) or for all URLs with a tag
+type CreateExtractionRuleRequest struct {
+	Name         string  `json:"name" binding:"required"`
+	URLID        *int64  `json:"url_id"`
+	Tag          *string `json:"tag"`
+	SelectorType string  `json:"selector_type" binding:"required,oneof=css xpath"`
+	Selector     string  `json:"selector" binding:"required"`
+	Attribute    *string `json:"attribute"`
+	Multiple     bool    `json:"multiple"`
+	Regex        *string `json:"regex"`
+}
+
+type ExtractionRuleResponse struct {
+	ID           int64   `json:"id"`
+	Name         string  `json:"name"`
+	URLID        *int64  `json:"url_id"`
+	Tag          *string `json:"tag"`
+	SelectorType string  `json:"selector_type"`
+	Selector     string  `json:"selector"`
+	Attribute    *string `json:"attribute"`
+	Multiple     bool    `json:"multiple"`
+	Regex        *string `json:"regex"`
+}
+
+// CreateRequestProfileRequest defines a request profile for a single URL (url_id) or for all
+// URLs with a tag. Password and token are only used by the matching auth_type.
+type CreateRequestProfileRequest struct {
+	Name           string            `json:"name" binding:"required"`
+	URLID          *int64            `json:"url_id"`
+	Tag            *string           `json:"tag"`
+	UserAgent      *string           `json:"user_agent"`
+	AcceptLanguage *string           `json:"accept_language"`
+	Headers        map[string]string `json:"headers"`
+	Cookies        map[string]string `json:"cookies"`
+	AuthType       string            `json:"auth_type" binding:"omitempty,oneof=none basic bearer"`
+	Username       *string           `json:"username"`
+	Password       *string           `json:"password"`
+	Token          *string           `json:"token"`
+	ApplyToLinks   bool              `json:"apply_to_links"`
+}
+
+// RequestProfileResponse leaves out secrets: headers and cookies are listed by name only
+type RequestProfileResponse struct {
+	ID             int64    `json:"id"`
+	Name           string   `json:"name"`
+	URLID          *int64   `json:"url_id"`
+	Tag            *string  `json:"tag"`
+	UserAgent      *string  `json:"user_agent"`
+	AcceptLanguage *string  `json:"accept_language"`
+	Headers        []string `json:"headers"`
+	Cookies        []string `json:"cookies"`
+	AuthType       string   `json:"auth_type"`
+	Username       *string  `json:"username"`
+	HasPassword    bool     `json:"has_password"`
+	HasToken       bool     `json:"has_token"`
+	ApplyToLinks   bool     `json:"apply_to_links"`
+}
+
+// CreateLoginRecipeRequest describes a login form to submit before crawling. Fields maps form
+// field names to the values to submit.
+type CreateLoginRecipeRequest struct {
+	Name          string            `json:"name" binding:"required"`
+	LoginURL      string            `json:"login_url" binding:"required"`
+	Fields        map[string]string `json:"fields" binding:"required"`
+	SuccessCookie *string           `json:"success_cookie"`
+	SuccessURL    *string           `json:"success_url"`
+}
+
+// LoginRecipeResponse lists the field names of a recipe but never their values
+type LoginRecipeResponse struct {
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	LoginURL      string   `json:"login_url"`
+	Fields        []string `json:"fields"`
+	SuccessCookie *string  `json:"success_cookie"`
+	SuccessURL    *string  `json:"success_url"`
+}
+
+// AssignLoginRecipeRequest sets the recipe a URL logs in with; null removes it
+type AssignLoginRecipeRequest struct {
+	RecipeID *int64 `json:"recipe_id"`
+}
+
+// CreateProxyPoolRequest defines a proxy pool for a single URL (url_id) or for all URLs with a
+// tag. Proxies are http, https, socks5 or socks5h URLs and may carry credentials.
+type CreateProxyPoolRequest struct {
+	Name    string   `json:"name" binding:"required"`
+	URLID   *int64   `json:"url_id"`
+	Tag     *string  `json:"tag"`
+	Proxies []string `json:"proxies" binding:"required"`
+}
+
+type ProxyPoolResponse struct {
+	ID      int64         `json:"id"`
+	Name    string        `json:"name"`
+	URLID   *int64        `json:"url_id"`
+	Tag     *string       `json:"tag"`
+	Proxies []ProxyHealth `json:"proxies"`
+}
+
+// ProxyHealth is the health of one proxy since the server started; credentials are left out
+type ProxyHealth struct {
+	Proxy               string  `json:"proxy"`
+	Healthy             bool    `json:"healthy"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	LastError           *string `json:"last_error"`
+	LastUsed            *string `json:"last_used"`
+	RetryAfter          *string `json:"retry_after"` // when a benched proxy is tried again
+	Successes           int64   `json:"successes"`
+	Errors              int64   `json:"errors"`
+}
+
+type CertificateResponse struct {
+	URLID           int64    `json:"url_id"`
+	URL             string   `json:"url"`
+	Host            string   `json:"host"`
+	Subject         string   `json:"subject"`
+	Issuer          string   `json:"issuer"`
+	SANs            []string `json:"sans"`
+	NotBefore       string   `json:"not_before"`
+	NotAfter        string   `json:"not_after"`
+	DaysToExpiry    int      `json:"days_to_expiry"`
+	ChainValid      bool     `json:"chain_valid"`
+	HostnameMatch   bool     `json:"hostname_match"`
+	ValidationError *string  `json:"validation_error"`
+	CheckedAt       string   `json:"checked_at"`
+}
+
+type CertificateAlertResponse struct {
+	ID        int64                `json:"id"`
+	URLID     int64                `json:"url_id"`
+	URL       string               `json:"url"`
+	Kind      CertificateAlertKind `json:"kind"`
+	Message   string               `json:"message"`
+	NotAfter  string               `json:"not_after"`
+	CreatedAt string               `json:"created_at"`
+}
+
 // ResultResponse represents the API response model for a crawl result
 type ResultResponse struct {
-	ID                     int64   `json:"id"`
-	URLID                  int64   `json:"url_id"`
-	HTMLVersion            *string `json:"html_version"`
-	Title                  *string `json:"title"`
-	HeadingsH1             int     `json:"headings_h1"`
-	HeadingsH2             int     `json:"headings_h2"`
-	HeadingsH3             int     `json:"headings_h3"`
-	HeadingsH4             int     `json:"headings_h4"`
-	HeadingsH5             int     `json:"headings_h5"`
-	HeadingsH6             int     `json:"headings_h6"`
-	InternalLinksCount     int     `json:"internal_links_count"`
-	ExternalLinksCount     int     `json:"external_links_count"`
-	InaccessibleLinksCount int     `json:"inaccessible_links_count"`
-	HasLoginForm           bool    `json:"has_login_form"`
+	ID                     int64    `json:"id"`
+	URLID                  int64    `json:"url_id"`
+	Outcome                string   `json:"outcome"` // ok, truncated, parse_timeout, not_html or not_modified
+	ContentType            *string  `json:"content_type"`
+	BodyBytes              int64    `json:"body_bytes"`
+	Truncated              bool     `json:"truncated"`
+	Proxy                  *string  `json:"proxy"`
+	ETag                   *string  `json:"etag"`
+	LastModified           *string  `json:"last_modified"`
+	PreviousResultID       *int64   `json:"previous_result_id"` // the result a not_modified result repeats
+	HTMLVersion            *string  `json:"html_version"`       // nil without a recognised doctype
+	DocumentMode           *string  `json:"document_mode"`
+	Charset                *string  `json:"charset"`
+	CharsetSource          *string  `json:"charset_source"`   // bom, header, meta, sniffed or default
+	CharsetMismatch        bool     `json:"charset_mismatch"` // header and <meta> declare different encodings
+	ContentHash            *string  `json:"content_hash"`
+	SimHash                *string  `json:"simhash"`    // hex, as it doesn't fit a JavaScript number
+	Changed                *bool    `json:"changed"`    // visible text differs from the previous crawl; nil on the first
+	Similarity             *float64 `json:"similarity"` // 0..1, simhash similarity to the previous crawl
+	Title                  *string  `json:"title"`
+	HeadingsH1             int      `json:"headings_h1"`
+	HeadingsH2             int      `json:"headings_h2"`
+	HeadingsH3             int      `json:"headings_h3"`
+	HeadingsH4             int      `json:"headings_h4"`
+	HeadingsH5             int      `json:"headings_h5"`
+	HeadingsH6             int      `json:"headings_h6"`
+	InternalLinksCount     int      `json:"internal_links_count"`
+	ExternalLinksCount     int      `json:"external_links_count"`
+	InaccessibleLinksCount int      `json:"inaccessible_links_count"`
+	LinkErrors             JSON     `json:"link_errors"` // failed links by error class, e.g. {"dns": 2, "http_status": 1}
+	HasLoginForm           bool     `json:"has_login_form"`
+	AccessibilityScore     *int     `json:"accessibility_score"` // nil when the accessibility analyzer didn't run
+	SecurityGrade          *string  `json:"security_grade"`      // nil when the security analyzer didn't run
+	Findings               JSON     `json:"findings"`
 }
 
 // Jobs API response types
@@ -56,3 +227,19 @@ type JobsStoppedItem struct {
 	JobID int64 `json:"job_id"`
 }
 type JobsStoppedResponse []JobsStoppedItem
+
+// MetricsResponse reports runtime counters of the crawler
+type MetricsResponse struct {
+	LinkCache *LinkCacheMetrics `json:"link_cache"` // nil when link checks aren't cached
+}
+
+// LinkCacheMetrics counts how link checks were answered since the server started
+type LinkCacheMetrics struct {
+	Hits         int64   `json:"hits"`         // answered from memory
+	StoreHits    int64   `json:"store_hits"`   // answered from the database
+	Misses       int64   `json:"misses"`       // checked over the network
+	Deduplicated int64   `json:"deduplicated"` // joined a check of the same link already running
+	StoreErrors  int64   `json:"store_errors"`
+	Entries      int     `json:"entries"`
+	HitRatio     float64 `json:"hit_ratio"`
+}
@@ -12,10 +12,26 @@ const (
 	JobStopped   CrawlJobStatus = "stopped" // stopped by the user
 )
 
+// JobOptions are per-job crawl settings chosen when the job is started
+type JobOptions struct {
+	// Analyzers to run by name; nil runs every registered analyzer
+	Analyzers []string `json:"analyzers"`
+	// FetchResources sizes every sub-resource of the page to measure its weight
+	FetchResources bool `json:"fetch_resources,omitempty"`
+	// Refetch downloads the page in full even if it is unchanged since the last crawl, so its
+	// links are checked again
+	Refetch bool `json:"refetch,omitempty"`
+	// RecheckLinks checks the links of the last crawl again when the page is unchanged since
+	RecheckLinks bool `json:"recheck_links,omitempty"`
+	// ArchiveLinks adds the page's link checks to its WARC records
+	ArchiveLinks bool `json:"archive_links,omitempty"`
+}
+
 type CrawlJob struct {
 	ID          int64          `db:"id"`
 	URLID       int64          `db:"url_id"`
 	Status      CrawlJobStatus `db:"status"`
+	Options     JSON           `db:"options"` // JobOptions the job was started with
 	StartedAt   *time.Time     `db:"started_at"`
 	CompletedAt *time.Time     `db:"completed_at"`
 	Error       *string        `db:"error_message"`
@@ -28,13 +44,57 @@ type URL struct {
 	URL       string    `db:"url"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+	Tags      []string  `db:"-"` // loaded separately from url_tags
+}
+
+type Tag struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name"`
+	URLCount  int64     `db:"url_count"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// URLFilter narrows down URL listings
+type URLFilter struct {
+	Tag string // only URLs carrying this tag (empty = no filter)
+	// ChangedWithin keeps URLs whose visible content changed within a window such as "24h" or
+	// "7d"; the service turns it into ChangedSince
+	ChangedWithin string
+	ChangedSince  *time.Time
+}
+
+// ArchiveFilter selects the results a WARC export covers. From and To are dates (2006-01-02)
+// or RFC 3339 times; the service turns them into the From and To times, To being exclusive.
+type ArchiveFilter struct {
+	JobID    *int64
+	FromDate string
+	ToDate   string
+	From     *time.Time
+	To       *time.Time
 }
 
 type CrawlResult struct {
 	ID                     int64     `db:"id"`
 	JobID                  int64     `db:"job_id"`
 	URLID                  int64     `db:"url_id"`
+	Outcome                string    `db:"outcome"`
+	ContentType            *string   `db:"content_type"`
+	BodyBytes              int64     `db:"body_bytes"`
+	Truncated              bool      `db:"truncated"`
+	Proxy                  *string   `db:"proxy"` // proxy the page was fetched through, without credentials
+	ETag                   *string   `db:"etag"`
+	LastModified           *string   `db:"last_modified"`
+	PreviousResultID       *int64    `db:"previous_result_id"` // set on not_modified results, which repeat it
+	InputsHash             *string   `db:"inputs_hash"`        // fingerprint of the rules, profile and login recipe crawled with
 	HTMLVersion            *string   `db:"html_version"`
+	DocumentMode           *string   `db:"document_mode"`
+	Charset                *string   `db:"charset"`
+	CharsetSource          *string   `db:"charset_source"`
+	CharsetMismatch        bool      `db:"charset_mismatch"`
+	ContentHash            *string   `db:"content_hash"`    // SHA-256 of the visible text
+	SimHash                *uint64   `db:"simhash"`         // near-duplicate fingerprint of the visible text
+	ContentChanged         *bool     `db:"content_changed"` // nil on a URL's first crawl
+	Similarity             *float64  `db:"similarity"`      // simhash similarity to the previous result
 	Title                  *string   `db:"title"`
 	HeadingsH1             int       `db:"headings_h1"`
 	HeadingsH2             int       `db:"headings_h2"`
@@ -45,6 +105,124 @@ type CrawlResult struct {
 	InternalLinksCount     int       `db:"internal_links_count"`
 	ExternalLinksCount     int       `db:"external_links_count"`
 	InaccessibleLinksCount int       `db:"inaccessible_links_count"`
+	LinkErrors             JSON      `db:"link_errors"` // failed links counted by error class
+	Links                  JSON      `db:"links"`       // links found on the page, checked again on request when it is unchanged
 	HasLoginForm           bool      `db:"has_login_form"`
+	Findings               JSON      `db:"findings"` // analyzer output keyed by analyzer name
 	CreatedAt              time.Time `db:"created_at"`
 }
+
+// ExtractionRule is a user-defined extraction rule scoped to a URL or a tag
+type ExtractionRule struct {
+	ID           int64     `db:"id"`
+	Name         string    `db:"name"`
+	URLID        *int64    `db:"url_id"`
+	TagID        *int64    `db:"tag_id"`
+	Tag          *string   `db:"tag"` // name of the scoping tag, joined from tags
+	SelectorType string    `db:"selector_type"`
+	Selector     string    `db:"selector"`
+	Attribute    *string   `db:"attribute"`
+	Multiple     bool      `db:"multiple"`
+	Regex        *string   `db:"regex"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// RequestProfile customises the requests made when crawling a URL or any URL with a tag
+type RequestProfile struct {
+	ID             int64     `db:"id"`
+	Name           string    `db:"name"`
+	URLID          *int64    `db:"url_id"`
+	TagID          *int64    `db:"tag_id"`
+	Tag            *string   `db:"tag"` // name of the scoping tag, joined from tags
+	UserAgent      *string   `db:"user_agent"`
+	AcceptLanguage *string   `db:"accept_language"`
+	Headers        JSON      `db:"headers"` // header name to value, only in profiles stored before header values were encrypted
+	AuthType       string    `db:"auth_type"`
+	AuthUsername   *string   `db:"auth_username"`
+	Secrets        []byte    `db:"secrets"` // encrypted header values, cookies, password and token
+	ApplyToLinks   bool      `db:"apply_to_links"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// LoginRecipe logs the crawler in before it fetches the URLs that reference it
+type LoginRecipe struct {
+	ID            int64     `db:"id"`
+	Name          string    `db:"name"`
+	LoginURL      string    `db:"login_url"`
+	Fields        []byte    `db:"fields"` // encrypted field name to value map
+	SuccessCookie *string   `db:"success_cookie"`
+	SuccessURL    *string   `db:"success_url"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// ProxyPool is a set of proxies that crawls of a URL, or of any URL with a tag, rotate through
+type ProxyPool struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name"`
+	URLID     *int64    `db:"url_id"`
+	TagID     *int64    `db:"tag_id"`
+	Tag       *string   `db:"tag"`     // name of the scoping tag, joined from tags
+	Proxies   []byte    `db:"proxies"` // encrypted list of proxy URLs
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// LinkCheck is the stored outcome of checking a link
+type LinkCheck struct {
+	URL       string    `db:"url"`   // normalised
+	Class     string    `db:"class"` // error class, empty when the link was fine
+	CheckedAt time.Time `db:"checked_at"`
+}
+
+// ResultSnapshot records where the raw response of a crawl result is stored
+type ResultSnapshot struct {
+	ID        int64     `db:"id"`
+	ResultID  int64     `db:"result_id"`
+	URLID     int64     `db:"url_id"`
+	BlobKey   *string   `db:"blob_key"`   // nil once the HTTP message is pruned and only WARC records are kept
+	SizeBytes int64     `db:"size_bytes"` // compressed
+	WARCKey   *string   `db:"warc_key"`   // set when the result is archived as WARC records
+	WARCBytes *int64    `db:"warc_bytes"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Request profile auth types
+const (
+	AuthNone   = "none"
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+// Certificate is the TLS certificate last seen for a URL, refreshed on every crawl
+type Certificate struct {
+	URLID           int64     `db:"url_id"`
+	URL             string    `db:"url"` // joined from urls
+	Host            string    `db:"host"`
+	Subject         string    `db:"subject"`
+	Issuer          string    `db:"issuer"`
+	SANs            JSON      `db:"sans"` // list of subject alternative names
+	NotBefore       time.Time `db:"not_before"`
+	NotAfter        time.Time `db:"not_after"`
+	ChainValid      bool      `db:"chain_valid"`
+	HostnameMatch   bool      `db:"hostname_match"`
+	ValidationError *string   `db:"validation_error"`
+	CheckedAt       time.Time `db:"checked_at"`
+}
+
+type CertificateAlertKind string
+
+const (
+	CertAlertExpiring CertificateAlertKind = "expiring"
+	CertAlertExpired  CertificateAlertKind = "expired"
+	CertAlertInvalid  CertificateAlertKind = "invalid"
+)
+
+// CertificateAlert is raised when a URL's certificate enters an alerting state
+type CertificateAlert struct {
+	ID        int64                `db:"id"`
+	URLID     int64                `db:"url_id"`
+	URL       string               `db:"url"` // joined from urls
+	Kind      CertificateAlertKind `db:"kind"`
+	Message   string               `db:"message"`
+	NotAfter  time.Time            `db:"not_after"`
+	CreatedAt time.Time            `db:"created_at"`
+}
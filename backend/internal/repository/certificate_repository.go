@@ -0,0 +1,96 @@
+package repository
+
+//go:generate mockery --name=CertificateRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type CertificateRepository interface {
+	GetByURLID(ctx context.Context, urlID int64) (*models.Certificate, error)
+	Upsert(ctx context.Context, cert models.Certificate) error
+	List(ctx context.Context, expiringBefore *time.Time) ([]models.Certificate, error)
+	CreateAlert(ctx context.Context, alert models.CertificateAlert) error
+	ListAlerts(ctx context.Context, limit int) ([]models.CertificateAlert, error)
+}
+
+type certificateRepository struct {
+	db *sqlx.DB
+}
+
+func NewCertificateRepository(db *sqlx.DB) CertificateRepository {
+	return &certificateRepository{db: db}
+}
+
+const certificateColumns = `c.url_id, u.url, c.host, c.subject, c.issuer, c.sans, c.not_before, c.not_after,
+	          c.chain_valid, c.hostname_match, c.validation_error, c.checked_at`
+
+// GetByURLID returns the certificate last seen for a URL, or sql.ErrNoRows
+func (r *certificateRepository) GetByURLID(ctx context.Context, urlID int64) (*models.Certificate, error) {
+	var out models.Certificate
+	query := `SELECT ` + certificateColumns + `
+	          FROM certificates c JOIN urls u ON u.id = c.url_id
+	          WHERE c.url_id = ?`
+	if err := r.db.GetContext(ctx, &out, query, urlID); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Upsert stores the certificate of a URL, replacing the previous one
+func (r *certificateRepository) Upsert(ctx context.Context, cert models.Certificate) error {
+	query := `INSERT INTO certificates (
+		url_id, host, subject, issuer, sans, not_before, not_after, chain_valid, hostname_match, validation_error
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		host = VALUES(host), subject = VALUES(subject), issuer = VALUES(issuer), sans = VALUES(sans),
+		not_before = VALUES(not_before), not_after = VALUES(not_after), chain_valid = VALUES(chain_valid),
+		hostname_match = VALUES(hostname_match), validation_error = VALUES(validation_error),
+		checked_at = CURRENT_TIMESTAMP`
+	_, err := r.db.ExecContext(ctx, query,
+		cert.URLID, cert.Host, cert.Subject, cert.Issuer, cert.SANs, cert.NotBefore, cert.NotAfter,
+		cert.ChainValid, cert.HostnameMatch, cert.ValidationError,
+	)
+	return err
+}
+
+// List returns certificates ordered by expiry, optionally only those expiring before a time
+func (r *certificateRepository) List(ctx context.Context, expiringBefore *time.Time) ([]models.Certificate, error) {
+	query := `SELECT ` + certificateColumns + `
+	          FROM certificates c JOIN urls u ON u.id = c.url_id`
+	args := []any{}
+	if expiringBefore != nil {
+		query += ` WHERE c.not_after < ?`
+		args = append(args, *expiringBefore)
+	}
+	query += ` ORDER BY c.not_after, c.url_id`
+	out := make([]models.Certificate, 0)
+	if err := r.db.SelectContext(ctx, &out, query, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *certificateRepository) CreateAlert(ctx context.Context, alert models.CertificateAlert) error {
+	query := `INSERT INTO certificate_alerts (url_id, kind, message, not_after) VALUES (?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, alert.URLID, alert.Kind, alert.Message, alert.NotAfter)
+	return err
+}
+
+// ListAlerts returns the most recent alerts first
+func (r *certificateRepository) ListAlerts(ctx context.Context, limit int) ([]models.CertificateAlert, error) {
+	query := `SELECT a.id, a.url_id, u.url, a.kind, a.message, a.not_after, a.created_at
+	          FROM certificate_alerts a JOIN urls u ON u.id = a.url_id
+	          ORDER BY a.created_at DESC, a.id DESC
+	          LIMIT ?`
+	out := make([]models.CertificateAlert, 0)
+	if err := r.db.SelectContext(ctx, &out, query, limit); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
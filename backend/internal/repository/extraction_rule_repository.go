@@ -0,0 +1,110 @@
+package repository
+
+//go:generate mockery --name=ExtractionRuleRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type ExtractionRuleRepository interface {
+	Create(ctx context.Context, rule models.ExtractionRule) (*models.ExtractionRule, error)
+	List(ctx context.Context) ([]models.ExtractionRule, error)
+	Delete(ctx context.Context, id int64) error
+	ListForURL(ctx context.Context, urlID int64) ([]models.ExtractionRule, error)
+}
+
+type extractionRuleRepository struct {
+	db *sqlx.DB
+}
+
+func NewExtractionRuleRepository(db *sqlx.DB) ExtractionRuleRepository {
+	return &extractionRuleRepository{db: db}
+}
+
+const extractionRuleColumns = `r.id, r.name, r.url_id, r.tag_id, t.name AS tag,
+	          r.selector_type, r.selector, r.attribute, r.multiple, r.regex, r.created_at`
+
+// Create inserts a rule. Tag-scoped rules reference the tag by name (rule.Tag);
+// sql.ErrNoRows is returned if that tag, or the URL of a URL-scoped rule, doesn't exist.
+func (r *extractionRuleRepository) Create(ctx context.Context, rule models.ExtractionRule) (*models.ExtractionRule, error) {
+	query := `INSERT INTO extraction_rules (name, url_id, tag_id, selector_type, selector, attribute, multiple, regex)
+	          SELECT ?, ?, (SELECT id FROM tags WHERE name = ?), ?, ?, ?, ?, ?
+	          FROM DUAL
+	          WHERE (? IS NULL OR EXISTS (SELECT 1 FROM tags WHERE name = ?))
+	            AND (? IS NULL OR EXISTS (SELECT 1 FROM urls WHERE id = ?))`
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Name, rule.URLID, rule.Tag, rule.SelectorType, rule.Selector, rule.Attribute, rule.Multiple, rule.Regex,
+		rule.Tag, rule.Tag, rule.URLID, rule.URLID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	var out models.ExtractionRule
+	query = `SELECT ` + extractionRuleColumns + `
+	          FROM extraction_rules r LEFT JOIN tags t ON t.id = r.tag_id
+	          WHERE r.id = ?`
+	if err := r.db.GetContext(ctx, &out, query, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns all rules
+func (r *extractionRuleRepository) List(ctx context.Context) ([]models.ExtractionRule, error) {
+	query := `SELECT ` + extractionRuleColumns + `
+	          FROM extraction_rules r LEFT JOIN tags t ON t.id = r.tag_id
+	          ORDER BY r.id`
+	out := make([]models.ExtractionRule, 0)
+	if err := r.db.SelectContext(ctx, &out, query); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete removes a rule, returning sql.ErrNoRows if it doesn't exist
+func (r *extractionRuleRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM extraction_rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListForURL returns the rules that apply to a URL: its own rules first, then those of its tags
+func (r *extractionRuleRepository) ListForURL(ctx context.Context, urlID int64) ([]models.ExtractionRule, error) {
+	query := `SELECT ` + extractionRuleColumns + `
+	          FROM extraction_rules r LEFT JOIN tags t ON t.id = r.tag_id
+	          WHERE r.url_id = ?
+	             OR r.tag_id IN (SELECT tag_id FROM url_tags WHERE url_id = ?)
+	          ORDER BY r.url_id IS NULL, r.id`
+	out := make([]models.ExtractionRule, 0)
+	if err := r.db.SelectContext(ctx, &out, query, urlID, urlID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -13,7 +13,7 @@ import (
 )
 
 type JobRepository interface {
-	Enqueue(ctx context.Context, urlID int64) (*models.CrawlJob, error)
+	Enqueue(ctx context.Context, urlID int64, opts models.JobOptions) (*models.CrawlJob, error)
 	UpdateStatus(ctx context.Context, id int64, status models.CrawlJobStatus, errMsg *string) error
 	GetByID(ctx context.Context, id int64) (*models.CrawlJob, error)
 	GetByURLID(ctx context.Context, urlID int64) (*models.CrawlJob, error)
@@ -29,9 +29,14 @@ func NewJobRepository(db *sqlx.DB) JobRepository {
 
 // Enqueue creates a new crawl job with status 'queued'
 // Uses prepared statement for optimal performance
-func (r *jobRepository) Enqueue(ctx context.Context, urlID int64) (*models.CrawlJob, error) {
-	query := `INSERT INTO crawl_jobs (url_id, status) VALUES (?, ?)`
-	result, err := r.db.ExecContext(ctx, query, urlID, models.JobQueued)
+func (r *jobRepository) Enqueue(ctx context.Context, urlID int64, opts models.JobOptions) (*models.CrawlJob, error) {
+	options, err := models.NewJSON(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO crawl_jobs (url_id, status, options) VALUES (?, ?, ?)`
+	result, err := r.db.ExecContext(ctx, query, urlID, models.JobQueued, options)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +51,7 @@ func (r *jobRepository) Enqueue(ctx context.Context, urlID int64) (*models.Crawl
 		ID:        id,
 		URLID:     urlID,
 		Status:    models.JobQueued,
+		Options:   options,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
@@ -122,7 +128,7 @@ func (r *jobRepository) UpdateStatus(ctx context.Context, id int64, status model
 // GetByID fetches a job by ID using prepared statement
 func (r *jobRepository) GetByID(ctx context.Context, id int64) (*models.CrawlJob, error) {
 	var out models.CrawlJob
-	query := `SELECT id, url_id, status, started_at, completed_at, error_message, created_at, updated_at 
+	query := `SELECT id, url_id, status, options, started_at, completed_at, error_message, created_at, updated_at 
 	          FROM crawl_jobs WHERE id = ?`
 	if err := r.db.GetContext(ctx, &out, query, id); err != nil {
 		if err == sql.ErrNoRows {
@@ -137,7 +143,7 @@ func (r *jobRepository) GetByID(ctx context.Context, id int64) (*models.CrawlJob
 // Uses ORDER BY and LIMIT for efficiency
 func (r *jobRepository) GetByURLID(ctx context.Context, urlID int64) (*models.CrawlJob, error) {
 	var out models.CrawlJob
-	query := `SELECT id, url_id, status, started_at, completed_at, error_message, created_at, updated_at 
+	query := `SELECT id, url_id, status, options, started_at, completed_at, error_message, created_at, updated_at 
 	          FROM crawl_jobs 
 	          WHERE url_id = ? 
 	          ORDER BY created_at DESC 
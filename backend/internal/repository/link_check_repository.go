@@ -0,0 +1,60 @@
+package repository
+
+//go:generate mockery --name=LinkCheckRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type LinkCheckRepository interface {
+	Get(ctx context.Context, url string) (*models.LinkCheck, error)
+	Upsert(ctx context.Context, check models.LinkCheck) error
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type linkCheckRepository struct {
+	db *sqlx.DB
+}
+
+func NewLinkCheckRepository(db *sqlx.DB) LinkCheckRepository {
+	return &linkCheckRepository{db: db}
+}
+
+// urlHash keys rows, since URLs are too long to index directly
+func urlHash(url string) []byte {
+	sum := sha256.Sum256([]byte(url))
+	return sum[:]
+}
+
+// Get returns the stored check of a normalised URL, or sql.ErrNoRows if it has none
+func (r *linkCheckRepository) Get(ctx context.Context, url string) (*models.LinkCheck, error) {
+	var out models.LinkCheck
+	query := `SELECT url, class, checked_at FROM link_checks WHERE url_hash = ?`
+	if err := r.db.GetContext(ctx, &out, query, urlHash(url)); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Upsert stores a check, replacing an earlier one of the same URL
+func (r *linkCheckRepository) Upsert(ctx context.Context, check models.LinkCheck) error {
+	query := `INSERT INTO link_checks (url_hash, url, class, checked_at) VALUES (?, ?, ?, ?)
+	          ON DUPLICATE KEY UPDATE class = VALUES(class), checked_at = VALUES(checked_at)`
+	_, err := r.db.ExecContext(ctx, query, urlHash(check.URL), check.URL, check.Class, check.CheckedAt)
+	return err
+}
+
+// DeleteBefore removes checks made before a point in time and returns how many there were
+func (r *linkCheckRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM link_checks WHERE checked_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,109 @@
+package repository
+
+//go:generate mockery --name=LoginRecipeRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type LoginRecipeRepository interface {
+	Create(ctx context.Context, recipe models.LoginRecipe) (*models.LoginRecipe, error)
+	List(ctx context.Context) ([]models.LoginRecipe, error)
+	Delete(ctx context.Context, id int64) error
+	AssignToURL(ctx context.Context, urlID int64, recipeID *int64) error
+	GetForURL(ctx context.Context, urlID int64) (*models.LoginRecipe, error)
+}
+
+type loginRecipeRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoginRecipeRepository(db *sqlx.DB) LoginRecipeRepository {
+	return &loginRecipeRepository{db: db}
+}
+
+const loginRecipeColumns = `r.id, r.name, r.login_url, r.fields, r.success_cookie, r.success_url, r.created_at`
+
+// Create inserts a recipe and returns it with all fields
+func (r *loginRecipeRepository) Create(ctx context.Context, recipe models.LoginRecipe) (*models.LoginRecipe, error) {
+	query := `INSERT INTO login_recipes (name, login_url, fields, success_cookie, success_url) VALUES (?, ?, ?, ?, ?)`
+	result, err := r.db.ExecContext(ctx, query,
+		recipe.Name, recipe.LoginURL, recipe.Fields, recipe.SuccessCookie, recipe.SuccessURL,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	var out models.LoginRecipe
+	query = `SELECT ` + loginRecipeColumns + ` FROM login_recipes r WHERE r.id = ?`
+	if err := r.db.GetContext(ctx, &out, query, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns all recipes
+func (r *loginRecipeRepository) List(ctx context.Context) ([]models.LoginRecipe, error) {
+	query := `SELECT ` + loginRecipeColumns + ` FROM login_recipes r ORDER BY r.id`
+	out := make([]models.LoginRecipe, 0)
+	if err := r.db.SelectContext(ctx, &out, query); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete removes a recipe, returning sql.ErrNoRows if it doesn't exist.
+// URLs that used it are crawled without logging in afterwards.
+func (r *loginRecipeRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM login_recipes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// AssignToURL sets the recipe a URL logs in with; a nil recipeID removes it.
+// sql.ErrNoRows is returned if the URL or the recipe doesn't exist.
+func (r *loginRecipeRepository) AssignToURL(ctx context.Context, urlID int64, recipeID *int64) error {
+	// checked up front: RowsAffected is 0 both for a missing URL and for an unchanged one
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM urls WHERE id = ?)
+	             AND (? IS NULL OR EXISTS (SELECT 1 FROM login_recipes WHERE id = ?))`
+	if err := r.db.GetContext(ctx, &exists, query, urlID, recipeID, recipeID); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE urls SET login_recipe_id = ? WHERE id = ?`, recipeID, urlID)
+	return err
+}
+
+// GetForURL returns the recipe a URL logs in with, or sql.ErrNoRows if it has none
+func (r *loginRecipeRepository) GetForURL(ctx context.Context, urlID int64) (*models.LoginRecipe, error) {
+	var out models.LoginRecipe
+	query := `SELECT ` + loginRecipeColumns + `
+	          FROM login_recipes r JOIN urls u ON u.login_recipe_id = r.id
+	          WHERE u.id = ?`
+	if err := r.db.GetContext(ctx, &out, query, urlID); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
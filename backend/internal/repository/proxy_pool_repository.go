@@ -0,0 +1,108 @@
+package repository
+
+//go:generate mockery --name=ProxyPoolRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type ProxyPoolRepository interface {
+	Create(ctx context.Context, pool models.ProxyPool) (*models.ProxyPool, error)
+	List(ctx context.Context) ([]models.ProxyPool, error)
+	Delete(ctx context.Context, id int64) error
+	ListForURL(ctx context.Context, urlID int64) ([]models.ProxyPool, error)
+}
+
+type proxyPoolRepository struct {
+	db *sqlx.DB
+}
+
+func NewProxyPoolRepository(db *sqlx.DB) ProxyPoolRepository {
+	return &proxyPoolRepository{db: db}
+}
+
+const proxyPoolColumns = `p.id, p.name, p.url_id, p.tag_id, t.name AS tag, p.proxies, p.created_at`
+
+// Create inserts a pool. Tag-scoped pools reference the tag by name (pool.Tag);
+// sql.ErrNoRows is returned if that tag doesn't exist.
+func (r *proxyPoolRepository) Create(ctx context.Context, pool models.ProxyPool) (*models.ProxyPool, error) {
+	query := `INSERT INTO proxy_pools (name, url_id, tag_id, proxies)
+	          SELECT ?, ?, (SELECT id FROM tags WHERE name = ?), ?
+	          FROM DUAL
+	          WHERE ? IS NULL OR EXISTS (SELECT 1 FROM tags WHERE name = ?)`
+	result, err := r.db.ExecContext(ctx, query,
+		pool.Name, pool.URLID, pool.Tag, pool.Proxies,
+		pool.Tag, pool.Tag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	var out models.ProxyPool
+	query = `SELECT ` + proxyPoolColumns + `
+	          FROM proxy_pools p LEFT JOIN tags t ON t.id = p.tag_id
+	          WHERE p.id = ?`
+	if err := r.db.GetContext(ctx, &out, query, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns all pools
+func (r *proxyPoolRepository) List(ctx context.Context) ([]models.ProxyPool, error) {
+	query := `SELECT ` + proxyPoolColumns + `
+	          FROM proxy_pools p LEFT JOIN tags t ON t.id = p.tag_id
+	          ORDER BY p.id`
+	out := make([]models.ProxyPool, 0)
+	if err := r.db.SelectContext(ctx, &out, query); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete removes a pool, returning sql.ErrNoRows if it doesn't exist
+func (r *proxyPoolRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM proxy_pools WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListForURL returns the pools that apply to a URL: its own pools first, then those of its tags
+func (r *proxyPoolRepository) ListForURL(ctx context.Context, urlID int64) ([]models.ProxyPool, error) {
+	query := `SELECT ` + proxyPoolColumns + `
+	          FROM proxy_pools p LEFT JOIN tags t ON t.id = p.tag_id
+	          WHERE p.url_id = ?
+	             OR p.tag_id IN (SELECT tag_id FROM url_tags WHERE url_id = ?)
+	          ORDER BY p.url_id IS NULL, p.id`
+	out := make([]models.ProxyPool, 0)
+	if err := r.db.SelectContext(ctx, &out, query, urlID, urlID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
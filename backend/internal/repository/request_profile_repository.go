@@ -0,0 +1,112 @@
+package repository
+
+//go:generate mockery --name=RequestProfileRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type RequestProfileRepository interface {
+	Create(ctx context.Context, profile models.RequestProfile) (*models.RequestProfile, error)
+	List(ctx context.Context) ([]models.RequestProfile, error)
+	Delete(ctx context.Context, id int64) error
+	ListForURL(ctx context.Context, urlID int64) ([]models.RequestProfile, error)
+}
+
+type requestProfileRepository struct {
+	db *sqlx.DB
+}
+
+func NewRequestProfileRepository(db *sqlx.DB) RequestProfileRepository {
+	return &requestProfileRepository{db: db}
+}
+
+const requestProfileColumns = `p.id, p.name, p.url_id, p.tag_id, t.name AS tag,
+	          p.user_agent, p.accept_language, p.headers, p.auth_type, p.auth_username,
+	          p.secrets, p.apply_to_links, p.created_at`
+
+// Create inserts a profile. Tag-scoped profiles reference the tag by name (profile.Tag);
+// sql.ErrNoRows is returned if that tag doesn't exist.
+func (r *requestProfileRepository) Create(ctx context.Context, profile models.RequestProfile) (*models.RequestProfile, error) {
+	query := `INSERT INTO request_profiles (name, url_id, tag_id, user_agent, accept_language, headers,
+	                                        auth_type, auth_username, secrets, apply_to_links)
+	          SELECT ?, ?, (SELECT id FROM tags WHERE name = ?), ?, ?, ?, ?, ?, ?, ?
+	          FROM DUAL
+	          WHERE ? IS NULL OR EXISTS (SELECT 1 FROM tags WHERE name = ?)`
+	result, err := r.db.ExecContext(ctx, query,
+		profile.Name, profile.URLID, profile.Tag, profile.UserAgent, profile.AcceptLanguage, profile.Headers,
+		profile.AuthType, profile.AuthUsername, profile.Secrets, profile.ApplyToLinks,
+		profile.Tag, profile.Tag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	var out models.RequestProfile
+	query = `SELECT ` + requestProfileColumns + `
+	          FROM request_profiles p LEFT JOIN tags t ON t.id = p.tag_id
+	          WHERE p.id = ?`
+	if err := r.db.GetContext(ctx, &out, query, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns all profiles
+func (r *requestProfileRepository) List(ctx context.Context) ([]models.RequestProfile, error) {
+	query := `SELECT ` + requestProfileColumns + `
+	          FROM request_profiles p LEFT JOIN tags t ON t.id = p.tag_id
+	          ORDER BY p.id`
+	out := make([]models.RequestProfile, 0)
+	if err := r.db.SelectContext(ctx, &out, query); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete removes a profile, returning sql.ErrNoRows if it doesn't exist
+func (r *requestProfileRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM request_profiles WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListForURL returns the profiles that apply to a URL: its own profiles first, then those of its tags
+func (r *requestProfileRepository) ListForURL(ctx context.Context, urlID int64) ([]models.RequestProfile, error) {
+	query := `SELECT ` + requestProfileColumns + `
+	          FROM request_profiles p LEFT JOIN tags t ON t.id = p.tag_id
+	          WHERE p.url_id = ?
+	             OR p.tag_id IN (SELECT tag_id FROM url_tags WHERE url_id = ?)
+	          ORDER BY p.url_id IS NULL, p.id`
+	out := make([]models.RequestProfile, 0)
+	if err := r.db.SelectContext(ctx, &out, query, urlID, urlID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
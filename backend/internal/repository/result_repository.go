@@ -28,15 +28,17 @@ func NewResultRepository(db *sqlx.DB) ResultRepository {
 // Uses prepared statement for optimal performance
 func (r *resultRepository) Create(ctx context.Context, res models.CrawlResult) (*models.CrawlResult, error) {
 	query := `INSERT INTO crawl_results (
-		job_id, url_id, html_version, title, 
+		job_id, url_id, outcome, content_type, body_bytes, truncated, proxy, etag, last_modified, previous_result_id, inputs_hash, html_version, document_mode, charset, charset_source, charset_mismatch, content_hash, simhash, content_changed, similarity, title, 
 		headings_h1, headings_h2, headings_h3, headings_h4, headings_h5, headings_h6,
-		internal_links_count, external_links_count, inaccessible_links_count, has_login_form
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		internal_links_count, external_links_count, inaccessible_links_count, link_errors, links, has_login_form,
+		findings
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	result, err := r.db.ExecContext(ctx, query,
-		res.JobID, res.URLID, res.HTMLVersion, res.Title,
+		res.JobID, res.URLID, res.Outcome, res.ContentType, res.BodyBytes, res.Truncated, res.Proxy, res.ETag, res.LastModified, res.PreviousResultID, res.InputsHash, res.HTMLVersion, res.DocumentMode, res.Charset, res.CharsetSource, res.CharsetMismatch, res.ContentHash, res.SimHash, res.ContentChanged, res.Similarity, res.Title,
 		res.HeadingsH1, res.HeadingsH2, res.HeadingsH3, res.HeadingsH4, res.HeadingsH5, res.HeadingsH6,
-		res.InternalLinksCount, res.ExternalLinksCount, res.InaccessibleLinksCount, res.HasLoginForm,
+		res.InternalLinksCount, res.ExternalLinksCount, res.InaccessibleLinksCount, res.LinkErrors, res.Links, res.HasLoginForm,
+		res.Findings,
 	)
 	if err != nil {
 		return nil, err
@@ -55,9 +57,10 @@ func (r *resultRepository) Create(ctx context.Context, res models.CrawlResult) (
 // Uses ORDER BY and LIMIT for efficiency
 func (r *resultRepository) GetByURLID(ctx context.Context, urlID int64) (*models.CrawlResult, error) {
 	var out models.CrawlResult
-	query := `SELECT id, job_id, url_id, html_version, title,
+	query := `SELECT id, job_id, url_id, outcome, content_type, body_bytes, truncated, proxy, etag, last_modified, previous_result_id, inputs_hash, html_version, document_mode, charset, charset_source, charset_mismatch, content_hash, simhash, content_changed, similarity, title,
 	          headings_h1, headings_h2, headings_h3, headings_h4, headings_h5, headings_h6,
-	          internal_links_count, external_links_count, inaccessible_links_count, has_login_form, created_at
+	          internal_links_count, external_links_count, inaccessible_links_count, link_errors, links, has_login_form,
+	          findings, created_at
 	          FROM crawl_results 
 	          WHERE url_id = ? 
 	          ORDER BY created_at DESC 
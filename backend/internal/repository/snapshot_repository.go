@@ -0,0 +1,159 @@
+package repository
+
+//go:generate mockery --name=SnapshotRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type SnapshotRepository interface {
+	Create(ctx context.Context, snap models.ResultSnapshot) (*models.ResultSnapshot, error)
+	GetByResultID(ctx context.Context, resultID int64) (*models.ResultSnapshot, error)
+	ListExpired(ctx context.Context, before *time.Time, keepPerURL int, limit int) ([]models.ResultSnapshot, error)
+	ListExpiredArchives(ctx context.Context, before *time.Time, limit int) ([]models.ResultSnapshot, error)
+	ListArchived(ctx context.Context, filter models.ArchiveFilter) ([]models.ResultSnapshot, error)
+	DropSnapshot(ctx context.Context, id int64) error
+	DropArchive(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type snapshotRepository struct {
+	db *sqlx.DB
+}
+
+func NewSnapshotRepository(db *sqlx.DB) SnapshotRepository {
+	return &snapshotRepository{db: db}
+}
+
+func (r *snapshotRepository) Create(ctx context.Context, snap models.ResultSnapshot) (*models.ResultSnapshot, error) {
+	query := `INSERT INTO result_snapshots (result_id, url_id, blob_key, size_bytes, warc_key, warc_bytes) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := r.db.ExecContext(ctx, query, snap.ResultID, snap.URLID, snap.BlobKey, snap.SizeBytes, snap.WARCKey, snap.WARCBytes)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	snap.ID = id
+	return &snap, nil
+}
+
+// GetByResultID returns the snapshot of a result, or sql.ErrNoRows if it has none
+func (r *snapshotRepository) GetByResultID(ctx context.Context, resultID int64) (*models.ResultSnapshot, error) {
+	var out models.ResultSnapshot
+	query := `SELECT id, result_id, url_id, blob_key, size_bytes, warc_key, warc_bytes, created_at FROM result_snapshots WHERE result_id = ?`
+	if err := r.db.GetContext(ctx, &out, query, resultID); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListExpired returns up to limit snapshots whose HTTP message is past retention: those of
+// deleted results, those taken before a point in time (when before is set) and those beyond
+// the newest keepPerURL of their URL (when keepPerURL is positive). Snapshots that only have
+// WARC records left aren't listed or ranked.
+func (r *snapshotRepository) ListExpired(ctx context.Context, before *time.Time, keepPerURL int, limit int) ([]models.ResultSnapshot, error) {
+	conditions := []string{"orphaned"}
+	var args []any
+	if before != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *before)
+	}
+	if keepPerURL > 0 {
+		conditions = append(conditions, "url_rank > ?")
+		args = append(args, keepPerURL)
+	}
+	args = append(args, limit)
+	query := `SELECT id, result_id, url_id, blob_key, size_bytes, warc_key, warc_bytes, created_at FROM (
+	              SELECT s.id, s.result_id, s.url_id, s.blob_key, s.size_bytes, s.warc_key, s.warc_bytes, s.created_at,
+	                     r.id IS NULL AS orphaned,
+	                     ROW_NUMBER() OVER (PARTITION BY s.url_id ORDER BY s.created_at DESC, s.id DESC) AS url_rank
+	              FROM result_snapshots s
+	              LEFT JOIN crawl_results r ON r.id = s.result_id
+	              WHERE s.blob_key IS NOT NULL
+	          ) ranked
+	          WHERE ` + strings.Join(conditions, " OR ") + `
+	          ORDER BY id
+	          LIMIT ?`
+	var out []models.ResultSnapshot
+	if err := r.db.SelectContext(ctx, &out, query, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListExpiredArchives returns up to limit snapshots whose WARC records are past retention:
+// those of deleted results and, when before is set, those taken before it
+func (r *snapshotRepository) ListExpiredArchives(ctx context.Context, before *time.Time, limit int) ([]models.ResultSnapshot, error) {
+	conditions := []string{"r.id IS NULL"}
+	var args []any
+	if before != nil {
+		conditions = append(conditions, "s.created_at < ?")
+		args = append(args, *before)
+	}
+	args = append(args, limit)
+	query := `SELECT s.id, s.result_id, s.url_id, s.blob_key, s.size_bytes, s.warc_key, s.warc_bytes, s.created_at
+	          FROM result_snapshots s
+	          LEFT JOIN crawl_results r ON r.id = s.result_id
+	          WHERE s.warc_key IS NOT NULL AND (` + strings.Join(conditions, " OR ") + `)
+	          ORDER BY s.id
+	          LIMIT ?`
+	var out []models.ResultSnapshot
+	if err := r.db.SelectContext(ctx, &out, query, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListArchived returns the archived snapshots of existing results that match filter, oldest
+// first; a zero filter matches every one
+func (r *snapshotRepository) ListArchived(ctx context.Context, filter models.ArchiveFilter) ([]models.ResultSnapshot, error) {
+	conditions := []string{"s.warc_key IS NOT NULL"}
+	var args []any
+	if filter.JobID != nil {
+		conditions = append(conditions, "r.job_id = ?")
+		args = append(args, *filter.JobID)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "s.created_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "s.created_at < ?")
+		args = append(args, *filter.To)
+	}
+	query := `SELECT s.id, s.result_id, s.url_id, s.blob_key, s.size_bytes, s.warc_key, s.warc_bytes, s.created_at
+	          FROM result_snapshots s
+	          JOIN crawl_results r ON r.id = s.result_id
+	          WHERE ` + strings.Join(conditions, " AND ") + `
+	          ORDER BY s.created_at, s.id`
+	var out []models.ResultSnapshot
+	if err := r.db.SelectContext(ctx, &out, query, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DropSnapshot forgets the HTTP message of a snapshot whose WARC records are kept
+func (r *snapshotRepository) DropSnapshot(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE result_snapshots SET blob_key = NULL, size_bytes = 0 WHERE id = ?`, id)
+	return err
+}
+
+// DropArchive forgets the WARC records of a snapshot whose HTTP message is kept
+func (r *snapshotRepository) DropArchive(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE result_snapshots SET warc_key = NULL, warc_bytes = NULL WHERE id = ?`, id)
+	return err
+}
+
+func (r *snapshotRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM result_snapshots WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,83 @@
+package repository
+
+//go:generate mockery --name=TagRepository --output=../mocks --outpkg=mocks
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+type TagRepository interface {
+	List(ctx context.Context) ([]models.Tag, error)
+	Assign(ctx context.Context, names []string, urlIDs []int64) error
+	Unassign(ctx context.Context, names []string, urlIDs []int64) (int64, error)
+}
+
+type tagRepository struct {
+	db *sqlx.DB
+}
+
+func NewTagRepository(db *sqlx.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// List returns all tags with the number of URLs carrying each of them
+func (r *tagRepository) List(ctx context.Context) ([]models.Tag, error) {
+	query := `SELECT t.id, t.name, t.created_at, COUNT(ut.url_id) AS url_count
+	          FROM tags t
+	          LEFT JOIN url_tags ut ON ut.tag_id = t.id
+	          GROUP BY t.id, t.name, t.created_at
+	          ORDER BY t.name`
+	out := make([]models.Tag, 0)
+	if err := r.db.SelectContext(ctx, &out, query); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Assign creates missing tags and attaches all of them to all given URLs.
+// Existing assignments are left untouched, so the operation is idempotent.
+func (r *tagRepository) Assign(ctx context.Context, names []string, urlIDs []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO tags (name) VALUES (?)`, name); err != nil {
+			return err
+		}
+	}
+
+	query, args, err := sqlx.In(`INSERT IGNORE INTO url_tags (url_id, tag_id)
+	          SELECT u.id, t.id FROM urls u CROSS JOIN tags t
+	          WHERE u.id IN (?) AND t.name IN (?)`, urlIDs, names)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Unassign detaches the given tags from the given URLs and returns the number of removed assignments.
+// Tags themselves are kept even if no URL carries them anymore.
+func (r *tagRepository) Unassign(ctx context.Context, names []string, urlIDs []int64) (int64, error) {
+	query, args, err := sqlx.In(`DELETE ut FROM url_tags ut
+	          JOIN tags t ON t.id = ut.tag_id
+	          WHERE ut.url_id IN (?) AND t.name IN (?)`, urlIDs, names)
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
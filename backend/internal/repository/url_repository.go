@@ -5,6 +5,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 
@@ -13,8 +14,9 @@ import (
 
 type URLRepository interface {
 	Create(ctx context.Context, url string) (*models.URL, error)
-	List(ctx context.Context, page int, limit int, sortBy string, order string) ([]models.URL, int64, error)
+	List(ctx context.Context, page int, limit int, sortBy string, order string, filter models.URLFilter) ([]models.URL, int64, error)
 	GetByID(ctx context.Context, id int64) (*models.URL, error)
+	ListByTag(ctx context.Context, tag string) ([]models.URL, error)
 }
 
 type urlRepository struct {
@@ -51,7 +53,8 @@ func (r *urlRepository) Create(ctx context.Context, url string) (*models.URL, er
 // List returns paginated URLs with total count
 // Uses optimized approach: separate count query (fast with index) + paginated select
 // Validates and sanitizes sortBy to prevent SQL injection
-func (r *urlRepository) List(ctx context.Context, page int, limit int, sortBy string, order string) ([]models.URL, int64, error) {
+// Tags of the returned URLs are loaded with one additional query
+func (r *urlRepository) List(ctx context.Context, page int, limit int, sortBy string, order string, filter models.URLFilter) ([]models.URL, int64, error) {
 	// Validate and sanitize inputs
 	if page < 1 {
 		page = 1
@@ -71,27 +74,99 @@ func (r *urlRepository) List(ctx context.Context, page int, limit int, sortBy st
 		order = "desc"
 	}
 
+	// Build WHERE clause from filter
+	var conds []string
+	var whereArgs []any
+	if filter.Tag != "" {
+		conds = append(conds, `id IN (SELECT ut.url_id FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.name = ?)`)
+		whereArgs = append(whereArgs, filter.Tag)
+	}
+	if filter.ChangedSince != nil {
+		conds = append(conds, `id IN (SELECT cr.url_id FROM crawl_results cr WHERE cr.content_changed = TRUE AND cr.created_at >= ?)`)
+		whereArgs = append(whereArgs, *filter.ChangedSince)
+	}
+	whereClause := ""
+	if len(conds) > 0 {
+		whereClause = ` WHERE ` + strings.Join(conds, " AND ")
+	}
+
 	// Get total count (optimized with index on created_at)
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM urls`
-	if err := r.db.GetContext(ctx, &total, countQuery); err != nil {
+	countQuery := `SELECT COUNT(*) FROM urls` + whereClause
+	if err := r.db.GetContext(ctx, &total, countQuery, whereArgs...); err != nil {
 		return nil, 0, err
 	}
 
 	// Fetch paginated results with explicit column selection
 	query := `SELECT id, url, created_at, updated_at 
-	          FROM urls 
+	          FROM urls` + whereClause + `
 	          ORDER BY ` + sortBy + ` ` + order + `
 	          LIMIT ? OFFSET ?`
 
 	var results []models.URL
-	if err := r.db.SelectContext(ctx, &results, query, limit, (page-1)*limit); err != nil {
+	args := append(whereArgs, limit, (page-1)*limit)
+	if err := r.db.SelectContext(ctx, &results, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachTags(ctx, results); err != nil {
 		return nil, 0, err
 	}
 
 	return results, total, nil
 }
 
+// ListByTag returns all URLs carrying the given tag
+func (r *urlRepository) ListByTag(ctx context.Context, tag string) ([]models.URL, error) {
+	query := `SELECT u.id, u.url, u.created_at, u.updated_at
+	          FROM urls u
+	          JOIN url_tags ut ON ut.url_id = u.id
+	          JOIN tags t ON t.id = ut.tag_id
+	          WHERE t.name = ?
+	          ORDER BY u.id`
+	var results []models.URL
+	if err := r.db.SelectContext(ctx, &results, query, tag); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// attachTags loads tag names for the given URLs in a single query
+func (r *urlRepository) attachTags(ctx context.Context, urls []models.URL) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(urls))
+	for _, u := range urls {
+		ids = append(ids, u.ID)
+	}
+
+	query, args, err := sqlx.In(`SELECT ut.url_id, t.name
+	          FROM url_tags ut
+	          JOIN tags t ON t.id = ut.tag_id
+	          WHERE ut.url_id IN (?)
+	          ORDER BY t.name`, ids)
+	if err != nil {
+		return err
+	}
+	var rows []struct {
+		URLID int64  `db:"url_id"`
+		Name  string `db:"name"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	byURL := make(map[int64][]string, len(urls))
+	for _, row := range rows {
+		byURL[row.URLID] = append(byURL[row.URLID], row.Name)
+	}
+	for i := range urls {
+		urls[i].Tags = byURL[urls[i].ID]
+	}
+	return nil
+}
+
 // GetByID fetches a URL by ID using prepared statement
 func (r *urlRepository) GetByID(ctx context.Context, id int64) (*models.URL, error) {
 	var out models.URL
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+const (
+	defaultAlertLimit = 100
+	maxAlertLimit     = 1000
+)
+
+type CertificateService struct {
+	repo repository.CertificateRepository
+	// threshold is how long before expiry a certificate raises an alert
+	threshold time.Duration
+	now       func() time.Time
+}
+
+func NewCertificateService(repo repository.CertificateRepository, threshold time.Duration) (*CertificateService, error) {
+	if repo == nil {
+		return nil, errors.New("CertificateRepository must not be nil")
+	}
+	if threshold <= 0 {
+		return nil, errors.New("certificate alert threshold must be positive")
+	}
+	return &CertificateService{repo: repo, threshold: threshold, now: time.Now}, nil
+}
+
+// RecordCertificate stores the certificate seen by a crawl and raises an alert when the
+// certificate enters an alerting state. A certificate that stays in the same state doesn't
+// raise the alert again on every crawl: the previous state is the one it was in when it was
+// last checked, so the same certificate still alerts when it goes from expiring to expired.
+func (s *CertificateService) RecordCertificate(ctx context.Context, urlID int64, info *crawler.CertificateInfo) error {
+	prev, err := s.repo.GetByURLID(ctx, urlID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to load previous certificate: %w", err)
+	}
+
+	sans, err := models.NewJSON(info.SANs)
+	if err != nil {
+		return err
+	}
+	cert := models.Certificate{
+		URLID:         urlID,
+		Host:          info.Host,
+		Subject:       info.Subject,
+		Issuer:        info.Issuer,
+		SANs:          sans,
+		NotBefore:     info.NotBefore,
+		NotAfter:      info.NotAfter,
+		ChainValid:    info.ChainValid,
+		HostnameMatch: info.HostnameMatch,
+	}
+	if info.Error != "" {
+		cert.ValidationError = &info.Error
+	}
+	if err := s.repo.Upsert(ctx, cert); err != nil {
+		return fmt.Errorf("failed to store certificate: %w", err)
+	}
+
+	now := s.now()
+	kind, msg := s.alertFor(cert, now)
+	if kind == "" {
+		return nil
+	}
+	if prev != nil && prev.NotAfter.Equal(cert.NotAfter) {
+		if prevKind, _ := s.alertFor(*prev, prev.CheckedAt); prevKind == kind {
+			return nil
+		}
+	}
+	logrus.WithFields(logrus.Fields{"url_id": urlID, "host": cert.Host, "kind": kind}).Warn(msg)
+	return s.repo.CreateAlert(ctx, models.CertificateAlert{URLID: urlID, Kind: kind, Message: msg, NotAfter: cert.NotAfter})
+}
+
+// alertFor returns the alert a certificate warrants, if any
+func (s *CertificateService) alertFor(cert models.Certificate, now time.Time) (models.CertificateAlertKind, string) {
+	switch {
+	case !cert.ChainValid || !cert.HostnameMatch:
+		reason := "validation failed"
+		if cert.ValidationError != nil {
+			reason = *cert.ValidationError
+		}
+		return models.CertAlertInvalid, fmt.Sprintf("certificate for %s is invalid: %s", cert.Host, reason)
+	case !now.Before(cert.NotAfter):
+		return models.CertAlertExpired, fmt.Sprintf("certificate for %s expired on %s", cert.Host, cert.NotAfter.Format(time.DateOnly))
+	case cert.NotAfter.Sub(now) <= s.threshold:
+		return models.CertAlertExpiring, fmt.Sprintf("certificate for %s expires in %d days (%s)",
+			cert.Host, daysUntil(cert.NotAfter, now), cert.NotAfter.Format(time.DateOnly))
+	}
+	return "", ""
+}
+
+// ListCertificates returns all certificates, or those expiring within a window such as "30d" or "72h"
+func (s *CertificateService) ListCertificates(ctx context.Context, expiringWithin string) ([]models.CertificateResponse, error) {
+	now := s.now()
+	var before *time.Time
+	if expiringWithin != "" {
+		window, err := parseWindow(expiringWithin)
+		if err != nil {
+			return nil, err
+		}
+		t := now.Add(window)
+		before = &t
+	}
+	rows, err := s.repo.List(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.CertificateResponse, 0, len(rows))
+	for _, c := range rows {
+		sans := []string{}
+		if err := c.SANs.Decode(&sans); err != nil {
+			return nil, fmt.Errorf("failed to decode SANs: %w", err)
+		}
+		resp = append(resp, models.CertificateResponse{
+			URLID:           c.URLID,
+			URL:             c.URL,
+			Host:            c.Host,
+			Subject:         c.Subject,
+			Issuer:          c.Issuer,
+			SANs:            sans,
+			NotBefore:       c.NotBefore.Format(time.RFC3339),
+			NotAfter:        c.NotAfter.Format(time.RFC3339),
+			DaysToExpiry:    daysUntil(c.NotAfter, now),
+			ChainValid:      c.ChainValid,
+			HostnameMatch:   c.HostnameMatch,
+			ValidationError: c.ValidationError,
+			CheckedAt:       c.CheckedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
+
+// ListAlerts returns the most recent certificate alerts
+func (s *CertificateService) ListAlerts(ctx context.Context, limit int) ([]models.CertificateAlertResponse, error) {
+	if limit <= 0 {
+		limit = defaultAlertLimit
+	}
+	limit = min(limit, maxAlertLimit)
+	rows, err := s.repo.ListAlerts(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.CertificateAlertResponse, 0, len(rows))
+	for _, a := range rows {
+		resp = append(resp, models.CertificateAlertResponse{
+			ID:        a.ID,
+			URLID:     a.URLID,
+			URL:       a.URL,
+			Kind:      a.Kind,
+			Message:   a.Message,
+			NotAfter:  a.NotAfter.Format(time.RFC3339),
+			CreatedAt: a.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
+
+// parseWindow parses a positive duration, accepting a day suffix ("30d") on top of Go durations
+func parseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %q", s)
+	}
+	return d, nil
+}
+
+// daysUntil returns whole days until t, negative once t has passed
+func daysUntil(t, now time.Time) int {
+	return int(t.Sub(now).Hours() / 24)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+func TestCertificateService_RecordCertificate_AlertsOnceWhenExpiring(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	info := &crawler.CertificateInfo{
+		Host: "example.com", Subject: "example.com", Issuer: "Test CA", SANs: []string{"example.com"},
+		NotBefore: now.AddDate(0, -2, 0), NotAfter: now.AddDate(0, 0, 10),
+		ChainValid: true, HostnameMatch: true,
+	}
+
+	// first sighting raises an alert
+	repo := new(mocks.CertificateRepository)
+	repo.On("GetByURLID", ctx, int64(1)).Return(nil, sql.ErrNoRows)
+	repo.On("Upsert", ctx, mock.MatchedBy(func(c models.Certificate) bool {
+		return c.URLID == 1 && c.NotAfter.Equal(info.NotAfter) && string(c.SANs) == `["example.com"]`
+	})).Return(nil)
+	repo.On("CreateAlert", ctx, mock.MatchedBy(func(a models.CertificateAlert) bool {
+		return a.URLID == 1 && a.Kind == models.CertAlertExpiring
+	})).Return(nil)
+
+	svc, err := NewCertificateService(repo, 30*24*time.Hour)
+	assert.NoError(t, err)
+	svc.now = func() time.Time { return now }
+	assert.NoError(t, svc.RecordCertificate(ctx, 1, info))
+	repo.AssertExpectations(t)
+
+	// the same certificate in the same state doesn't alert again
+	repo = new(mocks.CertificateRepository)
+	repo.On("GetByURLID", ctx, int64(1)).Return(&models.Certificate{
+		URLID: 1, Host: "example.com", NotAfter: info.NotAfter, ChainValid: true, HostnameMatch: true,
+		CheckedAt: now.Add(-time.Hour),
+	}, nil)
+	repo.On("Upsert", ctx, mock.Anything).Return(nil)
+
+	svc, err = NewCertificateService(repo, 30*24*time.Hour)
+	assert.NoError(t, err)
+	svc.now = func() time.Time { return now }
+	assert.NoError(t, svc.RecordCertificate(ctx, 1, info))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CreateAlert", mock.Anything, mock.Anything)
+}
+
+func TestCertificateService_RecordCertificate_AlertsOnEachStateChange(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	info := &crawler.CertificateInfo{
+		Host: "example.com", NotBefore: start.AddDate(0, -2, 0), NotAfter: start.AddDate(0, 0, 60),
+		ChainValid: true, HostnameMatch: true,
+	}
+
+	// the same certificate is crawled while healthy, then expiring, then expired
+	var prev *models.Certificate
+	for _, step := range []struct {
+		at   time.Time
+		want models.CertificateAlertKind
+	}{
+		{start, ""},
+		{start.AddDate(0, 0, 35), models.CertAlertExpiring},
+		{start.AddDate(0, 0, 61), models.CertAlertExpired},
+	} {
+		repo := new(mocks.CertificateRepository)
+		if prev == nil {
+			repo.On("GetByURLID", ctx, int64(1)).Return(nil, sql.ErrNoRows)
+		} else {
+			repo.On("GetByURLID", ctx, int64(1)).Return(prev, nil)
+		}
+		repo.On("Upsert", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			c := args.Get(1).(models.Certificate)
+			c.CheckedAt = step.at
+			prev = &c
+		})
+		if step.want != "" {
+			repo.On("CreateAlert", ctx, mock.MatchedBy(func(a models.CertificateAlert) bool { return a.Kind == step.want })).Return(nil)
+		}
+
+		svc, err := NewCertificateService(repo, 30*24*time.Hour)
+		assert.NoError(t, err)
+		svc.now = func() time.Time { return step.at }
+		assert.NoError(t, svc.RecordCertificate(ctx, 1, info))
+		repo.AssertExpectations(t)
+		if step.want == "" {
+			repo.AssertNotCalled(t, "CreateAlert", mock.Anything, mock.Anything)
+		}
+	}
+}
+
+func TestCertificateService_RecordCertificate_AlertKinds(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		info crawler.CertificateInfo
+		want models.CertificateAlertKind
+	}{
+		{"healthy", crawler.CertificateInfo{NotAfter: now.AddDate(0, 3, 0), ChainValid: true, HostnameMatch: true}, ""},
+		{"expired", crawler.CertificateInfo{NotAfter: now.AddDate(0, 0, -1), ChainValid: true, HostnameMatch: true}, models.CertAlertExpired},
+		{"untrusted", crawler.CertificateInfo{NotAfter: now.AddDate(0, 3, 0), HostnameMatch: true, Error: "unknown authority"}, models.CertAlertInvalid},
+		{"wrong host", crawler.CertificateInfo{NotAfter: now.AddDate(0, 3, 0), ChainValid: true}, models.CertAlertInvalid},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(mocks.CertificateRepository)
+			repo.On("GetByURLID", ctx, int64(1)).Return(nil, sql.ErrNoRows)
+			repo.On("Upsert", ctx, mock.Anything).Return(nil)
+			if tc.want != "" {
+				repo.On("CreateAlert", ctx, mock.MatchedBy(func(a models.CertificateAlert) bool { return a.Kind == tc.want })).Return(nil)
+			}
+
+			svc, err := NewCertificateService(repo, 30*24*time.Hour)
+			assert.NoError(t, err)
+			svc.now = func() time.Time { return now }
+			assert.NoError(t, svc.RecordCertificate(ctx, 1, &tc.info))
+			repo.AssertExpectations(t)
+			if tc.want == "" {
+				repo.AssertNotCalled(t, "CreateAlert", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCertificateService_ListCertificates_ExpiringWithin(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := new(mocks.CertificateRepository)
+	repo.On("List", ctx, mock.MatchedBy(func(before *time.Time) bool {
+		return before != nil && before.Equal(now.Add(30*24*time.Hour))
+	})).Return([]models.Certificate{
+		{URLID: 1, URL: "https://example.com", Host: "example.com", SANs: models.JSON(`["example.com"]`), NotAfter: now.AddDate(0, 0, 12)},
+	}, nil)
+
+	svc, err := NewCertificateService(repo, 30*24*time.Hour)
+	assert.NoError(t, err)
+	svc.now = func() time.Time { return now }
+	certs, err := svc.ListCertificates(ctx, "30d")
+
+	assert.NoError(t, err)
+	assert.Len(t, certs, 1)
+	assert.Equal(t, 12, certs[0].DaysToExpiry)
+	assert.Equal(t, []string{"example.com"}, certs[0].SANs)
+
+	_, err = svc.ListCertificates(ctx, "soon")
+	assert.Error(t, err)
+	_, err = svc.ListCertificates(ctx, "-1d")
+	assert.Error(t, err)
+}
+
+func TestParseWindow(t *testing.T) {
+	d, err := parseWindow("30d")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, d)
+
+	d, err = parseWindow("36h")
+	assert.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, d)
+
+	_, err = parseWindow("0d")
+	assert.Error(t, err)
+}
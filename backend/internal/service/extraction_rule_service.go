@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+type ExtractionRuleService struct {
+	repo repository.ExtractionRuleRepository
+}
+
+func NewExtractionRuleService(repo repository.ExtractionRuleRepository) (*ExtractionRuleService, error) {
+	if repo == nil {
+		return nil, errors.New("ExtractionRuleRepository must not be nil")
+	}
+	return &ExtractionRuleService{repo: repo}, nil
+}
+
+// CreateRule validates and stores a rule scoped to exactly one of a URL or a tag
+func (s *ExtractionRuleService) CreateRule(ctx context.Context, req models.CreateExtractionRuleRequest) (*models.ExtractionRuleResponse, error) {
+	if (req.URLID == nil) == (req.Tag == nil) {
+		return nil, errors.New("exactly one of url_id or tag is required")
+	}
+	rule := models.ExtractionRule{
+		Name:         req.Name,
+		URLID:        req.URLID,
+		SelectorType: req.SelectorType,
+		Selector:     req.Selector,
+		Attribute:    emptyToNil(req.Attribute),
+		Multiple:     req.Multiple,
+		Regex:        emptyToNil(req.Regex),
+	}
+	if req.Tag != nil {
+		tag := normalizeTag(*req.Tag)
+		rule.Tag = &tag
+	}
+	if err := crawler.ValidateRule(toCrawlerRule(rule)); err != nil {
+		return nil, err
+	}
+
+	rec, err := s.repo.Create(ctx, rule)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if req.URLID != nil {
+				return nil, errors.New("url not found")
+			}
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+	resp := toRuleResponse(*rec)
+	return &resp, nil
+}
+
+func (s *ExtractionRuleService) ListRules(ctx context.Context) ([]models.ExtractionRuleResponse, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.ExtractionRuleResponse, 0, len(rows))
+	for _, r := range rows {
+		resp = append(resp, toRuleResponse(r))
+	}
+	return resp, nil
+}
+
+func (s *ExtractionRuleService) DeleteRule(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// rulesForURL returns the crawler rules that apply to a URL. When a URL rule and a tag rule
+// share a name, the URL rule wins.
+func rulesForURL(ctx context.Context, repo repository.ExtractionRuleRepository, urlID int64) ([]crawler.ExtractionRule, error) {
+	rows, err := repo.ListForURL(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(rows))
+	out := make([]crawler.ExtractionRule, 0, len(rows))
+	for _, r := range rows {
+		if seen[r.Name] {
+			continue
+		}
+		seen[r.Name] = true
+		out = append(out, toCrawlerRule(r))
+	}
+	return out, nil
+}
+
+func toCrawlerRule(r models.ExtractionRule) crawler.ExtractionRule {
+	out := crawler.ExtractionRule{
+		Name:     r.Name,
+		Type:     r.SelectorType,
+		Selector: r.Selector,
+		Multiple: r.Multiple,
+	}
+	if r.Attribute != nil {
+		out.Attribute = *r.Attribute
+	}
+	if r.Regex != nil {
+		out.Regex = *r.Regex
+	}
+	return out
+}
+
+func toRuleResponse(r models.ExtractionRule) models.ExtractionRuleResponse {
+	return models.ExtractionRuleResponse{
+		ID:           r.ID,
+		Name:         r.Name,
+		URLID:        r.URLID,
+		Tag:          r.Tag,
+		SelectorType: r.SelectorType,
+		Selector:     r.Selector,
+		Attribute:    r.Attribute,
+		Multiple:     r.Multiple,
+		Regex:        r.Regex,
+	}
+}
+
+func emptyToNil(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+func TestExtractionRuleService_CreateRule_TagScoped(t *testing.T) {
+	ctx := context.Background()
+	tag := " Shop "
+	normalized := "shop"
+
+	mockRepo := new(mocks.ExtractionRuleRepository)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(r models.ExtractionRule) bool {
+		return r.Name == "price" && r.Tag != nil && *r.Tag == normalized && r.URLID == nil && r.Regex == nil
+	})).Return(&models.ExtractionRule{ID: 7, Name: "price", Tag: &normalized, SelectorType: "css", Selector: ".price"}, nil)
+
+	svc, err := NewExtractionRuleService(mockRepo)
+	assert.NoError(t, err)
+
+	empty := ""
+	resp, err := svc.CreateRule(ctx, models.CreateExtractionRuleRequest{
+		Name: "price", Tag: &tag, SelectorType: "css", Selector: ".price", Regex: &empty,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), resp.ID)
+	assert.Equal(t, normalized, *resp.Tag)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExtractionRuleService_CreateRule_RejectsInvalidRules(t *testing.T) {
+	ctx := context.Background()
+	urlID := int64(1)
+	tag := "shop"
+
+	mockRepo := new(mocks.ExtractionRuleRepository)
+	svc, err := NewExtractionRuleService(mockRepo)
+	assert.NoError(t, err)
+
+	badRegex := "("
+	cases := []models.CreateExtractionRuleRequest{
+		{Name: "both", URLID: &urlID, Tag: &tag, SelectorType: "css", Selector: "h1"},
+		{Name: "none", SelectorType: "css", Selector: "h1"},
+		{Name: "css", URLID: &urlID, SelectorType: "css", Selector: "h1["},
+		{Name: "xpath", URLID: &urlID, SelectorType: "xpath", Selector: "//h1["},
+		{Name: "regex", URLID: &urlID, SelectorType: "css", Selector: "h1", Regex: &badRegex},
+	}
+	for _, req := range cases {
+		_, err := svc.CreateRule(ctx, req)
+		assert.Error(t, err, req.Name)
+	}
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestExtractionRuleService_CreateRule_UnknownURL(t *testing.T) {
+	ctx := context.Background()
+	urlID := int64(404)
+
+	mockRepo := new(mocks.ExtractionRuleRepository)
+	mockRepo.On("Create", ctx, mock.Anything).Return(nil, sql.ErrNoRows)
+	svc, err := NewExtractionRuleService(mockRepo)
+	assert.NoError(t, err)
+
+	_, err = svc.CreateRule(ctx, models.CreateExtractionRuleRequest{Name: "price", URLID: &urlID, SelectorType: "css", Selector: ".price"})
+	assert.EqualError(t, err, "url not found")
+}
+
+func TestRulesForURL_URLRuleWinsOverTagRule(t *testing.T) {
+	ctx := context.Background()
+	urlID := int64(5)
+	urlRule, tagRule := urlID, "shop"
+	attr := "content"
+
+	mockRepo := new(mocks.ExtractionRuleRepository)
+	mockRepo.On("ListForURL", ctx, urlID).Return([]models.ExtractionRule{
+		{ID: 2, Name: "price", URLID: &urlRule, SelectorType: "css", Selector: "#price"},
+		{ID: 1, Name: "price", Tag: &tagRule, SelectorType: "css", Selector: ".price"},
+		{ID: 3, Name: "author", Tag: &tagRule, SelectorType: "css", Selector: "meta[name=author]", Attribute: &attr},
+	}, nil)
+
+	rules, err := rulesForURL(ctx, mockRepo, urlID)
+
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.Equal(t, "#price", rules[0].Selector)
+	assert.Equal(t, "author", rules[1].Name)
+	assert.Equal(t, "content", rules[1].Attribute)
+	mockRepo.AssertExpectations(t)
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -19,13 +23,25 @@ type jobTask struct {
 	jobID int64
 	urlID int64
 	url   string
+	opts  models.JobOptions
 }
 
 type JobService struct {
-	jobs    repository.JobRepository
-	results repository.ResultRepository
-	urls    repository.URLRepository
-	craw    *crawler.Crawler
+	jobs     repository.JobRepository
+	results  repository.ResultRepository
+	urls     repository.URLRepository
+	craw     *crawler.Crawler
+	rules    repository.ExtractionRuleRepository // optional
+	certs    *CertificateService                 // optional
+	profiles *RequestProfileService              // optional
+	logins   *LoginRecipeService                 // optional
+	proxies  *ProxyService                       // optional
+	snaps    *SnapshotService                    // optional
+	// box keys the inputs hash of results; set with the profiles or login recipes whose secrets
+	// go into it
+	box *SecretBox
+
+	conditional bool // revalidate pages against their last result instead of refetching them
 
 	// Worker pool for parallel job processing
 	jobQueue chan jobTask
@@ -35,7 +51,56 @@ type JobService struct {
 	stop     chan struct{}
 }
 
-func NewJobService(j repository.JobRepository, r repository.ResultRepository, u repository.URLRepository, c *crawler.Crawler) (*JobService, error) {
+// JobServiceOption wires optional dependencies into the job service
+type JobServiceOption func(*JobService)
+
+// WithExtractionRules makes jobs evaluate the extraction rules that apply to their URL
+func WithExtractionRules(repo repository.ExtractionRuleRepository) JobServiceOption {
+	return func(s *JobService) { s.rules = repo }
+}
+
+// WithCertificateMonitor records the certificate seen by each crawl and raises expiry alerts
+func WithCertificateMonitor(certs *CertificateService) JobServiceOption {
+	return func(s *JobService) { s.certs = certs }
+}
+
+// WithRequestProfiles makes jobs fetch their URL with the request profile that applies to it
+func WithRequestProfiles(profiles *RequestProfileService) JobServiceOption {
+	return func(s *JobService) {
+		s.profiles = profiles
+		if profiles != nil {
+			s.box = profiles.box
+		}
+	}
+}
+
+// WithLoginRecipes makes jobs log in first when their URL references a login recipe
+func WithLoginRecipes(logins *LoginRecipeService) JobServiceOption {
+	return func(s *JobService) {
+		s.logins = logins
+		if logins != nil {
+			s.box = logins.box
+		}
+	}
+}
+
+// WithProxyPools makes jobs crawl through the proxy pool that applies to their URL
+func WithProxyPools(proxies *ProxyService) JobServiceOption {
+	return func(s *JobService) { s.proxies = proxies }
+}
+
+// WithSnapshots keeps the raw response of every stored result
+func WithSnapshots(snaps *SnapshotService) JobServiceOption {
+	return func(s *JobService) { s.snaps = snaps }
+}
+
+// WithConditionalFetch sends the ETag and Last-Modified of a URL's last result with its next
+// crawl. A page the server reports unchanged gets a not_modified result repeating the last one.
+func WithConditionalFetch() JobServiceOption {
+	return func(s *JobService) { s.conditional = true }
+}
+
+func NewJobService(j repository.JobRepository, r repository.ResultRepository, u repository.URLRepository, c *crawler.Crawler, opts ...JobServiceOption) (*JobService, error) {
 	if j == nil || r == nil || u == nil {
 		return nil, errors.New("all deps for job service must be not nil")
 	}
@@ -53,6 +118,9 @@ func NewJobService(j repository.JobRepository, r repository.ResultRepository, u
 		workers:  workers,
 		stop:     make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(svc)
+	}
 
 	// Start worker pool
 	svc.startWorkers()
@@ -77,7 +145,7 @@ func (s *JobService) worker(id int) {
 	for {
 		select {
 		case task := <-s.jobQueue:
-			if err := s.process(context.Background(), task.jobID, task.urlID, task.url); err != nil {
+			if err := s.process(context.Background(), task); err != nil {
 				// Log error and attempt to persist to DB
 				logrus.WithError(err).WithFields(logrus.Fields{
 					"worker_id": id,
@@ -104,8 +172,39 @@ func (s *JobService) Shutdown() {
 	s.wg.Wait()
 }
 
-func (s *JobService) StartForURL(ctx context.Context, urlID int64, url string) (int64, error) {
-	job, err := s.jobs.Enqueue(ctx, urlID)
+// ValidateOptions checks job options before any job is enqueued with them
+func (s *JobService) ValidateOptions(opts models.JobOptions) error {
+	_, err := s.craw.Analyzers().Resolve(opts.Analyzers)
+	return err
+}
+
+// AvailableAnalyzers lists the analyzers that can be enabled per job
+func (s *JobService) AvailableAnalyzers() []string {
+	return s.craw.Analyzers().Names()
+}
+
+// Metrics reports the crawler's runtime counters
+func (s *JobService) Metrics() models.MetricsResponse {
+	var out models.MetricsResponse
+	if cache := s.craw.LinkCache(); cache != nil {
+		st := cache.Stats()
+		out.LinkCache = &models.LinkCacheMetrics{
+			Hits:         st.Hits,
+			StoreHits:    st.StoreHits,
+			Misses:       st.Misses,
+			Deduplicated: st.Deduplicated,
+			StoreErrors:  st.StoreErrors,
+			Entries:      st.Entries,
+		}
+		if total := st.Hits + st.StoreHits + st.Misses + st.Deduplicated; total > 0 {
+			out.LinkCache.HitRatio = float64(total-st.Misses) / float64(total)
+		}
+	}
+	return out
+}
+
+func (s *JobService) StartForURL(ctx context.Context, urlID int64, url string, opts models.JobOptions) (int64, error) {
+	job, err := s.jobs.Enqueue(ctx, urlID, opts)
 	if err != nil {
 		return 0, err
 	}
@@ -113,7 +212,7 @@ func (s *JobService) StartForURL(ctx context.Context, urlID int64, url string) (
 	// Enqueue job for parallel processing through worker pool
 	// This will block if the queue is full, ensuring we respect the concurrency limit
 	select {
-	case s.jobQueue <- jobTask{jobID: job.ID, urlID: urlID, url: url}:
+	case s.jobQueue <- jobTask{jobID: job.ID, urlID: urlID, url: url, opts: opts}:
 		// Job queued successfully, will be processed by a worker
 	case <-ctx.Done():
 		return 0, ctx.Err()
@@ -176,9 +275,20 @@ func (s *JobService) GetURLByID(ctx context.Context, urlID int64) (*models.URL,
 	return s.urls.GetByID(ctx, urlID)
 }
 
+// GetURLsByTag returns all URLs carrying the tag, used for bulk job start/stop
+func (s *JobService) GetURLsByTag(ctx context.Context, tag string) ([]models.URL, error) {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return nil, ErrEmptyTag
+	}
+	return s.urls.ListByTag(ctx, tag)
+}
+
 // process executes a crawl job and returns an error if any step fails
 // Errors are logged and persisted to the database by the caller (worker)
-func (s *JobService) process(ctx context.Context, jobID int64, urlID int64, target string) error {
+func (s *JobService) process(ctx context.Context, task jobTask) error {
+	jobID, urlID, target := task.jobID, task.urlID, task.url
+
 	// Update job status to running
 	// If this fails with sql.ErrNoRows, it means the job was already stopped/updated by another goroutine
 	if err := s.jobs.UpdateStatus(ctx, jobID, models.JobRunning, nil); err != nil {
@@ -190,20 +300,126 @@ func (s *JobService) process(ctx context.Context, jobID int64, urlID int64, targ
 	}
 
 	// Execute crawl
-	res, err := s.craw.Crawl(ctx, target)
+	crawlOpts := crawler.Options{Analyzers: task.opts.Analyzers, FetchResources: task.opts.FetchResources, Snapshot: s.snaps != nil}
+	crawlOpts.SnapshotLinks = crawlOpts.Snapshot && s.snaps.Archives() && task.opts.ArchiveLinks
+	if s.rules != nil {
+		rules, err := rulesForURL(ctx, s.rules, urlID)
+		if err != nil {
+			return fmt.Errorf("failed to load extraction rules: %w", err)
+		}
+		crawlOpts.Rules = rules
+	}
+	if s.profiles != nil {
+		profile, err := s.profiles.ProfileForURL(ctx, urlID)
+		if err != nil {
+			return fmt.Errorf("failed to load request profile: %w", err)
+		}
+		crawlOpts.Profile = profile
+	}
+	if s.logins != nil {
+		recipe, err := s.logins.RecipeForURL(ctx, urlID)
+		if err != nil {
+			return fmt.Errorf("failed to load login recipe: %w", err)
+		}
+		crawlOpts.Login = recipe
+	}
+	var pool *crawler.ProxyPool
+	if s.proxies != nil {
+		var err error
+		if pool, err = s.proxies.PoolForURL(ctx, urlID); err != nil {
+			return fmt.Errorf("failed to load proxy pool: %w", err)
+		}
+		if pool != nil {
+			crawlOpts.Proxy = pool.Pick()
+		}
+	}
+
+	// the last result is what a conditional fetch revalidates and what changes are measured against
+	prev := s.lastResult(ctx, urlID)
+	inputs := inputsHash(crawlOpts, s.box)
+	if s.conditional && !task.opts.Refetch && s.revalidatable(ctx, prev, task.opts, inputs) {
+		crawlOpts.Conditional = &crawler.Validators{}
+		if prev.ETag != nil {
+			crawlOpts.Conditional.ETag = *prev.ETag
+		}
+		if prev.LastModified != nil {
+			crawlOpts.Conditional.LastModified = *prev.LastModified
+		}
+		if task.opts.RecheckLinks {
+			if err := prev.Links.Decode(&crawlOpts.RecheckLinks); err != nil {
+				logrus.WithError(err).WithField("url_id", urlID).Warn("Failed to decode the last result's links")
+			}
+		}
+	}
+
+	res, err := s.craw.CrawlWithOptions(ctx, target, crawlOpts)
+	if pool != nil {
+		pool.Report(crawlOpts.Proxy, err)
+	}
+	// The certificate is recorded even when the crawl failed on it
+	if s.certs != nil && res.Certificate != nil {
+		if certErr := s.certs.RecordCertificate(ctx, urlID, res.Certificate); certErr != nil {
+			logrus.WithError(certErr).WithField("url_id", urlID).Warn("Failed to record certificate")
+		}
+	}
 	if err != nil {
-		// Crawl failed - error will be persisted by worker
-		return fmt.Errorf("crawl failed: %w", err)
+		// Crawl failed - error will be persisted by worker, led by its class where known
+		failed := "crawl failed"
+		if crawlOpts.Proxy != nil {
+			failed = fmt.Sprintf("crawl through proxy %s failed", crawler.RedactProxy(crawlOpts.Proxy))
+		}
+		if class := crawler.ClassifyError(err); class != crawler.ErrorClassOther {
+			failed += " (" + class + ")"
+		}
+		return fmt.Errorf("%s: %w", failed, err)
+	}
+
+	if res.Outcome == crawler.OutcomeNotModified && prev != nil {
+		rec, err := notModifiedResult(*prev, jobID, res, len(crawlOpts.RecheckLinks) > 0)
+		if err != nil {
+			return fmt.Errorf("failed to encode link errors: %w", err)
+		}
+		stored, err := s.results.Create(ctx, rec)
+		if err != nil {
+			return fmt.Errorf("failed to persist crawl results: %w", err)
+		}
+		s.saveSnapshot(ctx, rec, stored, res.Snapshot)
+		return s.complete(ctx, jobID)
+	}
+
+	findings, err := models.NewJSON(res.Findings)
+	if err != nil {
+		return fmt.Errorf("failed to encode analyzer findings: %w", err)
+	}
+	linkErrors, err := models.NewJSON(res.LinkErrors)
+	if err != nil {
+		return fmt.Errorf("failed to encode link errors: %w", err)
+	}
+	links, err := models.NewJSON(res.Links)
+	if err != nil {
+		return fmt.Errorf("failed to encode links: %w", err)
 	}
 
 	// Persist results
 	var htmlVer, title *string
 	htmlVer = res.HTMLVersion
 	title = res.Title
-	_, err = s.results.Create(ctx, models.CrawlResult{
+	rec := models.CrawlResult{
 		JobID:                  jobID,
 		URLID:                  urlID,
+		Outcome:                res.Outcome,
+		ContentType:            emptyToNil(&res.ContentType),
+		BodyBytes:              res.BodyBytes,
+		Truncated:              res.Truncated,
+		Proxy:                  emptyToNil(&res.Proxy),
+		ETag:                   emptyToNil(&res.Validators.ETag),
+		LastModified:           emptyToNil(&res.Validators.LastModified),
+		InputsHash:             &inputs,
 		HTMLVersion:            htmlVer,
+		DocumentMode:           emptyToNil(&res.DocumentMode),
+		Charset:                emptyToNil(&res.Charset.Name),
+		CharsetSource:          emptyToNil(&res.Charset.Source),
+		CharsetMismatch:        res.Charset.Mismatch,
 		Title:                  title,
 		HeadingsH1:             res.Headings["h1"],
 		HeadingsH2:             res.Headings["h2"],
@@ -214,12 +430,38 @@ func (s *JobService) process(ctx context.Context, jobID int64, urlID int64, targ
 		InternalLinksCount:     res.InternalLinks,
 		ExternalLinksCount:     res.ExternalLinks,
 		InaccessibleLinksCount: res.InaccessibleLinks,
+		LinkErrors:             linkErrors,
+		Links:                  links,
 		HasLoginForm:           res.HasLoginForm,
-	})
+		Findings:               findings,
+	}
+	if res.Content.Hash != "" {
+		rec.ContentHash = &res.Content.Hash
+		rec.SimHash = &res.Content.SimHash
+		compareContent(&rec, prev)
+	}
+	stored, err := s.results.Create(ctx, rec)
 	if err != nil {
 		return fmt.Errorf("failed to persist crawl results: %w", err)
 	}
+	s.saveSnapshot(ctx, rec, stored, res.Snapshot)
+	return s.complete(ctx, jobID)
+}
 
+// saveSnapshot keeps the raw response of rec, stored as stored. A snapshot is a debugging aid,
+// so failing to save one doesn't fail the job.
+func (s *JobService) saveSnapshot(ctx context.Context, rec models.CrawlResult, stored *models.CrawlResult, snap *crawler.Snapshot) {
+	if s.snaps == nil || stored == nil || snap == nil {
+		return
+	}
+	rec.ID = stored.ID
+	if err := s.snaps.Save(ctx, rec, snap); err != nil {
+		logrus.WithError(err).WithField("result_id", stored.ID).Warn("Failed to save snapshot")
+	}
+}
+
+// complete marks a processed job as done
+func (s *JobService) complete(ctx context.Context, jobID int64) error {
 	// Update job status to done
 	// If this fails with sql.ErrNoRows, it means the job was already stopped/updated by another goroutine
 	if err := s.jobs.UpdateStatus(ctx, jobID, models.JobCompleted, nil); err != nil {
@@ -232,3 +474,109 @@ func (s *JobService) process(ctx context.Context, jobID int64, urlID int64, targ
 
 	return nil
 }
+
+// lastResult returns the last result of a URL, or nil if it has none or it can't be loaded
+func (s *JobService) lastResult(ctx context.Context, urlID int64) *models.CrawlResult {
+	prev, err := s.results.GetByURLID(ctx, urlID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logrus.WithError(err).WithField("url_id", urlID).Warn("Failed to load the last result")
+		}
+		return nil
+	}
+	return prev
+}
+
+// revalidatable reports whether the next crawl may be conditional on prev: it has validators,
+// it was crawled with the same inputs (see inputsHash) and its job ran the same analyzers,
+// since a not_modified result repeats its findings. Lookup failures just mean a full fetch.
+func (s *JobService) revalidatable(ctx context.Context, prev *models.CrawlResult, opts models.JobOptions, inputs string) bool {
+	if prev == nil || (prev.ETag == nil && prev.LastModified == nil) {
+		return false
+	}
+	if prev.InputsHash == nil || *prev.InputsHash != inputs {
+		return false
+	}
+	job, err := s.jobs.GetByID(ctx, prev.JobID)
+	if err != nil {
+		logrus.WithError(err).WithField("job_id", prev.JobID).Warn("Failed to load the last job; fetching in full")
+		return false
+	}
+	var prevOpts models.JobOptions
+	if err := job.Options.Decode(&prevOpts); err != nil {
+		return false
+	}
+	return prevOpts.FetchResources == opts.FetchResources && sameAnalyzers(prevOpts.Analyzers, opts.Analyzers)
+}
+
+// compareContent records whether rec's visible text changed since prev, and how similar the two
+// are. Only complete pages are compared: a truncated or partly parsed page would look changed.
+func compareContent(rec *models.CrawlResult, prev *models.CrawlResult) {
+	if prev == nil || prev.ContentHash == nil || prev.SimHash == nil {
+		return
+	}
+	if !completeOutcome(rec.Outcome) || !completeOutcome(prev.Outcome) {
+		return
+	}
+	changed := *rec.ContentHash != *prev.ContentHash
+	similarity := crawler.Similarity(*rec.SimHash, *prev.SimHash)
+	rec.ContentChanged = &changed
+	rec.Similarity = &similarity
+}
+
+// completeOutcome reports whether a result with this outcome describes the whole page
+func completeOutcome(outcome string) bool {
+	return outcome == crawler.OutcomeOK || outcome == crawler.OutcomeNotModified
+}
+
+func sameAnalyzers(a, b []string) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// notModifiedResult repeats prev for a crawl that found the page unchanged. When its links were
+// rechecked, the link errors are those of the new checks.
+func notModifiedResult(prev models.CrawlResult, jobID int64, res crawler.Result, rechecked bool) (models.CrawlResult, error) {
+	out := prev
+	out.ID = 0
+	out.JobID = jobID
+	out.Outcome = crawler.OutcomeNotModified
+	out.Proxy = emptyToNil(&res.Proxy)
+	out.ETag = emptyToNil(&res.Validators.ETag)
+	out.LastModified = emptyToNil(&res.Validators.LastModified)
+	out.PreviousResultID = &prev.ID
+	out.ContentChanged, out.Similarity = nil, nil
+	if prev.ContentHash != nil {
+		compareContent(&out, &prev)
+	}
+	if rechecked {
+		linkErrors, err := models.NewJSON(res.LinkErrors)
+		if err != nil {
+			return out, err
+		}
+		out.LinkErrors, out.InaccessibleLinksCount = linkErrors, res.InaccessibleLinks
+	}
+	return out, nil
+}
+
+// inputsHash fingerprints what a crawl depends on besides the page and its analyzers: the
+// extraction rules, the request profile and the login recipe. The proxy is left out, since
+// pools rotate through theirs. Profiles and recipes carry credentials, so the hash is keyed
+// with the box their secrets are sealed with; box is only nil when there are neither.
+func inputsHash(opts crawler.Options, box *SecretBox) string {
+	b, _ := json.Marshal(struct {
+		Rules   []crawler.ExtractionRule
+		Profile *crawler.RequestProfile
+		Login   *crawler.LoginRecipe
+	}{opts.Rules, opts.Profile, opts.Login})
+	if box != nil {
+		return box.Sum(b)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
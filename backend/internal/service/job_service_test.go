@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,10 +23,17 @@ func createTestCrawler(html string) (*crawler.Crawler, *httptest.Server) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		_, _ = w.Write([]byte(html))
 	}))
-	c := crawler.New(crawler.HTTPClient(5 * time.Second))
+	c := crawler.New(crawler.HTTPClient(5*time.Second, crawler.WithoutAddressGuard()))
 	return c, ts
 }
 
+// newFirstCrawlResults mocks the results of URLs that haven't been crawled before
+func newFirstCrawlResults() *mocks.ResultRepository {
+	results := new(mocks.ResultRepository)
+	results.On("GetByURLID", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows).Maybe()
+	return results
+}
+
 func TestJobService_StartForURL_HappyPath(t *testing.T) {
 	ctx := context.Background()
 	urlID := int64(123)
@@ -32,7 +41,7 @@ func TestJobService_StartForURL_HappyPath(t *testing.T) {
 	expectedJobID := int64(456)
 
 	mockJobs := new(mocks.JobRepository)
-	mockJobs.On("Enqueue", ctx, urlID).Return(&models.CrawlJob{
+	mockJobs.On("Enqueue", ctx, urlID, models.JobOptions{}).Return(&models.CrawlJob{
 		ID:     expectedJobID,
 		URLID:  urlID,
 		Status: models.JobQueued,
@@ -41,17 +50,17 @@ func TestJobService_StartForURL_HappyPath(t *testing.T) {
 	// The goroutine will try to call UpdateStatus, so we need to allow it (but it may fail)
 	// Use Maybe() to allow the call but not require it
 	mockJobs.On("UpdateStatus", mock.Anything, expectedJobID, mock.Anything, mock.Anything).Return(nil).Maybe()
-	mockResults := new(mocks.ResultRepository)
+	mockResults := newFirstCrawlResults()
 	mockResults.On("Create", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 
 	mockURLs := new(mocks.URLRepository)
-	realCrawler := crawler.New(crawler.HTTPClient(1 * time.Second))
+	realCrawler := crawler.New(crawler.HTTPClient(1*time.Second, crawler.WithoutAddressGuard()))
 
 	svc, err := NewJobService(mockJobs, mockResults, mockURLs, realCrawler)
 	assert.NoError(t, err, "NewJobService should not return error")
 	defer svc.Shutdown()
 
-	jobID, err := svc.StartForURL(ctx, urlID, url)
+	jobID, err := svc.StartForURL(ctx, urlID, url, models.JobOptions{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedJobID, jobID)
@@ -89,7 +98,7 @@ func TestJobService_Process_HappyPath(t *testing.T) {
 	mockJobs.On("UpdateStatus", mock.Anything, jobID, models.JobRunning, (*string)(nil)).Return(nil)
 	mockJobs.On("UpdateStatus", mock.Anything, jobID, models.JobCompleted, (*string)(nil)).Return(nil)
 
-	mockResults := new(mocks.ResultRepository)
+	mockResults := newFirstCrawlResults()
 	mockResults.On("Create", mock.Anything, mock.MatchedBy(func(res models.CrawlResult) bool {
 		return res.JobID == jobID &&
 			res.URLID == urlID &&
@@ -100,7 +109,8 @@ func TestJobService_Process_HappyPath(t *testing.T) {
 			res.HeadingsH3 == 1 &&
 			res.InternalLinksCount == 2 &&
 			res.ExternalLinksCount == 1 &&
-			res.HasLoginForm == true
+			res.HasLoginForm == true &&
+			strings.Contains(string(res.Findings), `"forms"`)
 	})).Return(&models.CrawlResult{
 		ID:    1,
 		JobID: jobID,
@@ -114,10 +124,189 @@ func TestJobService_Process_HappyPath(t *testing.T) {
 	defer svc.Shutdown()
 
 	// Call process directly (not via worker pool) for deterministic testing
-	err = svc.process(ctx, jobID, urlID, ts.URL)
+	err = svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL})
 	assert.NoError(t, err, "process should complete successfully")
 
 	// Verify all expectations were met
 	mockJobs.AssertExpectations(t)
 	mockResults.AssertExpectations(t)
 }
+
+func TestJobService_Process_NotModifiedRepeatsLastResult(t *testing.T) {
+	ctx := context.Background()
+	jobID, prevJobID, urlID := int64(20), int64(19), int64(7)
+	etag := `"abc"`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>changed</title></head></html>`))
+	}))
+	defer ts.Close()
+	cr := crawler.New(crawler.HTTPClient(5*time.Second, crawler.WithoutAddressGuard()))
+
+	title := "Old title"
+	inputs := inputsHash(crawler.Options{}, nil)
+	prev := &models.CrawlResult{ID: 3, JobID: prevJobID, URLID: urlID, Outcome: crawler.OutcomeOK, ETag: &etag, InputsHash: &inputs, Title: &title, InternalLinksCount: 4}
+	mockResults := new(mocks.ResultRepository)
+	mockResults.On("GetByURLID", mock.Anything, urlID).Return(prev, nil)
+	mockResults.On("Create", mock.Anything, mock.MatchedBy(func(res models.CrawlResult) bool {
+		return res.JobID == jobID && res.Outcome == crawler.OutcomeNotModified &&
+			res.PreviousResultID != nil && *res.PreviousResultID == prev.ID &&
+			res.Title != nil && *res.Title == title && res.InternalLinksCount == 4 &&
+			res.ETag != nil && *res.ETag == etag
+	})).Return(&models.CrawlResult{ID: 4}, nil).Once()
+
+	prevOpts, _ := models.NewJSON(models.JobOptions{})
+	mockJobs := new(mocks.JobRepository)
+	mockJobs.On("UpdateStatus", mock.Anything, jobID, models.JobRunning, (*string)(nil)).Return(nil)
+	mockJobs.On("UpdateStatus", mock.Anything, jobID, models.JobCompleted, (*string)(nil)).Return(nil)
+	mockJobs.On("GetByID", mock.Anything, prevJobID).Return(&models.CrawlJob{ID: prevJobID, Options: prevOpts}, nil)
+
+	svc, err := NewJobService(mockJobs, mockResults, new(mocks.URLRepository), cr, WithConditionalFetch())
+	assert.NoError(t, err)
+	defer svc.Shutdown()
+
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL}))
+	mockResults.AssertExpectations(t)
+
+	// other analyzers than last time, an explicit refetch or other rules, profile or login
+	// recipe get the page in full
+	mockResults.On("Create", mock.Anything, mock.MatchedBy(func(res models.CrawlResult) bool {
+		return res.Outcome == crawler.OutcomeOK && res.Title != nil && *res.Title == "changed" && res.PreviousResultID == nil &&
+			res.InputsHash != nil && *res.InputsHash == inputs
+	})).Return(&models.CrawlResult{ID: 5}, nil).Times(3)
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL, opts: models.JobOptions{Analyzers: []string{"title", "headings"}}}))
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL, opts: models.JobOptions{Refetch: true}}))
+	otherInputs := inputsHash(crawler.Options{Profile: &crawler.RequestProfile{UserAgent: "qa-bot"}}, nil)
+	prev.InputsHash = &otherInputs
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL}))
+	mockResults.AssertExpectations(t)
+	// a refetch, or a result crawled with other inputs, doesn't look up the last job's options
+	mockJobs.AssertNumberOfCalls(t, "GetByID", 2)
+}
+
+func TestJobService_Process_NotModifiedRechecksLinks(t *testing.T) {
+	ctx := context.Background()
+	jobID, prevJobID, urlID := int64(24), int64(23), int64(8)
+	etag := `"v1"`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		t.Errorf("expected a conditional request for %s", r.URL)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/gone", http.NotFound)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	cr := crawler.New(crawler.HTTPClient(5*time.Second, crawler.WithoutAddressGuard()))
+
+	inputs := inputsHash(crawler.Options{}, nil)
+	links, _ := models.NewJSON([]string{ts.URL + "/ok", ts.URL + "/gone"})
+	prev := &models.CrawlResult{ID: 5, JobID: prevJobID, URLID: urlID, Outcome: crawler.OutcomeOK, ETag: &etag, InputsHash: &inputs,
+		InternalLinksCount: 2, Links: links}
+	mockResults := new(mocks.ResultRepository)
+	mockResults.On("GetByURLID", mock.Anything, urlID).Return(prev, nil)
+	mockResults.On("Create", mock.Anything, mock.MatchedBy(func(res models.CrawlResult) bool {
+		return res.Outcome == crawler.OutcomeNotModified && res.PreviousResultID != nil && *res.PreviousResultID == prev.ID &&
+			res.InaccessibleLinksCount == 1 && string(res.LinkErrors) == `{"http_status":1}` && string(res.Links) == string(links)
+	})).Return(&models.CrawlResult{ID: 6}, nil).Once()
+
+	prevOpts, _ := models.NewJSON(models.JobOptions{})
+	mockJobs := new(mocks.JobRepository)
+	mockJobs.On("UpdateStatus", mock.Anything, jobID, mock.Anything, (*string)(nil)).Return(nil)
+	mockJobs.On("GetByID", mock.Anything, prevJobID).Return(&models.CrawlJob{ID: prevJobID, Options: prevOpts}, nil)
+
+	svc, err := NewJobService(mockJobs, mockResults, new(mocks.URLRepository), cr, WithConditionalFetch())
+	assert.NoError(t, err)
+	defer svc.Shutdown()
+
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL, opts: models.JobOptions{RecheckLinks: true}}))
+	mockResults.AssertExpectations(t)
+}
+
+func TestJobService_Process_DetectsContentChanges(t *testing.T) {
+	ctx := context.Background()
+	jobID, urlID := int64(30), int64(31)
+	text := "The quick brown fox jumps over the lazy dog while the cat sleeps in the warm afternoon sun"
+
+	realCrawler, ts := createTestCrawler(`<html><body><p>` + text + `.</p><script>var t = Date.now()</script></body></html>`)
+	defer ts.Close()
+	first, err := realCrawler.Crawl(ctx, ts.URL)
+	assert.NoError(t, err)
+	same, sameSim := first.Content.Hash, first.Content.SimHash
+
+	editedTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><p>` + text + ` on Sunday.</p></body></html>`))
+	}))
+	defer editedTS.Close()
+
+	prev := &models.CrawlResult{ID: 1, JobID: 2, URLID: urlID, Outcome: crawler.OutcomeOK, ContentHash: &same, SimHash: &sameSim}
+	truncated := &models.CrawlResult{ID: 3, JobID: 2, URLID: urlID + 1, Outcome: crawler.OutcomeTruncated, ContentHash: &same, SimHash: &sameSim}
+	mockResults := new(mocks.ResultRepository)
+	mockResults.On("GetByURLID", mock.Anything, urlID).Return(prev, nil)
+	mockResults.On("GetByURLID", mock.Anything, urlID+1).Return(truncated, nil)
+	var stored []models.CrawlResult
+	mockResults.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = append(stored, args.Get(1).(models.CrawlResult))
+	}).Return(&models.CrawlResult{ID: 9}, nil)
+	mockJobs := new(mocks.JobRepository)
+	mockJobs.On("UpdateStatus", mock.Anything, jobID, mock.Anything, (*string)(nil)).Return(nil)
+
+	svc, err := NewJobService(mockJobs, mockResults, new(mocks.URLRepository), realCrawler)
+	assert.NoError(t, err)
+	defer svc.Shutdown()
+
+	// scripts aren't visible text, so the same page re-rendered hasn't changed
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL}))
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: editedTS.URL}))
+	// a cut-off previous page isn't compared against
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID + 1, url: ts.URL}))
+	if assert.Len(t, stored, 3) {
+		unchanged, changed, uncompared := stored[0], stored[1], stored[2]
+		assert.Nil(t, uncompared.ContentChanged)
+		assert.Nil(t, uncompared.Similarity)
+		if assert.NotNil(t, unchanged.ContentChanged) && assert.NotNil(t, unchanged.Similarity) {
+			assert.False(t, *unchanged.ContentChanged)
+			assert.Equal(t, 1.0, *unchanged.Similarity)
+		}
+		if assert.NotNil(t, changed.ContentChanged) && assert.NotNil(t, changed.Similarity) {
+			assert.True(t, *changed.ContentChanged)
+			assert.Greater(t, *changed.Similarity, 0.75, "a small edit keeps the pages similar")
+			assert.Less(t, *changed.Similarity, 1.0)
+		}
+	}
+}
+
+func TestJobService_Process_SavesSnapshot(t *testing.T) {
+	ctx := context.Background()
+	jobID, urlID := int64(40), int64(41)
+	realCrawler, ts := createTestCrawler(`<html><head><title>snap</title></head></html>`)
+	defer ts.Close()
+
+	mockResults := newFirstCrawlResults()
+	mockResults.On("Create", mock.Anything, mock.Anything).Return(&models.CrawlResult{ID: 42}, nil)
+	mockJobs := new(mocks.JobRepository)
+	mockJobs.On("UpdateStatus", mock.Anything, jobID, mock.Anything, (*string)(nil)).Return(nil)
+	mockSnaps := new(mocks.SnapshotRepository)
+	mockSnaps.On("Create", mock.Anything, mock.MatchedBy(func(snap models.ResultSnapshot) bool {
+		return snap.ResultID == 42 && snap.URLID == urlID && snap.SizeBytes > 0
+	})).Return(&models.ResultSnapshot{ID: 1}, nil).Once()
+
+	snaps, err := NewSnapshotService(mockSnaps, newTestBlobStore(t), SnapshotRetention{})
+	assert.NoError(t, err)
+	svc, err := NewJobService(mockJobs, mockResults, new(mocks.URLRepository), realCrawler, WithSnapshots(snaps))
+	assert.NoError(t, err)
+	defer svc.Shutdown()
+
+	assert.NoError(t, svc.process(ctx, jobTask{jobID: jobID, urlID: urlID, url: ts.URL}))
+	mockSnaps.AssertExpectations(t)
+}
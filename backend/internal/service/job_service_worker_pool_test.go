@@ -28,15 +28,15 @@ func TestJobService_WorkerPool_ConcurrentExecution(t *testing.T) {
 	defer ts.Close()
 
 	mockJobs := new(mocks.JobRepository)
-	mockResults := new(mocks.ResultRepository)
+	mockResults := newFirstCrawlResults()
 	mockURLs := new(mocks.URLRepository)
-	realCrawler := crawler.New(crawler.HTTPClient(5 * time.Second))
+	realCrawler := crawler.New(crawler.HTTPClient(5*time.Second, crawler.WithoutAddressGuard()))
 
 	for i := range numJobs {
 		jobID := int64(i + 1)
 		urlID := int64(i + 100)
 
-		mockJobs.On("Enqueue", ctx, urlID).Return(&models.CrawlJob{
+		mockJobs.On("Enqueue", ctx, urlID, models.JobOptions{}).Return(&models.CrawlJob{
 			ID:     jobID,
 			URLID:  urlID,
 			Status: models.JobQueued,
@@ -56,7 +56,7 @@ func TestJobService_WorkerPool_ConcurrentExecution(t *testing.T) {
 		go func(idx int) {
 			defer wg.Done()
 			urlID := int64(idx + 100)
-			_, err := svc.StartForURL(ctx, urlID, ts.URL)
+			_, err := svc.StartForURL(ctx, urlID, ts.URL, models.JobOptions{})
 			assert.NoError(t, err)
 		}(i)
 	}
@@ -72,23 +72,23 @@ func TestJobService_WorkerPool_Shutdown(t *testing.T) {
 	jobID := int64(456)
 
 	mockJobs := new(mocks.JobRepository)
-	mockJobs.On("Enqueue", ctx, urlID).Return(&models.CrawlJob{
+	mockJobs.On("Enqueue", ctx, urlID, models.JobOptions{}).Return(&models.CrawlJob{
 		ID:     jobID,
 		URLID:  urlID,
 		Status: models.JobQueued,
 	}, nil).Once()
 
 	mockJobs.On("UpdateStatus", mock.Anything, jobID, mock.Anything, mock.Anything).Return(nil).Maybe()
-	mockResults := new(mocks.ResultRepository)
+	mockResults := newFirstCrawlResults()
 	mockResults.On("Create", mock.Anything, mock.Anything).Return(&models.CrawlResult{ID: 1}, nil).Maybe()
 
 	mockURLs := new(mocks.URLRepository)
-	realCrawler := crawler.New(crawler.HTTPClient(1 * time.Second))
+	realCrawler := crawler.New(crawler.HTTPClient(1*time.Second, crawler.WithoutAddressGuard()))
 
 	svc, err := NewJobService(mockJobs, mockResults, mockURLs, realCrawler)
 	assert.NoError(t, err)
 
-	_, err = svc.StartForURL(ctx, urlID, "http://example.com")
+	_, err = svc.StartForURL(ctx, urlID, "http://example.com", models.JobOptions{})
 	assert.NoError(t, err)
 
 	svc.Shutdown()
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+// LinkCheckStore keeps the crawler's link check outcomes in the database (crawler.LinkStore).
+// Checks older than the TTL are pruned at most once per TTL.
+type LinkCheckStore struct {
+	repo repository.LinkCheckRepository
+	ttl  time.Duration
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+func NewLinkCheckStore(repo repository.LinkCheckRepository, ttl time.Duration) (*LinkCheckStore, error) {
+	if repo == nil {
+		return nil, errors.New("LinkCheckRepository must not be nil")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("link check TTL must be positive")
+	}
+	return &LinkCheckStore{repo: repo, ttl: ttl}, nil
+}
+
+func (s *LinkCheckStore) LoadLink(ctx context.Context, key string) (string, time.Time, bool, error) {
+	check, err := s.repo.Get(ctx, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", time.Time{}, false, nil
+		}
+		return "", time.Time{}, false, err
+	}
+	return check.Class, check.CheckedAt, true, nil
+}
+
+func (s *LinkCheckStore) SaveLink(ctx context.Context, key, class string, checkedAt time.Time) error {
+	if err := s.repo.Upsert(ctx, models.LinkCheck{URL: key, Class: class, CheckedAt: checkedAt}); err != nil {
+		return err
+	}
+	s.prune(ctx, checkedAt)
+	return nil
+}
+
+func (s *LinkCheckStore) prune(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	due := now.Sub(s.lastPruned) >= s.ttl
+	if due {
+		s.lastPruned = now
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+	n, err := s.repo.DeleteBefore(ctx, now.Add(-s.ttl))
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to prune expired link checks")
+		return
+	}
+	if n > 0 {
+		logrus.Debugf("Pruned %d expired link checks", n)
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+func TestLinkCheckStore_LoadAndSave(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	mockRepo := new(mocks.LinkCheckRepository)
+	mockRepo.On("Get", mock.Anything, "http://a.example/").Return(nil, sql.ErrNoRows)
+	mockRepo.On("Get", mock.Anything, "http://b.example/").
+		Return(&models.LinkCheck{URL: "http://b.example/", Class: crawler.ErrorClassDNS, CheckedAt: now}, nil)
+	mockRepo.On("Upsert", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("DeleteBefore", mock.Anything, now.Add(-time.Hour)).Return(int64(3), nil).Once()
+
+	store, err := NewLinkCheckStore(mockRepo, time.Hour)
+	assert.NoError(t, err)
+
+	_, _, found, err := store.LoadLink(ctx, "http://a.example/")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	class, at, found, err := store.LoadLink(ctx, "http://b.example/")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, crawler.ErrorClassDNS, class)
+	assert.Equal(t, now, at)
+
+	// expired rows are pruned on the first save, then not again within the TTL
+	assert.NoError(t, store.SaveLink(ctx, "http://a.example/", "", now))
+	assert.NoError(t, store.SaveLink(ctx, "http://c.example/", crawler.ErrorClassTLS, now.Add(time.Minute)))
+	mockRepo.AssertCalled(t, "Upsert", mock.Anything, models.LinkCheck{URL: "http://c.example/", Class: crawler.ErrorClassTLS, CheckedAt: now.Add(time.Minute)})
+	mockRepo.AssertNumberOfCalls(t, "DeleteBefore", 1)
+
+	_, err = NewLinkCheckStore(mockRepo, 0)
+	assert.Error(t, err)
+}
+
+func TestJobService_MetricsReportLinkCache(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><a href="/about">about</a></body></html>`))
+	}))
+	defer ts.Close()
+
+	cr := crawler.New(crawler.HTTPClient(5*time.Second, crawler.WithoutAddressGuard()))
+	svc, err := NewJobService(new(mocks.JobRepository), new(mocks.ResultRepository), new(mocks.URLRepository), cr)
+	assert.NoError(t, err)
+	defer svc.Shutdown()
+	assert.Nil(t, svc.Metrics().LinkCache)
+
+	cr.SetLinkCache(crawler.NewLinkCache(time.Hour, nil))
+	for range 2 {
+		_, err := cr.Crawl(context.Background(), ts.URL)
+		assert.NoError(t, err)
+	}
+	m := svc.Metrics().LinkCache
+	if assert.NotNil(t, m) {
+		assert.Equal(t, int64(1), m.Hits)
+		assert.Equal(t, int64(1), m.Misses)
+		assert.Equal(t, 1, m.Entries)
+		assert.InDelta(t, 0.5, m.HitRatio, 1e-9)
+	}
+}
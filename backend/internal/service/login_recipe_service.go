@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+type LoginRecipeService struct {
+	repo repository.LoginRecipeRepository
+	box  *SecretBox
+}
+
+func NewLoginRecipeService(repo repository.LoginRecipeRepository, box *SecretBox) (*LoginRecipeService, error) {
+	if repo == nil {
+		return nil, errors.New("LoginRecipeRepository must not be nil")
+	}
+	if box == nil {
+		return nil, errors.New("SecretBox must not be nil")
+	}
+	return &LoginRecipeService{repo: repo, box: box}, nil
+}
+
+// CreateRecipe validates a recipe and stores it with its field values encrypted
+func (s *LoginRecipeService) CreateRecipe(ctx context.Context, req models.CreateLoginRecipeRequest) (*models.LoginRecipeResponse, error) {
+	recipe := models.LoginRecipe{
+		Name:          req.Name,
+		LoginURL:      req.LoginURL,
+		SuccessCookie: emptyToNil(req.SuccessCookie),
+		SuccessURL:    emptyToNil(req.SuccessURL),
+	}
+	if err := crawler.ValidateLoginRecipe(toCrawlerRecipe(recipe, req.Fields)); err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if recipe.Fields, err = s.box.Seal(plain); err != nil {
+		return nil, fmt.Errorf("failed to encrypt fields: %w", err)
+	}
+
+	rec, err := s.repo.Create(ctx, recipe)
+	if err != nil {
+		return nil, err
+	}
+	return s.toRecipeResponse(*rec)
+}
+
+func (s *LoginRecipeService) ListRecipes(ctx context.Context) ([]models.LoginRecipeResponse, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.LoginRecipeResponse, 0, len(rows))
+	for _, r := range rows {
+		recipe, err := s.toRecipeResponse(r)
+		if err != nil {
+			return nil, fmt.Errorf("login recipe %d: %w", r.ID, err)
+		}
+		resp = append(resp, *recipe)
+	}
+	return resp, nil
+}
+
+func (s *LoginRecipeService) DeleteRecipe(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// AssignToURL makes a URL log in with a recipe before it's crawled; nil removes the recipe.
+// sql.ErrNoRows is returned if the URL or the recipe doesn't exist.
+func (s *LoginRecipeService) AssignToURL(ctx context.Context, urlID int64, recipeID *int64) error {
+	return s.repo.AssignToURL(ctx, urlID, recipeID)
+}
+
+// RecipeForURL returns the recipe to log in with before crawling a URL, or nil if there is none
+func (s *LoginRecipeService) RecipeForURL(ctx context.Context, urlID int64) (*crawler.LoginRecipe, error) {
+	rec, err := s.repo.GetForURL(ctx, urlID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fields, err := s.openFields(rec.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("login recipe %d: %w", rec.ID, err)
+	}
+	recipe := toCrawlerRecipe(*rec, fields)
+	return &recipe, nil
+}
+
+func (s *LoginRecipeService) openFields(sealed []byte) (map[string]string, error) {
+	plain, err := s.box.Open(sealed)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(plain, &fields); err != nil {
+		return nil, fmt.Errorf("invalid fields: %w", err)
+	}
+	return fields, nil
+}
+
+func toCrawlerRecipe(r models.LoginRecipe, fields map[string]string) crawler.LoginRecipe {
+	out := crawler.LoginRecipe{LoginURL: r.LoginURL, Fields: fields}
+	if r.SuccessCookie != nil {
+		out.SuccessCookie = *r.SuccessCookie
+	}
+	if r.SuccessURL != nil {
+		out.SuccessURL = *r.SuccessURL
+	}
+	return out
+}
+
+// toRecipeResponse lists the recipe's field names; their values are never returned
+func (s *LoginRecipeService) toRecipeResponse(r models.LoginRecipe) (*models.LoginRecipeResponse, error) {
+	fields, err := s.openFields(r.Fields)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &models.LoginRecipeResponse{
+		ID:            r.ID,
+		Name:          r.Name,
+		LoginURL:      r.LoginURL,
+		Fields:        names,
+		SuccessCookie: r.SuccessCookie,
+		SuccessURL:    r.SuccessURL,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+func TestLoginRecipeService_CreateRecipe_EncryptsFields(t *testing.T) {
+	ctx := context.Background()
+	cookie := "sid"
+	empty := ""
+
+	mockRepo := new(mocks.LoginRecipeRepository)
+	var stored models.LoginRecipe
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(r models.LoginRecipe) bool {
+		return r.LoginURL == "https://example.com/login" && len(r.Fields) > 0 &&
+			!bytes.Contains(r.Fields, []byte("s3cret")) && *r.SuccessCookie == "sid" && r.SuccessURL == nil
+	})).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(models.LoginRecipe)
+		stored.ID = 4
+	}).Return(func(_ context.Context, _ models.LoginRecipe) *models.LoginRecipe { return &stored }, nil)
+
+	svc, err := NewLoginRecipeService(mockRepo, testSecretBox(t))
+	assert.NoError(t, err)
+	resp, err := svc.CreateRecipe(ctx, models.CreateLoginRecipeRequest{
+		Name:          "client portal",
+		LoginURL:      "https://example.com/login",
+		Fields:        map[string]string{"user": "qa", "pass": "s3cret"},
+		SuccessCookie: &cookie,
+		SuccessURL:    &empty,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), resp.ID)
+	assert.Equal(t, []string{"pass", "user"}, resp.Fields)
+	mockRepo.AssertExpectations(t)
+
+	mockRepo.On("GetForURL", ctx, int64(7)).Return(&stored, nil)
+	recipe, err := svc.RecipeForURL(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", recipe.Fields["pass"])
+	assert.Equal(t, "sid", recipe.SuccessCookie)
+}
+
+func TestLoginRecipeService_CreateRecipe_RejectsInvalidRecipes(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.LoginRecipeRepository)
+	svc, err := NewLoginRecipeService(mockRepo, testSecretBox(t))
+	assert.NoError(t, err)
+
+	cases := []models.CreateLoginRecipeRequest{
+		{Name: "relative", LoginURL: "/login", Fields: map[string]string{"user": "qa"}},
+		{Name: "no fields", LoginURL: "https://example.com/login"},
+	}
+	for _, req := range cases {
+		_, err := svc.CreateRecipe(ctx, req)
+		assert.Error(t, err, req.Name)
+	}
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestLoginRecipeService_RecipeForURL_None(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.LoginRecipeRepository)
+	mockRepo.On("GetForURL", ctx, int64(1)).Return(nil, sql.ErrNoRows)
+
+	svc, err := NewLoginRecipeService(mockRepo, testSecretBox(t))
+	assert.NoError(t, err)
+	recipe, err := svc.RecipeForURL(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Nil(t, recipe)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+// ProxyService manages proxy pools. Proxy health lives in memory and starts over when the
+// server restarts.
+type ProxyService struct {
+	repo repository.ProxyPoolRepository
+	box  *SecretBox
+
+	mu    sync.Mutex
+	pools map[int64]*crawler.ProxyPool // by pool id, built on first use
+}
+
+func NewProxyService(repo repository.ProxyPoolRepository, box *SecretBox) (*ProxyService, error) {
+	if repo == nil {
+		return nil, errors.New("ProxyPoolRepository must not be nil")
+	}
+	if box == nil {
+		return nil, errors.New("SecretBox must not be nil")
+	}
+	return &ProxyService{repo: repo, box: box, pools: make(map[int64]*crawler.ProxyPool)}, nil
+}
+
+// CreatePool validates the proxies of a pool scoped to exactly one of a URL or a tag and
+// stores them encrypted
+func (s *ProxyService) CreatePool(ctx context.Context, req models.CreateProxyPoolRequest) (*models.ProxyPoolResponse, error) {
+	if (req.URLID == nil) == (req.Tag == nil) {
+		return nil, errors.New("exactly one of url_id or tag is required")
+	}
+	if _, err := crawler.NewProxyPool(req.Proxies); err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(req.Proxies)
+	if err != nil {
+		return nil, err
+	}
+	pool := models.ProxyPool{Name: req.Name, URLID: req.URLID}
+	if req.Tag != nil {
+		tag := normalizeTag(*req.Tag)
+		pool.Tag = &tag
+	}
+	if pool.Proxies, err = s.box.Seal(plain); err != nil {
+		return nil, fmt.Errorf("failed to encrypt proxies: %w", err)
+	}
+
+	rec, err := s.repo.Create(ctx, pool)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+	return s.toPoolResponse(*rec)
+}
+
+// ListPools returns every pool with the health of its proxies
+func (s *ProxyService) ListPools(ctx context.Context) ([]models.ProxyPoolResponse, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.ProxyPoolResponse, 0, len(rows))
+	for _, r := range rows {
+		pool, err := s.toPoolResponse(r)
+		if err != nil {
+			return nil, fmt.Errorf("proxy pool %d: %w", r.ID, err)
+		}
+		resp = append(resp, *pool)
+	}
+	return resp, nil
+}
+
+func (s *ProxyService) DeletePool(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.pools, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// PoolForURL returns the pool crawls of a URL go through, or nil if there is none. The URL's
+// own pool wins over those of its tags; among tag pools the oldest wins.
+func (s *ProxyService) PoolForURL(ctx context.Context, urlID int64) (*crawler.ProxyPool, error) {
+	rows, err := s.repo.ListForURL(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	pool, err := s.pool(rows[0])
+	if err != nil {
+		return nil, fmt.Errorf("proxy pool %d: %w", rows[0].ID, err)
+	}
+	return pool, nil
+}
+
+// pool returns the live pool of a record, keeping its health across crawls
+func (s *ProxyService) pool(r models.ProxyPool) (*crawler.ProxyPool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.pools[r.ID]; ok {
+		return p, nil
+	}
+	plain, err := s.box.Open(r.Proxies)
+	if err != nil {
+		return nil, err
+	}
+	var proxies []string
+	if err := json.Unmarshal(plain, &proxies); err != nil {
+		return nil, fmt.Errorf("invalid proxies: %w", err)
+	}
+	p, err := crawler.NewProxyPool(proxies)
+	if err != nil {
+		return nil, err
+	}
+	s.pools[r.ID] = p
+	return p, nil
+}
+
+func (s *ProxyService) toPoolResponse(r models.ProxyPool) (*models.ProxyPoolResponse, error) {
+	pool, err := s.pool(r)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.ProxyPoolResponse{ID: r.ID, Name: r.Name, URLID: r.URLID, Tag: r.Tag}
+	for _, st := range pool.Status() {
+		h := models.ProxyHealth{
+			Proxy:               st.Proxy,
+			Healthy:             st.Healthy,
+			ConsecutiveFailures: st.Failures,
+			LastError:           emptyToNil(&st.LastError),
+			LastUsed:            formatTime(st.LastUsed),
+			RetryAfter:          formatTime(st.RetryAfter),
+			Successes:           st.Successes,
+			Errors:              st.TotalErrors,
+		}
+		resp.Proxies = append(resp.Proxies, h)
+	}
+	return resp, nil
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
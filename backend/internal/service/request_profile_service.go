@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"golang.org/x/net/http/httpguts"
+
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+// reservedHeaders can't be set as custom headers, either because the HTTP client or the proxy
+// pool owns them or because a dedicated field (cookies, auth_type) covers them
+var reservedHeaders = map[string]bool{
+	"Host":                true,
+	"Content-Length":      true,
+	"Transfer-Encoding":   true,
+	"Connection":          true,
+	"Cookie":              true,
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+}
+
+// profileSecrets is the encrypted part of a request profile. Custom headers often carry API
+// keys, so their values are kept here too.
+type profileSecrets struct {
+	Password string            `json:"password,omitempty"`
+	Token    string            `json:"token,omitempty"`
+	Cookies  map[string]string `json:"cookies,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+type RequestProfileService struct {
+	repo repository.RequestProfileRepository
+	box  *SecretBox
+}
+
+func NewRequestProfileService(repo repository.RequestProfileRepository, box *SecretBox) (*RequestProfileService, error) {
+	if repo == nil {
+		return nil, errors.New("RequestProfileRepository must not be nil")
+	}
+	if box == nil {
+		return nil, errors.New("SecretBox must not be nil")
+	}
+	return &RequestProfileService{repo: repo, box: box}, nil
+}
+
+// CreateProfile validates a profile scoped to exactly one of a URL or a tag and stores it with
+// its header values, cookies and credentials encrypted
+func (s *RequestProfileService) CreateProfile(ctx context.Context, req models.CreateRequestProfileRequest) (*models.RequestProfileResponse, error) {
+	if (req.URLID == nil) == (req.Tag == nil) {
+		return nil, errors.New("exactly one of url_id or tag is required")
+	}
+	if err := validateProfileRequest(&req); err != nil {
+		return nil, err
+	}
+
+	profile := models.RequestProfile{
+		Name:           req.Name,
+		URLID:          req.URLID,
+		UserAgent:      emptyToNil(req.UserAgent),
+		AcceptLanguage: emptyToNil(req.AcceptLanguage),
+		AuthType:       req.AuthType,
+		ApplyToLinks:   req.ApplyToLinks,
+	}
+	if req.Tag != nil {
+		tag := normalizeTag(*req.Tag)
+		profile.Tag = &tag
+	}
+
+	secrets := profileSecrets{Cookies: req.Cookies, Headers: req.Headers}
+	switch req.AuthType {
+	case models.AuthBasic:
+		profile.AuthUsername = req.Username
+		if req.Password != nil {
+			secrets.Password = *req.Password
+		}
+	case models.AuthBearer:
+		secrets.Token = *req.Token
+	}
+	if secrets.Password != "" || secrets.Token != "" || len(secrets.Cookies) > 0 || len(secrets.Headers) > 0 {
+		plain, err := json.Marshal(secrets)
+		if err != nil {
+			return nil, err
+		}
+		if profile.Secrets, err = s.box.Seal(plain); err != nil {
+			return nil, fmt.Errorf("failed to encrypt secrets: %w", err)
+		}
+	}
+
+	rec, err := s.repo.Create(ctx, profile)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+	return s.toProfileResponse(*rec)
+}
+
+func (s *RequestProfileService) ListProfiles(ctx context.Context) ([]models.RequestProfileResponse, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.RequestProfileResponse, 0, len(rows))
+	for _, r := range rows {
+		p, err := s.toProfileResponse(r)
+		if err != nil {
+			return nil, fmt.Errorf("profile %d: %w", r.ID, err)
+		}
+		resp = append(resp, *p)
+	}
+	return resp, nil
+}
+
+func (s *RequestProfileService) DeleteProfile(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ProfileForURL returns the profile to crawl a URL with, or nil if there is none. The URL's own
+// profile wins over those of its tags; among tag profiles the oldest wins.
+func (s *RequestProfileService) ProfileForURL(ctx context.Context, urlID int64) (*crawler.RequestProfile, error) {
+	rows, err := s.repo.ListForURL(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	r := rows[0]
+	secrets, err := s.openSecrets(r.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("profile %d: %w", r.ID, err)
+	}
+	out := &crawler.RequestProfile{
+		Cookies:      secrets.Cookies,
+		ApplyToLinks: r.ApplyToLinks,
+	}
+	if r.UserAgent != nil {
+		out.UserAgent = *r.UserAgent
+	}
+	if r.AcceptLanguage != nil {
+		out.AcceptLanguage = *r.AcceptLanguage
+	}
+	if out.Headers, err = profileHeaders(r, secrets); err != nil {
+		return nil, fmt.Errorf("profile %d: %w", r.ID, err)
+	}
+	switch r.AuthType {
+	case models.AuthBasic:
+		out.BasicAuth = &crawler.BasicAuth{Password: secrets.Password}
+		if r.AuthUsername != nil {
+			out.BasicAuth.Username = *r.AuthUsername
+		}
+	case models.AuthBearer:
+		out.BearerToken = secrets.Token
+	}
+	return out, nil
+}
+
+// profileHeaders returns the custom headers of a profile. Profiles stored before header values
+// were encrypted still have them in the plaintext headers column.
+func profileHeaders(r models.RequestProfile, secrets profileSecrets) (map[string]string, error) {
+	if len(r.Headers) == 0 {
+		return secrets.Headers, nil
+	}
+	headers := make(map[string]string)
+	if err := json.Unmarshal(r.Headers, &headers); err != nil {
+		return nil, fmt.Errorf("invalid headers: %w", err)
+	}
+	for name, value := range secrets.Headers {
+		headers[name] = value
+	}
+	return headers, nil
+}
+
+func (s *RequestProfileService) openSecrets(sealed []byte) (profileSecrets, error) {
+	var secrets profileSecrets
+	if len(sealed) == 0 {
+		return secrets, nil
+	}
+	plain, err := s.box.Open(sealed)
+	if err != nil {
+		return secrets, err
+	}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return secrets, fmt.Errorf("invalid secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// validateProfileRequest checks headers, cookies and credentials, defaulting auth_type to none
+func validateProfileRequest(req *models.CreateRequestProfileRequest) error {
+	for name, value := range req.Headers {
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			return fmt.Errorf("invalid header %q", name)
+		}
+		if reservedHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("header %q can't be set directly; use cookies or auth_type", name)
+		}
+	}
+	for name, value := range req.Cookies {
+		if err := (&http.Cookie{Name: name, Value: value}).Valid(); err != nil {
+			return fmt.Errorf("invalid cookie %q", name)
+		}
+	}
+	for field, v := range map[string]*string{"user_agent": req.UserAgent, "accept_language": req.AcceptLanguage} {
+		if v != nil && !httpguts.ValidHeaderFieldValue(*v) {
+			return fmt.Errorf("invalid %s", field)
+		}
+	}
+
+	if req.AuthType == "" {
+		req.AuthType = models.AuthNone
+	}
+	hasPassword := req.Password != nil && *req.Password != ""
+	hasToken := req.Token != nil && *req.Token != ""
+	switch req.AuthType {
+	case models.AuthBasic:
+		if req.Username == nil || *req.Username == "" {
+			return errors.New("basic auth requires a username")
+		}
+		if hasToken {
+			return errors.New("token is only used with bearer auth")
+		}
+	case models.AuthBearer:
+		if !hasToken {
+			return errors.New("bearer auth requires a token")
+		}
+		if hasPassword || req.Username != nil {
+			return errors.New("username and password are only used with basic auth")
+		}
+	default:
+		if hasPassword || hasToken || req.Username != nil {
+			return errors.New("credentials require auth_type basic or bearer")
+		}
+	}
+	return nil
+}
+
+// toProfileResponse redacts a profile: header and cookie values, password and token are never
+// returned
+func (s *RequestProfileService) toProfileResponse(r models.RequestProfile) (*models.RequestProfileResponse, error) {
+	secrets, err := s.openSecrets(r.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.RequestProfileResponse{
+		ID:             r.ID,
+		Name:           r.Name,
+		URLID:          r.URLID,
+		Tag:            r.Tag,
+		UserAgent:      r.UserAgent,
+		AcceptLanguage: r.AcceptLanguage,
+		Headers:        []string{},
+		Cookies:        make([]string, 0, len(secrets.Cookies)),
+		AuthType:       r.AuthType,
+		Username:       r.AuthUsername,
+		HasPassword:    secrets.Password != "",
+		HasToken:       secrets.Token != "",
+		ApplyToLinks:   r.ApplyToLinks,
+	}
+	headers, err := profileHeaders(r, secrets)
+	if err != nil {
+		return nil, err
+	}
+	for name := range headers {
+		resp.Headers = append(resp.Headers, name)
+	}
+	sort.Strings(resp.Headers)
+	for name := range secrets.Cookies {
+		resp.Cookies = append(resp.Cookies, name)
+	}
+	sort.Strings(resp.Cookies)
+	return resp, nil
+}
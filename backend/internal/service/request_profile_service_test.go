@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+// testSecretBox returns the box the services that store secrets are tested with
+func testSecretBox(t *testing.T) *SecretBox {
+	t.Helper()
+	box, err := NewSecretBox("test-key")
+	assert.NoError(t, err)
+	return box
+}
+
+func TestSecretBox_RoundTrip(t *testing.T) {
+	box, err := NewSecretBox("key-one")
+	assert.NoError(t, err)
+
+	sealed, err := box.Seal([]byte("hunter2"))
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(sealed, []byte("hunter2")))
+
+	plain, err := box.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", string(plain))
+
+	other, err := NewSecretBox("key-two")
+	assert.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+
+	// fingerprints are keyed: another key gives another sum, and neither is the plain hash
+	assert.Equal(t, box.Sum([]byte("hunter2")), box.Sum([]byte("hunter2")))
+	assert.NotEqual(t, box.Sum([]byte("hunter2")), other.Sum([]byte("hunter2")))
+	plainSum := sha256.Sum256([]byte("hunter2"))
+	assert.NotEqual(t, hex.EncodeToString(plainSum[:]), box.Sum([]byte("hunter2")))
+
+	_, err = NewSecretBox("")
+	assert.Error(t, err)
+}
+
+func TestRequestProfileService_CreateProfile_EncryptsAndRedacts(t *testing.T) {
+	ctx := context.Background()
+	urlID := int64(3)
+	user, pass := "qa", "s3cret"
+
+	mockRepo := new(mocks.RequestProfileRepository)
+	var stored models.RequestProfile
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(p models.RequestProfile) bool {
+		return p.AuthType == models.AuthBasic && *p.AuthUsername == "qa" && len(p.Secrets) > 0 && len(p.Headers) == 0 &&
+			!bytes.Contains(p.Secrets, []byte("s3cret")) && !bytes.Contains(p.Secrets, []byte("abc123")) &&
+			!bytes.Contains(p.Secrets, []byte("key-123"))
+	})).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(models.RequestProfile)
+		stored.ID = 9
+	}).Return(func(_ context.Context, _ models.RequestProfile) *models.RequestProfile { return &stored }, nil)
+
+	svc, err := NewRequestProfileService(mockRepo, testSecretBox(t))
+	assert.NoError(t, err)
+	resp, err := svc.CreateProfile(ctx, models.CreateRequestProfileRequest{
+		Name:     "staging",
+		URLID:    &urlID,
+		Headers:  map[string]string{"X-Env": "staging", "X-Api-Key": "key-123"},
+		Cookies:  map[string]string{"session": "abc123", "csrf": "xyz"},
+		AuthType: models.AuthBasic,
+		Username: &user,
+		Password: &pass,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), resp.ID)
+	assert.Equal(t, []string{"csrf", "session"}, resp.Cookies)
+	assert.Equal(t, []string{"X-Api-Key", "X-Env"}, resp.Headers)
+	assert.True(t, resp.HasPassword)
+	assert.False(t, resp.HasToken)
+	mockRepo.AssertExpectations(t)
+
+	// the stored profile decrypts back into the crawler's request profile
+	mockRepo.On("ListForURL", ctx, urlID).Return([]models.RequestProfile{stored}, nil)
+	profile, err := svc.ProfileForURL(ctx, urlID)
+	assert.NoError(t, err)
+	assert.Equal(t, "qa", profile.BasicAuth.Username)
+	assert.Equal(t, "s3cret", profile.BasicAuth.Password)
+	assert.Equal(t, "abc123", profile.Cookies["session"])
+	assert.Equal(t, "staging", profile.Headers["X-Env"])
+	assert.Equal(t, "key-123", profile.Headers["X-Api-Key"])
+}
+
+func TestRequestProfileService_CreateProfile_RejectsInvalidProfiles(t *testing.T) {
+	ctx := context.Background()
+	urlID := int64(1)
+	tag := "staging"
+	user, token, empty := "qa", "tok", ""
+
+	mockRepo := new(mocks.RequestProfileRepository)
+	svc, err := NewRequestProfileService(mockRepo, testSecretBox(t))
+	assert.NoError(t, err)
+
+	cases := map[string]models.CreateRequestProfileRequest{
+		"both scopes":        {Name: "p", URLID: &urlID, Tag: &tag},
+		"no scope":           {Name: "p"},
+		"bad header name":    {Name: "p", URLID: &urlID, Headers: map[string]string{"X Env": "a"}},
+		"bad header value":   {Name: "p", URLID: &urlID, Headers: map[string]string{"X-Env": "a\r\nb"}},
+		"reserved header":    {Name: "p", URLID: &urlID, Headers: map[string]string{"authorization": "Bearer x"}},
+		"proxy auth header":  {Name: "p", URLID: &urlID, Headers: map[string]string{"Proxy-Authorization": "Basic x"}},
+		"bad cookie":         {Name: "p", URLID: &urlID, Cookies: map[string]string{"a b": "c"}},
+		"basic no username":  {Name: "p", URLID: &urlID, AuthType: models.AuthBasic, Username: &empty},
+		"bearer no token":    {Name: "p", URLID: &urlID, AuthType: models.AuthBearer},
+		"bearer with user":   {Name: "p", URLID: &urlID, AuthType: models.AuthBearer, Token: &token, Username: &user},
+		"token without auth": {Name: "p", URLID: &urlID, Token: &token},
+	}
+	for name, req := range cases {
+		_, err := svc.CreateProfile(ctx, req)
+		assert.Error(t, err, name)
+	}
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestRequestProfileService_ProfileForURL(t *testing.T) {
+	ctx := context.Background()
+	ua := "url-profile/1.0"
+	tagUA := "tag-profile/1.0"
+
+	mockRepo := new(mocks.RequestProfileRepository)
+	svc, err := NewRequestProfileService(mockRepo, testSecretBox(t))
+	assert.NoError(t, err)
+
+	// the repository lists the URL's own profile first
+	mockRepo.On("ListForURL", ctx, int64(1)).Return([]models.RequestProfile{
+		{ID: 5, Name: "own", UserAgent: &ua, AuthType: models.AuthNone, ApplyToLinks: true,
+			Headers: models.JSON(`{"X-Env":"legacy"}`)}, // stored before header values were encrypted
+		{ID: 2, Name: "tag", UserAgent: &tagUA, AuthType: models.AuthNone},
+	}, nil)
+	mockRepo.On("ListForURL", ctx, int64(2)).Return([]models.RequestProfile{}, nil)
+
+	profile, err := svc.ProfileForURL(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, ua, profile.UserAgent)
+	assert.True(t, profile.ApplyToLinks)
+	assert.Nil(t, profile.BasicAuth)
+	assert.Equal(t, "legacy", profile.Headers["X-Env"])
+
+	profile, err = svc.ProfileForURL(ctx, 2)
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
 
 	models "github.com/Dysar/url-crawler/backend/internal/models"
 	"github.com/Dysar/url-crawler/backend/internal/repository"
@@ -24,10 +27,56 @@ func (s *ResultService) GetResultByURLID(ctx context.Context, urlID int64) (*mod
 	if err != nil {
 		return nil, err
 	}
+
+	// Pull headline numbers out of the analyzer findings. A failed analyzer stores
+	// {"error": ...} in place of its findings, which leaves the score nil.
+	var findings struct {
+		Accessibility *struct {
+			Score *int `json:"score"`
+		} `json:"accessibility"`
+		Security *struct {
+			Grade *string `json:"grade"`
+		} `json:"security"`
+	}
+	var a11yScore *int
+	var securityGrade *string
+	if err := res.Findings.Decode(&findings); err != nil {
+		// the rest of the result is still worth returning, without the headline numbers
+		logrus.WithField("url_id", urlID).Warnf("Failed to decode findings: %v", err)
+	} else {
+		if findings.Accessibility != nil {
+			a11yScore = findings.Accessibility.Score
+		}
+		if findings.Security != nil {
+			securityGrade = findings.Security.Grade
+		}
+	}
+	var simHash *string
+	if res.SimHash != nil {
+		hex := fmt.Sprintf("%016x", *res.SimHash)
+		simHash = &hex
+	}
+
 	return &models.ResultResponse{
 		ID:                     res.ID,
 		URLID:                  res.URLID,
+		Outcome:                res.Outcome,
+		ContentType:            res.ContentType,
+		BodyBytes:              res.BodyBytes,
+		Truncated:              res.Truncated,
+		Proxy:                  res.Proxy,
+		ETag:                   res.ETag,
+		LastModified:           res.LastModified,
+		PreviousResultID:       res.PreviousResultID,
 		HTMLVersion:            res.HTMLVersion,
+		DocumentMode:           res.DocumentMode,
+		Charset:                res.Charset,
+		CharsetSource:          res.CharsetSource,
+		CharsetMismatch:        res.CharsetMismatch,
+		ContentHash:            res.ContentHash,
+		SimHash:                simHash,
+		Changed:                res.ContentChanged,
+		Similarity:             res.Similarity,
 		Title:                  res.Title,
 		HeadingsH1:             res.HeadingsH1,
 		HeadingsH2:             res.HeadingsH2,
@@ -38,7 +87,11 @@ func (s *ResultService) GetResultByURLID(ctx context.Context, urlID int64) (*mod
 		InternalLinksCount:     res.InternalLinksCount,
 		ExternalLinksCount:     res.ExternalLinksCount,
 		InaccessibleLinksCount: res.InaccessibleLinksCount,
+		LinkErrors:             res.LinkErrors,
 		HasLoginForm:           res.HasLoginForm,
+		AccessibilityScore:     a11yScore,
+		SecurityGrade:          securityGrade,
+		Findings:               res.Findings,
 	}, nil
 }
 
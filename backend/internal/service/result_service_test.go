@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+)
+
+func TestResultService_GetResultByURLID_HeadlineFindings(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name     string
+		findings models.JSON
+		score    *int
+		grade    *string
+	}{
+		{"analyzers ran", models.JSON(`{"accessibility":{"score":72,"errors":2},"security":{"grade":"B","score":85}}`), intPtr(72), strPtr("B")},
+		{"analyzer failed", models.JSON(`{"accessibility":{"error":"boom"},"security":{"error":"boom"}}`), nil, nil},
+		{"analyzer not selected", models.JSON(`{"forms":[]}`), nil, nil},
+		{"no findings", nil, nil, nil},
+		{"undecodable findings", models.JSON(`{"accessibility":{"score":"high"},"security":{"grade":"B"}}`), nil, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(mocks.ResultRepository)
+			mockRepo.On("GetByURLID", ctx, int64(1)).Return(&models.CrawlResult{ID: 5, URLID: 1, Findings: tc.findings}, nil)
+
+			svc, err := NewResultService(mockRepo)
+			assert.NoError(t, err)
+
+			res, err := svc.GetResultByURLID(ctx, 1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.score, res.AccessibilityScore)
+			assert.Equal(t, tc.grade, res.SecurityGrade)
+			assert.Equal(t, tc.findings, res.Findings)
+		})
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func strPtr(v string) *string { return &v }
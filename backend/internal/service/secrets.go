@@ -0,0 +1,83 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// secretsKeyLabel salts the key derivation, so a passphrase reused elsewhere still yields a
+// key of its own here
+const secretsKeyLabel = "url-crawler/secret-box/v1"
+
+// scrypt cost parameters for deriving the key; the key is derived once, at startup
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// SecretBox encrypts secrets stored in the database with AES-256-GCM
+type SecretBox struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// NewSecretBox derives the encryption key from a passphrase with scrypt. Changing the
+// passphrase makes existing secrets unreadable.
+func NewSecretBox(passphrase string) (*SecretBox, error) {
+	if passphrase == "" {
+		return nil, errors.New("secrets key must not be empty")
+	}
+	// the first 32 bytes are the encryption key, the rest keys Sum; scrypt's output only grows,
+	// so the encryption key is the same as when only 32 bytes were derived
+	key, err := scrypt.Key([]byte(passphrase), []byte(secretsKeyLabel), scryptN, scryptR, scryptP, 64)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead, macKey: key[32:]}, nil
+}
+
+// Sum returns a keyed fingerprint (HMAC-SHA256, hex-encoded) of data, for comparing secrets
+// without storing anything that could be brute-forced back into them
+func (b *SecretBox) Sum(data []byte) string {
+	mac := hmac.New(sha256.New, b.macKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Seal encrypts plaintext, prefixing the random nonce
+func (b *SecretBox) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Open decrypts what Seal produced
+func (b *SecretBox) Open(sealed []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("sealed secret is too short")
+	}
+	plaintext, err := b.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, errors.New("cannot decrypt secret; was the secrets key changed?")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Dysar/url-crawler/backend/internal/blobstore"
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/warc"
+)
+
+// warcFragment encodes a result as WARC records: the page's response, its request and a
+// metadata record with the result's headline numbers, followed by the link checks when the
+// snapshot has them. Each record is its own gzip member, so fragments concatenate into a file.
+func warcFragment(res models.CrawlResult, snap *crawler.Snapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	w := warc.NewWriter(&buf)
+
+	fields := [][2]string{{"WARC-Payload-Digest", warc.Digest(snap.Body)}}
+	if snap.Truncated {
+		fields = append(fields, [2]string{"WARC-Truncated", "length"})
+	}
+	responseID, err := writeExchange(w, &snap.Exchange, snap.Body, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var linkErrors string
+	if len(res.LinkErrors) > 0 {
+		linkErrors = string(res.LinkErrors)
+	}
+	var title, contentHash string
+	if res.Title != nil {
+		title = *res.Title
+	}
+	if res.ContentHash != nil {
+		contentHash = *res.ContentHash
+	}
+	meta := &warc.Record{
+		Type:        warc.TypeMetadata,
+		Date:        snap.FetchedAt,
+		TargetURI:   snap.URL,
+		RefersTo:    responseID,
+		ContentType: warc.ContentTypeFields,
+		Block: warc.Fields(
+			[2]string{"result-id", strconv.FormatInt(res.ID, 10)},
+			[2]string{"job-id", strconv.FormatInt(res.JobID, 10)},
+			[2]string{"outcome", res.Outcome},
+			[2]string{"title", title},
+			[2]string{"content-hash", contentHash},
+			[2]string{"internal-links", strconv.Itoa(res.InternalLinksCount)},
+			[2]string{"external-links", strconv.Itoa(res.ExternalLinksCount)},
+			[2]string{"inaccessible-links", strconv.Itoa(res.InaccessibleLinksCount)},
+			[2]string{"link-errors", linkErrors},
+		),
+	}
+	if _, _, err := w.WriteRecord(meta); err != nil {
+		return nil, err
+	}
+
+	for i := range snap.Links {
+		if _, err := writeExchange(w, &snap.Links[i], nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeExchange writes the response and request records of an exchange and returns the
+// response record's ID
+func writeExchange(w *warc.Writer, ex *crawler.Exchange, body []byte, fields [][2]string) (string, error) {
+	var block bytes.Buffer
+	if _, err := ex.WriteResponse(&block, body); err != nil {
+		return "", err
+	}
+	resp := &warc.Record{
+		Type:        warc.TypeResponse,
+		Date:        ex.FetchedAt,
+		TargetURI:   ex.URL,
+		ContentType: warc.ContentTypeHTTPResponse,
+		Fields:      fields,
+		Block:       block.Bytes(),
+	}
+	if _, _, err := w.WriteRecord(resp); err != nil {
+		return "", err
+	}
+
+	var reqBlock bytes.Buffer
+	if err := ex.WriteRequest(&reqBlock); err != nil {
+		return "", err
+	}
+	req := &warc.Record{
+		Type:         warc.TypeRequest,
+		Date:         ex.FetchedAt,
+		TargetURI:    ex.URL,
+		ConcurrentTo: resp.ID,
+		ContentType:  warc.ContentTypeHTTPRequest,
+		Block:        reqBlock.Bytes(),
+	}
+	if _, _, err := w.WriteRecord(req); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// parseArchiveTime reads a date (2006-01-02, midnight UTC) or an RFC 3339 time
+func parseArchiveTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: want a date such as 2006-01-02 or an RFC 3339 time", s)
+	}
+	return t, nil
+}
+
+// ArchivedSnapshots lists the archived results filter selects: those of a job, of a date
+// range (to exclusive), or both
+func (s *SnapshotService) ArchivedSnapshots(ctx context.Context, filter models.ArchiveFilter) ([]models.ResultSnapshot, error) {
+	if !s.archive {
+		return nil, errors.New("WARC archiving is not enabled")
+	}
+	if filter.FromDate != "" {
+		from, err := parseArchiveTime(filter.FromDate)
+		if err != nil {
+			return nil, err
+		}
+		filter.From = &from
+	}
+	if filter.ToDate != "" {
+		to, err := parseArchiveTime(filter.ToDate)
+		if err != nil {
+			return nil, err
+		}
+		filter.To = &to
+	}
+	if filter.JobID == nil && filter.From == nil && filter.To == nil {
+		return nil, errors.New("a job or a date range is required")
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("the end of the date range must be after its start")
+	}
+	return s.repo.ListArchived(ctx, filter)
+}
+
+// ArchiveName names the .warc.gz of an export, e.g. job-12.warc.gz
+func ArchiveName(filter models.ArchiveFilter) string {
+	name := "crawls"
+	if filter.JobID != nil {
+		name = fmt.Sprintf("job-%d", *filter.JobID)
+	}
+	if filter.FromDate != "" {
+		name += "-from-" + filter.FromDate
+	}
+	if filter.ToDate != "" {
+		name += "-to-" + filter.ToDate
+	}
+	return sanitizeFilename(name) + ".warc.gz"
+}
+
+func sanitizeFilename(s string) string {
+	out := []byte(s)
+	for i, c := range out {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.') {
+			out[i] = '_'
+		}
+	}
+	return string(out)
+}
+
+// WriteWARC writes the archived records of snaps to w as the .warc.gz called filename, led by
+// a warcinfo record. Snapshots whose records have gone missing from the blob store are skipped.
+func (s *SnapshotService) WriteWARC(ctx context.Context, w io.Writer, snaps []models.ResultSnapshot, filename string) error {
+	ww := warc.NewWriter(w)
+	info := &warc.Record{
+		Type:        warc.TypeWarcinfo,
+		Date:        s.now(),
+		ContentType: warc.ContentTypeFields,
+		Fields:      [][2]string{{"WARC-Filename", filename}},
+		Block: warc.Fields(
+			[2]string{"software", "url-crawler"},
+			[2]string{"format", "WARC File Format 1.1"},
+			[2]string{"conformsTo", "http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/"},
+			[2]string{"description", fmt.Sprintf("%d crawl results", len(snaps))},
+		),
+	}
+	if _, _, err := ww.WriteRecord(info); err != nil {
+		return err
+	}
+	for _, snap := range snaps {
+		if snap.WARCKey == nil {
+			continue
+		}
+		r, err := s.store.Get(ctx, *snap.WARCKey)
+		if errors.Is(err, blobstore.ErrNotFound) {
+			logrus.WithField("key", *snap.WARCKey).Warn("Archived records are missing from the blob store")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = ww.Append(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", *snap.WARCKey, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Dysar/url-crawler/backend/internal/blobstore"
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+const (
+	// snapshotPruneInterval is how often expired snapshots are pruned in the background
+	snapshotPruneInterval = time.Minute
+	// snapshotPruneBatch is how many expired snapshots are listed at a time
+	snapshotPruneBatch = 500
+)
+
+// SnapshotRetention bounds how many raw responses are kept. WARC records have a retention of
+// their own: they outlive the HTTP message of their snapshot unless WARCMaxAge is set.
+type SnapshotRetention struct {
+	MaxAge     time.Duration // 0 keeps snapshots regardless of age
+	MaxPerURL  int           // 0 keeps every snapshot of a URL
+	WARCMaxAge time.Duration // 0 keeps WARC records forever
+}
+
+// SnapshotService keeps the raw response of each crawl result, gzipped, in a blob store.
+// Snapshots past retention, and those of deleted results, are pruned in the background once
+// StartPruning is called; their WARC records follow a retention of their own.
+type SnapshotService struct {
+	repo       repository.SnapshotRepository
+	store      blobstore.Store
+	retention  SnapshotRetention
+	archive    bool // also keep WARC records of each result
+	now        func() time.Time
+	pruneEvery time.Duration
+
+	// Background pruning
+	once   sync.Once
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSnapshotService(repo repository.SnapshotRepository, store blobstore.Store, retention SnapshotRetention) (*SnapshotService, error) {
+	if repo == nil || store == nil {
+		return nil, errors.New("SnapshotRepository and blob store must not be nil")
+	}
+	if retention.MaxAge < 0 || retention.MaxPerURL < 0 || retention.WARCMaxAge < 0 {
+		return nil, errors.New("snapshot retention must not be negative")
+	}
+	return &SnapshotService{repo: repo, store: store, retention: retention, now: time.Now, pruneEvery: snapshotPruneInterval}, nil
+}
+
+// SetWARCArchive makes Save keep the result's WARC records as well, for Export
+func (s *SnapshotService) SetWARCArchive(on bool) { s.archive = on }
+
+// Archives says whether snapshots include WARC records
+func (s *SnapshotService) Archives() bool { return s.archive }
+
+func snapshotKey(urlID, resultID int64) string {
+	return fmt.Sprintf("snapshots/%d/%d.http.gz", urlID, resultID)
+}
+
+func warcKey(urlID, resultID int64) string {
+	return fmt.Sprintf("snapshots/%d/%d.warc.gz", urlID, resultID)
+}
+
+// Save stores snap as the snapshot of a stored result: the response as an HTTP message,
+// gzipped, and with WARC archiving on, the result's WARC records
+func (s *SnapshotService) Save(ctx context.Context, res models.CrawlResult, snap *crawler.Snapshot) error {
+	resultID, urlID := res.ID, res.URLID
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Name = fmt.Sprintf("result-%d.http", resultID)
+	zw.ModTime = s.now()
+	if _, err := snap.WriteTo(zw); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+
+	key := snapshotKey(urlID, resultID)
+	rec := models.ResultSnapshot{ResultID: resultID, URLID: urlID, BlobKey: &key, SizeBytes: int64(buf.Len())}
+	if err := s.store.Put(ctx, key, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to store snapshot: %w", err)
+	}
+	keys := []string{key}
+	if s.archive {
+		fragment, err := warcFragment(res, snap)
+		if err == nil {
+			key := warcKey(urlID, resultID)
+			if err = s.store.Put(ctx, key, fragment); err == nil {
+				size := int64(len(fragment))
+				rec.WARCKey, rec.WARCBytes = &key, &size
+				keys = append(keys, key)
+			}
+		}
+		if err != nil {
+			s.deleteBlobs(ctx, keys)
+			return fmt.Errorf("failed to archive snapshot: %w", err)
+		}
+	}
+	if _, err := s.repo.Create(ctx, rec); err != nil {
+		s.deleteBlobs(ctx, keys)
+		return fmt.Errorf("failed to record snapshot: %w", err)
+	}
+	return nil
+}
+
+// deleteBlobs removes the blobs of a snapshot that couldn't be recorded
+func (s *SnapshotService) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			logrus.WithError(err).WithField("key", key).Warn("Failed to delete unrecorded snapshot")
+		}
+	}
+}
+
+// Open returns the snapshot of a result and a reader of its gzipped content, or
+// sql.ErrNoRows if the result has none (or only its WARC records are left)
+func (s *SnapshotService) Open(ctx context.Context, resultID int64) (*models.ResultSnapshot, io.ReadCloser, error) {
+	snap, err := s.repo.GetByResultID(ctx, resultID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if snap.BlobKey == nil {
+		return nil, nil, sql.ErrNoRows
+	}
+	r, err := s.store.Get(ctx, *snap.BlobKey)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			logrus.WithField("key", *snap.BlobKey).Warn("Snapshot is recorded but missing from the blob store")
+			return nil, nil, sql.ErrNoRows
+		}
+		return nil, nil, err
+	}
+	return snap, r, nil
+}
+
+// Prune deletes the HTTP messages and the WARC records past their retention and returns how
+// many it deleted. A snapshot's row goes with the last of the two.
+func (s *SnapshotService) Prune(ctx context.Context) (int, error) {
+	var before *time.Time
+	if s.retention.MaxAge > 0 {
+		cutoff := s.now().Add(-s.retention.MaxAge)
+		before = &cutoff
+	}
+	pruned, err := s.pruneBatches(ctx, func() ([]models.ResultSnapshot, error) {
+		return s.repo.ListExpired(ctx, before, s.retention.MaxPerURL, snapshotPruneBatch)
+	}, func(snap models.ResultSnapshot) (*string, *string) { return snap.BlobKey, snap.WARCKey }, s.repo.DropSnapshot)
+	if err != nil {
+		return pruned, err
+	}
+
+	var warcBefore *time.Time
+	if s.retention.WARCMaxAge > 0 {
+		cutoff := s.now().Add(-s.retention.WARCMaxAge)
+		warcBefore = &cutoff
+	}
+	n, err := s.pruneBatches(ctx, func() ([]models.ResultSnapshot, error) {
+		return s.repo.ListExpiredArchives(ctx, warcBefore, snapshotPruneBatch)
+	}, func(snap models.ResultSnapshot) (*string, *string) { return snap.WARCKey, snap.BlobKey }, s.repo.DropArchive)
+	return pruned + n, err
+}
+
+// pruneBatches deletes the blobs list returns, batch by batch, until it runs out. keys gives
+// the expired blob of a snapshot and the one it may have besides; a row left with the other
+// blob is kept with drop, others are deleted.
+func (s *SnapshotService) pruneBatches(ctx context.Context, list func() ([]models.ResultSnapshot, error),
+	keys func(models.ResultSnapshot) (expired, other *string), drop func(context.Context, int64) error) (int, error) {
+	pruned := 0
+	for {
+		expired, err := list()
+		if err != nil {
+			return pruned, err
+		}
+		for _, snap := range expired {
+			key, other := keys(snap)
+			// blobs go first: a row without blobs is harmless, blobs without a row leak
+			if key != nil {
+				if err := s.store.Delete(ctx, *key); err != nil {
+					return pruned, fmt.Errorf("failed to delete snapshot %s: %w", *key, err)
+				}
+			}
+			if other != nil {
+				err = drop(ctx, snap.ID)
+			} else {
+				err = s.repo.Delete(ctx, snap.ID)
+			}
+			if err != nil {
+				return pruned, err
+			}
+			pruned++
+		}
+		if len(expired) < snapshotPruneBatch {
+			return pruned, nil
+		}
+	}
+}
+
+// StartPruning prunes expired snapshots now and then every pruneEvery until Shutdown. Pruning
+// runs under a context of its own, so it doesn't hold up the crawl that saved a snapshot or
+// get cut short by it.
+func (s *SnapshotService) StartPruning() {
+	s.once.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			ticker := time.NewTicker(s.pruneEvery)
+			defer ticker.Stop()
+			for {
+				s.prune(ctx)
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Shutdown stops background pruning, cancelling a run in progress, and waits for it to return
+func (s *SnapshotService) Shutdown() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// prune runs Prune and logs the outcome
+func (s *SnapshotService) prune(ctx context.Context) {
+	n, err := s.Prune(ctx)
+	if err != nil && ctx.Err() == nil {
+		logrus.WithError(err).Warn("Failed to prune expired snapshots")
+	}
+	if n > 0 {
+		logrus.Debugf("Pruned %d expired snapshots", n)
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Dysar/url-crawler/backend/internal/blobstore"
+	"github.com/Dysar/url-crawler/backend/internal/crawler"
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/warc"
+)
+
+func newTestBlobStore(t *testing.T) *blobstore.FS {
+	t.Helper()
+	store, err := blobstore.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSnapshotService_SaveAndOpen(t *testing.T) {
+	ctx := context.Background()
+	store := newTestBlobStore(t)
+	var recorded models.ResultSnapshot
+	mockRepo := new(mocks.SnapshotRepository)
+	mockRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(models.ResultSnapshot)
+	}).Return(func(_ context.Context, snap models.ResultSnapshot) (*models.ResultSnapshot, error) {
+		snap.ID = 1
+		return &snap, nil
+	})
+
+	// saving doesn't prune, so ListExpired isn't mocked
+	svc, err := NewSnapshotService(mockRepo, store, SnapshotRetention{MaxAge: time.Hour, MaxPerURL: 10})
+	assert.NoError(t, err)
+	snap := &crawler.Snapshot{
+		Exchange: crawler.Exchange{Proto: "HTTP/1.1", Status: "200 OK", Header: http.Header{"Content-Type": {"text/html"}}},
+		Body:     []byte("<p>hi</p>"),
+	}
+	assert.NoError(t, svc.Save(ctx, models.CrawlResult{ID: 5, URLID: 2}, snap))
+	if assert.NotNil(t, recorded.BlobKey) {
+		assert.Equal(t, "snapshots/2/5.http.gz", *recorded.BlobKey)
+	}
+	assert.Equal(t, int64(5), recorded.ResultID)
+	assert.Equal(t, int64(2), recorded.URLID)
+
+	mockRepo.On("GetByResultID", mock.Anything, int64(5)).Return(&recorded, nil)
+	got, blob, err := svc.Open(ctx, 5)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer blob.Close()
+	assert.Equal(t, recorded.SizeBytes, got.SizeBytes)
+	zr, err := gzip.NewReader(blob)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "result-5.http", zr.Name)
+	resp, err := http.ReadResponse(bufio.NewReader(zr), nil)
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "text/html", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "<p>hi</p>", string(body))
+	}
+
+	// a recorded snapshot whose blob is gone is not found
+	assert.NoError(t, store.Delete(ctx, *recorded.BlobKey))
+	_, _, err = svc.Open(ctx, 5)
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestSnapshotService_SaveDropsUnrecordedBlob(t *testing.T) {
+	ctx := context.Background()
+	store := newTestBlobStore(t)
+	mockRepo := new(mocks.SnapshotRepository)
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+	svc, err := NewSnapshotService(mockRepo, store, SnapshotRetention{})
+	assert.NoError(t, err)
+	err = svc.Save(ctx, models.CrawlResult{ID: 5, URLID: 2}, &crawler.Snapshot{Exchange: crawler.Exchange{Status: "200 OK", Header: http.Header{}}})
+	assert.ErrorContains(t, err, "db down")
+	_, err = store.Get(ctx, snapshotKey(2, 5))
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+}
+
+func TestSnapshotService_Prune(t *testing.T) {
+	ctx := context.Background()
+	store := newTestBlobStore(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, key := range []string{snapshotKey(1, 1), snapshotKey(1, 2)} {
+		assert.NoError(t, store.Put(ctx, key, []byte("x")))
+	}
+
+	cutoff := now.Add(-24 * time.Hour)
+	mockRepo := new(mocks.SnapshotRepository)
+	key1, key3 := snapshotKey(1, 1), snapshotKey(1, 3)
+	mockRepo.On("ListExpired", mock.Anything, &cutoff, 0, snapshotPruneBatch).Return([]models.ResultSnapshot{
+		{ID: 10, ResultID: 1, URLID: 1, BlobKey: &key1},
+		{ID: 11, ResultID: 3, URLID: 1, BlobKey: &key3}, // blob already gone
+	}, nil).Once()
+	mockRepo.On("ListExpiredArchives", mock.Anything, (*time.Time)(nil), snapshotPruneBatch).Return(nil, nil).Once()
+	mockRepo.On("Delete", mock.Anything, int64(10)).Return(nil).Once()
+	mockRepo.On("Delete", mock.Anything, int64(11)).Return(nil).Once()
+
+	svc, err := NewSnapshotService(mockRepo, store, SnapshotRetention{MaxAge: 24 * time.Hour})
+	assert.NoError(t, err)
+	svc.now = func() time.Time { return now }
+	n, err := svc.Prune(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	mockRepo.AssertExpectations(t)
+
+	_, err = store.Get(ctx, snapshotKey(1, 1))
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	r, err := store.Get(ctx, snapshotKey(1, 2))
+	if assert.NoError(t, err, "snapshots within retention are kept") {
+		r.Close()
+	}
+}
+
+func TestSnapshotService_PruneKeepsWARCRecords(t *testing.T) {
+	ctx := context.Background()
+	store := newTestBlobStore(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	httpKey, warcKey := snapshotKey(1, 1), warcKey(1, 1)
+	for _, key := range []string{httpKey, warcKey} {
+		assert.NoError(t, store.Put(ctx, key, []byte("x")))
+	}
+	snap := models.ResultSnapshot{ID: 10, ResultID: 1, URLID: 1, BlobKey: &httpKey, WARCKey: &warcKey}
+
+	mockRepo := new(mocks.SnapshotRepository)
+	mockRepo.On("ListExpired", mock.Anything, mock.Anything, 0, snapshotPruneBatch).Return([]models.ResultSnapshot{snap}, nil).Once()
+	mockRepo.On("DropSnapshot", mock.Anything, int64(10)).Return(nil).Once()
+	mockRepo.On("ListExpiredArchives", mock.Anything, (*time.Time)(nil), snapshotPruneBatch).Return(nil, nil).Once()
+
+	// by default, WARC records outlive the HTTP message
+	svc, err := NewSnapshotService(mockRepo, store, SnapshotRetention{MaxAge: time.Hour})
+	assert.NoError(t, err)
+	svc.now = func() time.Time { return now }
+	n, err := svc.Prune(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	mockRepo.AssertExpectations(t)
+	_, err = store.Get(ctx, httpKey)
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	r, err := store.Get(ctx, warcKey)
+	if assert.NoError(t, err, "WARC records are kept forever by default") {
+		r.Close()
+	}
+
+	// with a WARC retention, the records go too, and the row with them
+	warcCutoff := now.Add(-24 * time.Hour)
+	snap.BlobKey = nil
+	mockRepo.On("ListExpired", mock.Anything, mock.Anything, 0, snapshotPruneBatch).Return(nil, nil).Once()
+	mockRepo.On("ListExpiredArchives", mock.Anything, &warcCutoff, snapshotPruneBatch).Return([]models.ResultSnapshot{snap}, nil).Once()
+	mockRepo.On("Delete", mock.Anything, int64(10)).Return(nil).Once()
+	svc.retention.WARCMaxAge = 24 * time.Hour
+	n, err = svc.Prune(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	mockRepo.AssertExpectations(t)
+	_, err = store.Get(ctx, warcKey)
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+}
+
+func TestSnapshotService_PrunesInBackground(t *testing.T) {
+	runs := make(chan context.Context, 10)
+	mockRepo := new(mocks.SnapshotRepository)
+	mockRepo.On("ListExpired", mock.Anything, (*time.Time)(nil), 3, snapshotPruneBatch).Run(func(args mock.Arguments) {
+		runs <- args.Get(0).(context.Context)
+	}).Return(nil, nil)
+	mockRepo.On("ListExpiredArchives", mock.Anything, (*time.Time)(nil), snapshotPruneBatch).Return(nil, nil)
+
+	svc, err := NewSnapshotService(mockRepo, newTestBlobStore(t), SnapshotRetention{MaxPerURL: 3})
+	assert.NoError(t, err)
+	svc.pruneEvery = 10 * time.Millisecond
+	svc.StartPruning()
+	var ctx context.Context
+	for i := 0; i < 2; i++ {
+		select {
+		case ctx = <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("expected pruning run %d", i+1)
+		}
+	}
+	svc.Shutdown()
+	assert.Error(t, ctx.Err(), "shutdown cancels the pruning context")
+}
+
+func TestNewSnapshotService_Validates(t *testing.T) {
+	_, err := NewSnapshotService(nil, newTestBlobStore(t), SnapshotRetention{})
+	assert.Error(t, err)
+	_, err = NewSnapshotService(new(mocks.SnapshotRepository), newTestBlobStore(t), SnapshotRetention{MaxPerURL: -1})
+	assert.Error(t, err)
+	_, err = NewSnapshotService(new(mocks.SnapshotRepository), newTestBlobStore(t), SnapshotRetention{WARCMaxAge: -time.Hour})
+	assert.Error(t, err)
+}
+
+func TestSnapshotService_ArchiveAndExport(t *testing.T) {
+	ctx := context.Background()
+	store := newTestBlobStore(t)
+	var recorded models.ResultSnapshot
+	mockRepo := new(mocks.SnapshotRepository)
+	mockRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(models.ResultSnapshot)
+	}).Return(&models.ResultSnapshot{ID: 1}, nil)
+
+	svc, err := NewSnapshotService(mockRepo, store, SnapshotRetention{})
+	assert.NoError(t, err)
+	svc.SetWARCArchive(true)
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	title := "Home"
+	snap := &crawler.Snapshot{
+		Exchange: crawler.Exchange{
+			Method: http.MethodGet, URL: "https://example.com/", RequestHeader: http.Header{"User-Agent": {"test"}},
+			Proto: "HTTP/1.1", Status: "200 OK", Header: http.Header{"Content-Type": {"text/html"}}, FetchedAt: at,
+		},
+		Body: []byte("<a href=/about>about</a>"),
+		Links: []crawler.Exchange{{
+			Method: http.MethodHead, URL: "https://example.com/about", RequestHeader: http.Header{},
+			Proto: "HTTP/1.1", Status: "404 Not Found", Header: http.Header{}, FetchedAt: at,
+		}},
+	}
+	res := models.CrawlResult{ID: 7, JobID: 3, URLID: 2, Outcome: crawler.OutcomeOK, Title: &title, InternalLinksCount: 1}
+	assert.NoError(t, svc.Save(ctx, res, snap))
+	if assert.NotNil(t, recorded.WARCKey) {
+		assert.Equal(t, "snapshots/2/7.warc.gz", *recorded.WARCKey)
+	}
+
+	jobID := int64(3)
+	mockRepo.On("ListArchived", mock.Anything, models.ArchiveFilter{JobID: &jobID}).Return([]models.ResultSnapshot{recorded}, nil)
+	snaps, err := svc.ArchivedSnapshots(ctx, models.ArchiveFilter{JobID: &jobID})
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, svc.WriteWARC(ctx, &out, snaps, "job-3.warc.gz"))
+
+	var types []string
+	var response, metadata *warc.Record
+	r := warc.NewReader(bytes.NewReader(out.Bytes()))
+	for {
+		rec, _, _, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		types = append(types, rec.Type)
+		switch {
+		case rec.Type == warc.TypeResponse && response == nil:
+			response = rec
+		case rec.Type == warc.TypeMetadata:
+			metadata = rec
+		}
+	}
+	assert.Equal(t, []string{"warcinfo", "response", "request", "metadata", "response", "request"}, types)
+	assert.Equal(t, "https://example.com/", response.TargetURI)
+	assert.True(t, at.Equal(response.Date))
+	assert.Equal(t, warc.Digest(snap.Body), response.Field("WARC-Payload-Digest"))
+	assert.Contains(t, string(metadata.Block), "title: Home\r\n")
+	assert.Equal(t, response.ID, metadata.RefersTo)
+
+	var cdx bytes.Buffer
+	n, err := warc.WriteCDX(&cdx, bytes.NewReader(out.Bytes()), "job-3.warc.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Contains(t, cdx.String(), "com,example)/about 20260301120000 https://example.com/about - 404")
+}
+
+func TestSnapshotService_ArchivedSnapshotsValidates(t *testing.T) {
+	svc, err := NewSnapshotService(new(mocks.SnapshotRepository), newTestBlobStore(t), SnapshotRetention{})
+	assert.NoError(t, err)
+	jobID := int64(1)
+	_, err = svc.ArchivedSnapshots(context.Background(), models.ArchiveFilter{JobID: &jobID})
+	assert.ErrorContains(t, err, "not enabled")
+
+	svc.SetWARCArchive(true)
+	for _, filter := range []models.ArchiveFilter{
+		{},
+		{FromDate: "yesterday"},
+		{FromDate: "2026-03-02", ToDate: "2026-03-01"},
+	} {
+		_, err := svc.ArchivedSnapshots(context.Background(), filter)
+		assert.Error(t, err, "filter %+v", filter)
+	}
+	assert.Equal(t, "crawls-from-2026-03-01-to-2026-03-02T00_00_00Z.warc.gz",
+		ArchiveName(models.ArchiveFilter{FromDate: "2026-03-01", ToDate: "2026-03-02T00:00:00Z"}))
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	models "github.com/Dysar/url-crawler/backend/internal/models"
+	"github.com/Dysar/url-crawler/backend/internal/repository"
+)
+
+// maxTagLength matches the size of tags.name, in characters
+const maxTagLength = 64
+
+// Tag validation errors, which handlers report as bad requests
+var (
+	// ErrEmptyTag is returned when a tag name is blank after normalization
+	ErrEmptyTag = errors.New("tag must not be empty")
+	// ErrTagTooLong is returned for a tag name longer than tags.name allows
+	ErrTagTooLong = errors.New("tag is too long")
+	// ErrNoTags and ErrNoURLs are returned when tagging is asked for without tags or URLs
+	ErrNoTags = errors.New("tags required")
+	ErrNoURLs = errors.New("url_ids required")
+)
+
+type TagService struct {
+	repo repository.TagRepository
+}
+
+func NewTagService(repo repository.TagRepository) (*TagService, error) {
+	if repo == nil {
+		return nil, errors.New("TagRepository must not be nil")
+	}
+	return &TagService{repo: repo}, nil
+}
+
+func (s *TagService) ListTags(ctx context.Context) ([]models.TagResponse, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.TagResponse, 0, len(rows))
+	for _, t := range rows {
+		resp = append(resp, models.TagResponse{ID: t.ID, Name: t.Name, URLCount: t.URLCount})
+	}
+	return resp, nil
+}
+
+// TagURLs attaches all tags to all URLs, creating tags that don't exist yet
+func (s *TagService) TagURLs(ctx context.Context, tags []string, urlIDs []int64) error {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(urlIDs) == 0 {
+		return ErrNoURLs
+	}
+	return s.repo.Assign(ctx, names, urlIDs)
+}
+
+// UntagURLs removes the tags from the URLs and returns the number of removed assignments
+func (s *TagService) UntagURLs(ctx context.Context, tags []string, urlIDs []int64) (int64, error) {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return 0, err
+	}
+	if len(urlIDs) == 0 {
+		return 0, ErrNoURLs
+	}
+	return s.repo.Unassign(ctx, names, urlIDs)
+}
+
+// normalizeTag makes tag names case-insensitive and whitespace-tolerant
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags normalizes and de-duplicates tag names, rejecting empty or too long ones
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		name := normalizeTag(t)
+		if name == "" {
+			return nil, ErrEmptyTag
+		}
+		if utf8.RuneCountInString(name) > maxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrTagTooLong, name, maxTagLength)
+		}
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNoTags
+	}
+	return out, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Dysar/url-crawler/backend/internal/mocks"
+)
+
+func TestTagService_TagURLs_NormalizesAndDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	urlIDs := []int64{1, 2}
+
+	mockRepo := new(mocks.TagRepository)
+	mockRepo.On("Assign", ctx, []string{"client-a", "staging"}, urlIDs).Return(nil)
+
+	svc, err := NewTagService(mockRepo)
+	assert.NoError(t, err)
+
+	err = svc.TagURLs(ctx, []string{"Client-A", " staging ", "client-a"}, urlIDs)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTagService_TagURLs_RejectsInvalidInput(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(mocks.TagRepository)
+	svc, err := NewTagService(mockRepo)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, svc.TagURLs(ctx, []string{" "}, []int64{1}), ErrEmptyTag)
+	assert.ErrorIs(t, svc.TagURLs(ctx, []string{}, []int64{1}), ErrNoTags)
+	assert.ErrorIs(t, svc.TagURLs(ctx, []string{"ok"}, nil), ErrNoURLs)
+	assert.ErrorIs(t, svc.TagURLs(ctx, []string{strings.Repeat("a", maxTagLength+1)}, []int64{1}), ErrTagTooLong)
+	mockRepo.AssertNotCalled(t, "Assign")
+}
+
+func TestTagService_TagURLs_CountsLengthInCharacters(t *testing.T) {
+	ctx := context.Background()
+	name := strings.Repeat("ü", maxTagLength)
+
+	mockRepo := new(mocks.TagRepository)
+	mockRepo.On("Assign", ctx, []string{name}, []int64{1}).Return(nil)
+
+	svc, err := NewTagService(mockRepo)
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.TagURLs(ctx, []string{name}, []int64{1}))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTagService_UntagURLs_ReturnsRemovedCount(t *testing.T) {
+	ctx := context.Background()
+	urlIDs := []int64{3}
+
+	mockRepo := new(mocks.TagRepository)
+	mockRepo.On("Unassign", ctx, []string{"client-a"}, urlIDs).Return(int64(1), nil)
+
+	svc, err := NewTagService(mockRepo)
+	assert.NoError(t, err)
+
+	removed, err := svc.UntagURLs(ctx, []string{"CLIENT-A"}, urlIDs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+	mockRepo.AssertExpectations(t)
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	models "github.com/Dysar/url-crawler/backend/internal/models"
 	"github.com/Dysar/url-crawler/backend/internal/repository"
@@ -24,17 +26,30 @@ func (s *URLService) CreateURL(ctx context.Context, url string) (*models.URLResp
 	if err != nil {
 		return nil, err
 	}
-	return &models.URLResponse{ID: rec.ID, URL: rec.URL}, nil
+	return &models.URLResponse{ID: rec.ID, URL: rec.URL, Tags: []string{}}, nil
 }
 
-func (s *URLService) ListURLs(ctx context.Context, page int, limit int, sortBy string, order string) (*models.URLListResponse, error) {
-	rows, total, err := s.repo.List(ctx, page, limit, sortBy, order)
+func (s *URLService) ListURLs(ctx context.Context, page int, limit int, sortBy string, order string, filter models.URLFilter) (*models.URLListResponse, error) {
+	filter.Tag = normalizeTag(filter.Tag)
+	if filter.ChangedWithin != "" {
+		window, err := parseWindow(filter.ChangedWithin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid changed_within: %w", err)
+		}
+		since := time.Now().Add(-window)
+		filter.ChangedSince = &since
+	}
+	rows, total, err := s.repo.List(ctx, page, limit, sortBy, order, filter)
 	if err != nil {
 		return nil, err
 	}
 	resp := make([]models.URLResponse, 0, len(rows))
 	for _, r := range rows {
-		resp = append(resp, models.URLResponse{ID: r.ID, URL: r.URL})
+		tags := r.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		resp = append(resp, models.URLResponse{ID: r.ID, URL: r.URL, Tags: tags})
 	}
 	return &models.URLListResponse{
 		Data:  resp,
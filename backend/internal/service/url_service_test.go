@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/Dysar/url-crawler/backend/internal/mocks"
 	models "github.com/Dysar/url-crawler/backend/internal/models"
@@ -47,7 +48,7 @@ func TestURLService_ListURLs_HappyPath(t *testing.T) {
 	now := time.Now()
 
 	mockRepo := new(mocks.URLRepository)
-	mockRepo.On("List", ctx, page, limit, sortBy, order).Return([]models.URL{
+	mockRepo.On("List", ctx, page, limit, sortBy, order, models.URLFilter{}).Return([]models.URL{
 		{
 			ID:        1,
 			URL:       "https://example.com",
@@ -59,13 +60,14 @@ func TestURLService_ListURLs_HappyPath(t *testing.T) {
 			URL:       "https://test.com",
 			CreatedAt: now,
 			UpdatedAt: now,
+			Tags:      []string{"client-a"},
 		},
 	}, total, nil)
 
 	svc, err := NewURLService(mockRepo)
 	assert.NoError(t, err, "NewURLService should not return error")
 
-	resp, err := svc.ListURLs(ctx, page, limit, sortBy, order)
+	resp, err := svc.ListURLs(ctx, page, limit, sortBy, order, models.URLFilter{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
@@ -77,5 +79,43 @@ func TestURLService_ListURLs_HappyPath(t *testing.T) {
 	assert.Equal(t, "https://example.com", resp.Data[0].URL)
 	assert.Equal(t, int64(2), resp.Data[1].ID)
 	assert.Equal(t, "https://test.com", resp.Data[1].URL)
+	assert.Equal(t, []string{}, resp.Data[0].Tags)
+	assert.Equal(t, []string{"client-a"}, resp.Data[1].Tags)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestURLService_ListURLs_NormalizesTagFilter(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(mocks.URLRepository)
+	mockRepo.On("List", ctx, 1, 20, "created_at", "desc", models.URLFilter{Tag: "client-a"}).Return([]models.URL{}, int64(0), nil)
+
+	svc, err := NewURLService(mockRepo)
+	assert.NoError(t, err)
+
+	resp, err := svc.ListURLs(ctx, 1, 20, "created_at", "desc", models.URLFilter{Tag: "  Client-A "})
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestURLService_ListURLs_ChangedWithin(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(mocks.URLRepository)
+	mockRepo.On("List", ctx, 1, 20, "created_at", "desc", mock.MatchedBy(func(f models.URLFilter) bool {
+		return f.ChangedSince != nil && time.Since(*f.ChangedSince) > 7*24*time.Hour-time.Minute &&
+			time.Since(*f.ChangedSince) < 7*24*time.Hour+time.Minute
+	})).Return([]models.URL{}, int64(0), nil)
+
+	svc, err := NewURLService(mockRepo)
+	assert.NoError(t, err)
+
+	_, err = svc.ListURLs(ctx, 1, 20, "created_at", "desc", models.URLFilter{ChangedWithin: "7d"})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	_, err = svc.ListURLs(ctx, 1, 20, "created_at", "desc", models.URLFilter{ChangedWithin: "lately"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CDXHeader is the legend of the lines WriteCDX writes: canonical URL, date, original URL,
+// MIME type, status, payload digest, redirect, meta tags, compressed length, offset, file
+const CDXHeader = " CDX N b a m s k r M S V g"
+
+// WriteCDX indexes the response records of the .warc.gz read from r, which is called
+// filename, as sorted CDX lines, and returns how many records it indexed
+func WriteCDX(w io.Writer, r io.Reader, filename string) (int, error) {
+	var lines []string
+	wr := NewReader(r)
+	for {
+		rec, offset, length, err := wr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if rec.Type != TypeResponse {
+			continue
+		}
+		lines = append(lines, cdxLine(rec, offset, length, filename))
+	}
+	sort.Strings(lines)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(CDXHeader + "\n")
+	for _, line := range lines {
+		bw.WriteString(line + "\n")
+	}
+	return len(lines), bw.Flush()
+}
+
+func cdxLine(rec *Record, offset, length int64, filename string) string {
+	mimeType, status, redirect := "-", "-", "-"
+	_, payload, _ := bytes.Cut(rec.Block, []byte("\r\n\r\n"))
+	if resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(rec.Block)), nil); err == nil {
+		resp.Body.Close()
+		status = fmt.Sprint(resp.StatusCode)
+		if mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+			mimeType = mt
+		}
+		if loc := resp.Header.Get("Location"); loc != "" && resp.StatusCode >= 300 && resp.StatusCode < 400 {
+			redirect = cdxField(loc)
+		}
+	}
+	digest := rec.Field("WARC-Payload-Digest")
+	if digest == "" {
+		digest = Digest(payload)
+	}
+	return strings.Join([]string{
+		cdxField(SURT(rec.TargetURI)),
+		rec.Date.UTC().Format("20060102150405"),
+		cdxField(rec.TargetURI),
+		mimeType,
+		status,
+		strings.TrimPrefix(digest, "sha1:"),
+		redirect,
+		"-",
+		fmt.Sprint(length),
+		fmt.Sprint(offset),
+		cdxField(filename),
+	}, " ")
+}
+
+// cdxField keeps a value from breaking the space-separated line
+func cdxField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(s, " ", "%20")
+}
+
+// SURT canonicalises a URL the way CDX indexes sort it: the host reversed into
+// comma-separated labels without "www", then the path and sorted query, all lowercase.
+// http://www.Example.com/a?b=1&a=2 becomes com,example)/a?a=2&b=1.
+func SURT(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(raw)
+	}
+	labels := strings.Split(strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	host := strings.Join(labels, ",")
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	out := host + ")" + path
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		sort.Strings(params)
+		out += "?" + strings.Join(params, "&")
+	}
+	return strings.ToLower(out)
+}
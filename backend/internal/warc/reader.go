@@ -0,0 +1,120 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader reads the records of a .warc.gz stream with one record per gzip member
+type Reader struct {
+	r  *countingReader
+	zr *gzip.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: &countingReader{r: bufio.NewReader(r)}}
+}
+
+// Next returns the next record with the offset and compressed length of its member, or
+// io.EOF after the last one
+func (r *Reader) Next() (rec *Record, offset, length int64, err error) {
+	offset = r.r.n
+	if r.zr == nil {
+		r.zr, err = gzip.NewReader(r.r)
+	} else {
+		err = r.zr.Reset(r.r)
+	}
+	if err != nil {
+		if err == io.EOF {
+			return nil, 0, 0, io.EOF
+		}
+		return nil, 0, 0, fmt.Errorf("warc: record at offset %d: %w", offset, err)
+	}
+	r.zr.Multistream(false)
+	data, err := io.ReadAll(r.zr)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("warc: record at offset %d: %w", offset, err)
+	}
+	rec, err = parseRecord(data)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("warc: record at offset %d: %w", offset, err)
+	}
+	return rec, offset, r.r.n - offset, nil
+}
+
+func parseRecord(data []byte) (*Record, error) {
+	head, rest, ok := bytes.Cut(data, []byte("\r\n\r\n"))
+	if !ok {
+		return nil, errors.New("unterminated header")
+	}
+	lines := strings.Split(string(head), "\r\n")
+	if !strings.HasPrefix(lines[0], "WARC/") {
+		return nil, fmt.Errorf("not a WARC record: %q", lines[0])
+	}
+	rec := &Record{}
+	length := -1
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed field %q", line)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		switch strings.ToLower(name) {
+		case "warc-type":
+			rec.Type = value
+		case "warc-record-id":
+			rec.ID = value
+		case "warc-date":
+			rec.Date, _ = time.Parse(time.RFC3339Nano, value)
+		case "warc-target-uri":
+			rec.TargetURI = value
+		case "content-type":
+			rec.ContentType = value
+		case "warc-concurrent-to":
+			rec.ConcurrentTo = value
+		case "warc-refers-to":
+			rec.RefersTo = value
+		case "content-length":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+			length = n
+		default:
+			rec.Fields = append(rec.Fields, [2]string{name, value})
+		}
+	}
+	if length < 0 || length > len(rest) {
+		return nil, errors.New("missing or invalid Content-Length")
+	}
+	rec.Block = rest[:length]
+	return rec, nil
+}
+
+// countingReader counts the bytes gzip consumes. It is an io.ByteReader, so gzip reads
+// exactly to the end of each member rather than buffering past it.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
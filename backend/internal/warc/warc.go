@@ -0,0 +1,185 @@
+// Package warc writes and reads WARC 1.1 files (ISO 28500:2017) whose records are gzipped
+// one per member, as .warc.gz files conventionally are, and indexes them as CDX.
+package warc
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the version line every record starts with
+const Version = "WARC/1.1"
+
+// Record types
+const (
+	TypeWarcinfo = "warcinfo"
+	TypeRequest  = "request"
+	TypeResponse = "response"
+	TypeMetadata = "metadata"
+)
+
+// Content types of record blocks
+const (
+	ContentTypeHTTPRequest  = "application/http; msgtype=request"
+	ContentTypeHTTPResponse = "application/http; msgtype=response"
+	ContentTypeFields       = "application/warc-fields"
+)
+
+// Record is a WARC record. Writing fills in ID and Date when they are empty.
+type Record struct {
+	Type         string
+	ID           string // <urn:uuid:...>
+	Date         time.Time
+	TargetURI    string
+	ContentType  string
+	ConcurrentTo string // ID of a record captured in the same transaction
+	RefersTo     string // ID of the record this one describes
+	// Fields holds further named fields, e.g. WARC-Payload-Digest or WARC-Truncated, in order
+	Fields [][2]string
+	Block  []byte
+}
+
+// Field returns the value of a named field, including the ones Record has members for
+func (r *Record) Field(name string) string {
+	switch strings.ToLower(name) {
+	case "warc-type":
+		return r.Type
+	case "warc-record-id":
+		return r.ID
+	case "warc-date":
+		return r.Date.UTC().Format(time.RFC3339)
+	case "warc-target-uri":
+		return r.TargetURI
+	case "content-type":
+		return r.ContentType
+	case "warc-concurrent-to":
+		return r.ConcurrentTo
+	case "warc-refers-to":
+		return r.RefersTo
+	}
+	for _, f := range r.Fields {
+		if strings.EqualFold(f[0], name) {
+			return f[1]
+		}
+	}
+	return ""
+}
+
+// NewID returns a fresh record ID
+func NewID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// Digest returns the labelled SHA-1 digest WARC and CDX use for blocks and payloads
+func Digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// Fields encodes name/value pairs as an application/warc-fields block, skipping empty values
+func Fields(pairs ...[2]string) []byte {
+	var b strings.Builder
+	for _, p := range pairs {
+		if p[1] == "" {
+			continue
+		}
+		// fields are single lines
+		value := strings.Join(strings.Fields(p[1]), " ")
+		b.WriteString(p[0] + ": " + value + "\r\n")
+	}
+	return []byte(b.String())
+}
+
+// Writer writes records to a .warc.gz stream, each record in a gzip member of its own so
+// readers can seek straight to a record
+type Writer struct {
+	w      io.Writer
+	offset int64
+}
+
+// NewWriter writes records to w, which is assumed to be positioned at offset 0 of its file
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Offset is the number of compressed bytes written so far
+func (w *Writer) Offset() int64 { return w.offset }
+
+// WriteRecord writes r and returns the offset and compressed length of its member
+func (w *Writer) WriteRecord(r *Record) (offset, length int64, err error) {
+	if r.Type == "" {
+		return 0, 0, errors.New("warc: record has no type")
+	}
+	if r.ID == "" {
+		r.ID = NewID()
+	}
+	if r.Date.IsZero() {
+		r.Date = time.Now()
+	}
+
+	var head strings.Builder
+	head.WriteString(Version + "\r\n")
+	field := func(name, value string) {
+		if value != "" {
+			head.WriteString(name + ": " + value + "\r\n")
+		}
+	}
+	field("WARC-Type", r.Type)
+	field("WARC-Record-ID", r.ID)
+	field("WARC-Date", r.Date.UTC().Format(time.RFC3339))
+	field("WARC-Target-URI", r.TargetURI)
+	field("WARC-Concurrent-To", r.ConcurrentTo)
+	field("WARC-Refers-To", r.RefersTo)
+	for _, f := range r.Fields {
+		field(f[0], f[1])
+	}
+	field("WARC-Block-Digest", Digest(r.Block))
+	field("Content-Type", r.ContentType)
+	field("Content-Length", strconv.Itoa(len(r.Block)))
+	head.WriteString("\r\n")
+
+	cw := &countingWriter{w: w.w}
+	zw := gzip.NewWriter(cw)
+	for _, part := range [][]byte{[]byte(head.String()), r.Block, []byte("\r\n\r\n")} {
+		if _, err := zw.Write(part); err != nil {
+			return 0, 0, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return 0, 0, err
+	}
+	offset = w.offset
+	w.offset += cw.n
+	return offset, cw.n, nil
+}
+
+// Append copies already compressed records, such as those another Writer produced, and
+// returns how many bytes were copied
+func (w *Writer) Append(records io.Reader) (int64, error) {
+	n, err := io.Copy(w.w, records)
+	w.offset += n
+	return n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
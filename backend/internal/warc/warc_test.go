@@ -0,0 +1,148 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterAndReader(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	info := &Record{Type: TypeWarcinfo, Date: at, ContentType: ContentTypeFields, Block: Fields([2]string{"software", "url-crawler"})}
+	resp := &Record{
+		Type: TypeResponse, Date: at, TargetURI: "http://example.com/", ContentType: ContentTypeHTTPResponse,
+		Block: []byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<p>hi</p>"),
+	}
+	req := &Record{
+		Type: TypeRequest, Date: at, TargetURI: "http://example.com/", ContentType: ContentTypeHTTPRequest,
+		Block: []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"),
+	}
+	var offsets, lengths []int64
+	for _, rec := range []*Record{info, resp, req} {
+		if rec.Type == TypeRequest {
+			rec.ConcurrentTo = resp.ID
+		}
+		off, n, err := w.WriteRecord(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offsets, lengths = append(offsets, off), append(lengths, n)
+	}
+	if w.Offset() != int64(buf.Len()) {
+		t.Errorf("expected the offset to track the bytes written, got %d of %d", w.Offset(), buf.Len())
+	}
+
+	// every record is a gzip member of its own
+	member, err := gzip.NewReader(bytes.NewReader(buf.Bytes()[offsets[1] : offsets[1]+lengths[1]]))
+	if err != nil {
+		t.Fatalf("expected a gzip member at the response's offset: %v", err)
+	}
+	data, _ := io.ReadAll(member)
+	for _, want := range []string{"WARC/1.1\r\n", "WARC-Type: response\r\n", "WARC-Date: 2026-03-01T12:30:00Z\r\n", "Content-Length: 53\r\n", "WARC-Block-Digest: sha1:"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in the record:\n%s", want, data)
+		}
+	}
+	if !strings.HasSuffix(string(data), "<p>hi</p>\r\n\r\n") {
+		t.Errorf("expected the block followed by two CRLFs, got %q", data)
+	}
+
+	r := NewReader(&buf)
+	for i, want := range []*Record{info, resp, req} {
+		got, off, n, err := r.Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if off != offsets[i] || n != lengths[i] {
+			t.Errorf("record %d: expected offset %d length %d, got %d %d", i, offsets[i], lengths[i], off, n)
+		}
+		if got.Type != want.Type || got.ID != want.ID || !got.Date.Equal(at) || got.TargetURI != want.TargetURI ||
+			got.ConcurrentTo != want.ConcurrentTo || !bytes.Equal(got.Block, want.Block) {
+			t.Errorf("record %d: read back %+v, want %+v", i, got, want)
+		}
+		if got.Field("WARC-Block-Digest") != Digest(want.Block) {
+			t.Errorf("record %d: unexpected block digest %q", i, got.Field("WARC-Block-Digest"))
+		}
+	}
+	if _, _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF after the last record, got %v", err)
+	}
+}
+
+func TestNewID(t *testing.T) {
+	id := NewID()
+	if len(id) != len("<urn:uuid:00000000-0000-0000-0000-000000000000>") || !strings.HasPrefix(id, "<urn:uuid:") || id == NewID() {
+		t.Errorf("unexpected record ID %q", id)
+	}
+}
+
+func TestWriteCDX(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	records := []*Record{
+		{Type: TypeWarcinfo, Date: at, Block: Fields([2]string{"software", "url-crawler"})},
+		{Type: TypeResponse, Date: at, TargetURI: "https://www.Example.com/b", ContentType: ContentTypeHTTPResponse,
+			Block:  []byte("HTTP/1.1 200 OK\r\nContent-Type: text/html; charset=utf-8\r\n\r\nhello"),
+			Fields: [][2]string{{"WARC-Payload-Digest", Digest([]byte("hello"))}}},
+		{Type: TypeRequest, Date: at, TargetURI: "https://www.Example.com/b", Block: []byte("GET /b HTTP/1.1\r\n\r\n")},
+		{Type: TypeResponse, Date: at, TargetURI: "http://example.com/a", ContentType: ContentTypeHTTPResponse,
+			Block: []byte("HTTP/1.1 301 Moved Permanently\r\nLocation: http://example.com/a/\r\n\r\n")},
+	}
+	var offsets []int64
+	for _, rec := range records {
+		off, _, err := w.WriteRecord(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, off)
+	}
+
+	var out bytes.Buffer
+	n, err := WriteCDX(&out, &buf, "crawl.warc.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected the two responses to be indexed, got %d", n)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if lines[0] != CDXHeader {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	// sorted by canonical URL
+	redirect := strings.Fields(lines[1])
+	if redirect[0] != "com,example)/a" || redirect[4] != "301" || redirect[6] != "http://example.com/a/" || redirect[9] != strconv.FormatInt(offsets[3], 10) {
+		t.Errorf("unexpected redirect line %q", lines[1])
+	}
+	page := strings.Fields(lines[2])
+	want := []string{"com,example)/b", "20260301123000", "https://www.Example.com/b", "text/html", "200", strings.TrimPrefix(Digest([]byte("hello")), "sha1:"), "-", "-"}
+	for i, v := range want {
+		if page[i] != v {
+			t.Errorf("field %d: expected %q, got %q", i, v, page[i])
+		}
+	}
+	if page[9] != strconv.FormatInt(offsets[1], 10) || page[10] != "crawl.warc.gz" {
+		t.Errorf("unexpected location in %q", lines[2])
+	}
+}
+
+func TestSURT(t *testing.T) {
+	for in, want := range map[string]string{
+		"http://www.Example.com/a?b=1&a=2": "com,example)/a?a=2&b=1",
+		"https://sub.example.co.uk":        "uk,co,example,sub)/",
+		"http://example.com:8080/x":        "com,example:8080)/x",
+		"https://example.com:443/":         "com,example)/",
+	} {
+		if got := SURT(in); got != want {
+			t.Errorf("SURT(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+-- Per-job crawl options (e.g. enabled analyzers) and analyzer output stored as JSON,
+-- so new checks don't require schema changes
+
+ALTER TABLE crawl_jobs ADD COLUMN options JSON NULL AFTER status;
+
+ALTER TABLE crawl_results ADD COLUMN findings JSON NULL AFTER has_login_form;
@@ -0,0 +1,19 @@
+-- Custom extraction rules, scoped either to a single URL or to every URL carrying a tag
+
+CREATE TABLE IF NOT EXISTS extraction_rules (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    url_id BIGINT NULL,
+    tag_id BIGINT NULL,
+    selector_type ENUM('css', 'xpath') NOT NULL,
+    selector VARCHAR(1024) NOT NULL,
+    attribute VARCHAR(255) NULL,
+    multiple BOOLEAN DEFAULT FALSE,
+    regex VARCHAR(1024) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    INDEX idx_url_id (url_id),
+    INDEX idx_tag_id (tag_id),
+    CHECK ((url_id IS NULL) <> (tag_id IS NULL))
+);
@@ -0,0 +1,29 @@
+-- TLS certificate last seen per URL and the alerts raised when one expires or fails validation
+
+CREATE TABLE IF NOT EXISTS certificates (
+    url_id BIGINT PRIMARY KEY,
+    host VARCHAR(255) NOT NULL,
+    subject VARCHAR(512) NOT NULL,
+    issuer VARCHAR(512) NOT NULL,
+    sans JSON NULL,
+    not_before DATETIME NOT NULL,
+    not_after DATETIME NOT NULL,
+    chain_valid BOOLEAN NOT NULL,
+    hostname_match BOOLEAN NOT NULL,
+    validation_error TEXT NULL,
+    checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE,
+    INDEX idx_not_after (not_after)
+);
+
+CREATE TABLE IF NOT EXISTS certificate_alerts (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    url_id BIGINT NOT NULL,
+    kind ENUM('expiring', 'expired', 'invalid') NOT NULL,
+    message VARCHAR(1024) NOT NULL,
+    not_after DATETIME NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE,
+    INDEX idx_url_id (url_id),
+    INDEX idx_created_at (created_at)
+);
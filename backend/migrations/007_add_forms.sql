@@ -0,0 +1,3 @@
+-- Forms found on the page with their fields and classification (login, signup, search, ...)
+
+ALTER TABLE crawl_results ADD COLUMN forms JSON NULL AFTER has_login_form;
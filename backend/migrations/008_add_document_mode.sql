@@ -0,0 +1,3 @@
+-- Rendering mode the page's doctype selects (no-quirks, limited-quirks, quirks)
+
+ALTER TABLE crawl_results ADD COLUMN document_mode VARCHAR(16) NULL AFTER html_version;
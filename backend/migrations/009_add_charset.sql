@@ -0,0 +1,6 @@
+-- Encoding the page was decoded from, where it was declared, and whether the
+-- Content-Type header and the document's <meta> disagree
+
+ALTER TABLE crawl_results ADD COLUMN charset VARCHAR(64) NULL AFTER document_mode;
+ALTER TABLE crawl_results ADD COLUMN charset_source VARCHAR(16) NULL AFTER charset;
+ALTER TABLE crawl_results ADD COLUMN charset_mismatch BOOLEAN NOT NULL DEFAULT FALSE AFTER charset_source;
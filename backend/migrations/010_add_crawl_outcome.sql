@@ -0,0 +1,7 @@
+-- Whether the page was parsed completely: ok, truncated (body over the size limit),
+-- parse_timeout, or not_html (PDFs, images, archives are not parsed)
+
+ALTER TABLE crawl_results ADD COLUMN outcome VARCHAR(16) NOT NULL DEFAULT 'ok' AFTER url_id;
+ALTER TABLE crawl_results ADD COLUMN content_type VARCHAR(255) NULL AFTER outcome;
+ALTER TABLE crawl_results ADD COLUMN body_bytes BIGINT NOT NULL DEFAULT 0 AFTER content_type;
+ALTER TABLE crawl_results ADD COLUMN truncated BOOLEAN NOT NULL DEFAULT FALSE AFTER body_bytes;
@@ -0,0 +1,23 @@
+-- Request profiles customise how a URL is fetched: headers, cookies, credentials, user agent.
+-- Scoped either to a single URL or to every URL carrying a tag, like extraction rules.
+-- Cookies, passwords and bearer tokens are kept in secrets, AES-GCM encrypted by the service.
+
+CREATE TABLE IF NOT EXISTS request_profiles (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    url_id BIGINT NULL,
+    tag_id BIGINT NULL,
+    user_agent VARCHAR(512) NULL,
+    accept_language VARCHAR(255) NULL,
+    headers JSON NULL,
+    auth_type ENUM('none', 'basic', 'bearer') NOT NULL DEFAULT 'none',
+    auth_username VARCHAR(255) NULL,
+    secrets VARBINARY(16384) NULL,
+    apply_to_links BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    INDEX idx_url_id (url_id),
+    INDEX idx_tag_id (tag_id),
+    CHECK ((url_id IS NULL) <> (tag_id IS NULL))
+);
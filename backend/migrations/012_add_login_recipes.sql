@@ -0,0 +1,16 @@
+-- Login recipes log the crawler in before it fetches a URL. Several URLs can share one recipe.
+-- The submitted field values hold passwords, so they are AES-GCM encrypted by the service.
+
+CREATE TABLE IF NOT EXISTS login_recipes (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    login_url VARCHAR(2048) NOT NULL,
+    fields VARBINARY(16384) NOT NULL,
+    success_cookie VARCHAR(255) NULL,
+    success_url VARCHAR(2048) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE urls
+    ADD COLUMN login_recipe_id BIGINT NULL,
+    ADD CONSTRAINT fk_urls_login_recipe FOREIGN KEY (login_recipe_id) REFERENCES login_recipes(id) ON DELETE SET NULL;
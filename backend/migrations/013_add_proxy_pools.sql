@@ -0,0 +1,20 @@
+-- Proxy pools route crawls through HTTP, HTTPS or SOCKS5 proxies, scoped either to a single URL
+-- or to every URL carrying a tag. Proxy URLs may carry credentials, so the list is AES-GCM
+-- encrypted by the service. Proxy health is tracked in memory.
+
+CREATE TABLE IF NOT EXISTS proxy_pools (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    url_id BIGINT NULL,
+    tag_id BIGINT NULL,
+    proxies VARBINARY(16384) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    INDEX idx_url_id (url_id),
+    INDEX idx_tag_id (tag_id),
+    CHECK ((url_id IS NULL) <> (tag_id IS NULL))
+);
+
+-- The proxy a result was fetched through, without credentials
+ALTER TABLE crawl_results ADD COLUMN proxy VARCHAR(255) NULL AFTER truncated;
@@ -0,0 +1,5 @@
+-- Failed links of a result counted by error class (dns, connect, tls, timeout, reset,
+-- too_many_redirects, http_status, blocked, other). inaccessible_links_count sums the classes
+-- configured as inaccessible.
+
+ALTER TABLE crawl_results ADD COLUMN link_errors JSON NULL AFTER inaccessible_links_count;
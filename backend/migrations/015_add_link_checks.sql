@@ -0,0 +1,11 @@
+-- Outcomes of link checks shared by all crawls, so links common to many pages are checked once
+-- per TTL even across restarts. Rows are keyed by the SHA-256 of the normalised URL; class is
+-- empty for a link that was fine. Expired rows are pruned by the server.
+
+CREATE TABLE IF NOT EXISTS link_checks (
+    url_hash BINARY(32) PRIMARY KEY,
+    url TEXT NOT NULL,
+    class VARCHAR(32) NOT NULL DEFAULT '',
+    checked_at TIMESTAMP NOT NULL,
+    INDEX idx_checked_at (checked_at)
+);
@@ -0,0 +1,8 @@
+-- Validators of the fetched page, sent back as If-None-Match / If-Modified-Since on the next
+-- crawl of the URL. A crawl that finds the page unchanged stores a not_modified result carrying
+-- the previous result forward and pointing at it.
+
+ALTER TABLE crawl_results ADD COLUMN etag VARCHAR(512) NULL AFTER proxy;
+ALTER TABLE crawl_results ADD COLUMN last_modified VARCHAR(64) NULL AFTER etag;
+ALTER TABLE crawl_results ADD COLUMN previous_result_id BIGINT NULL AFTER last_modified;
+ALTER TABLE crawl_results ADD CONSTRAINT fk_previous_result FOREIGN KEY (previous_result_id) REFERENCES crawl_results(id) ON DELETE SET NULL;
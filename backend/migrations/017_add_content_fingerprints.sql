@@ -0,0 +1,9 @@
+-- Content fingerprints of each crawl: a SHA-256 of the visible text and a 64-bit simhash for
+-- near-duplicate detection. content_changed and similarity compare a result with the previous
+-- result of its URL; both are NULL on a URL's first crawl.
+
+ALTER TABLE crawl_results ADD COLUMN content_hash CHAR(64) NULL AFTER charset_mismatch;
+ALTER TABLE crawl_results ADD COLUMN simhash BIGINT UNSIGNED NULL AFTER content_hash;
+ALTER TABLE crawl_results ADD COLUMN content_changed BOOLEAN NULL AFTER simhash;
+ALTER TABLE crawl_results ADD COLUMN similarity DOUBLE NULL AFTER content_changed;
+ALTER TABLE crawl_results ADD INDEX idx_content_changed (content_changed, created_at);
@@ -0,0 +1,15 @@
+-- Raw responses (status line, headers and body, gzipped) kept for crawl results when a
+-- snapshot store is configured. The blobs live in the store under blob_key; rows outlive
+-- their result on purpose, so the server can prune the blobs of deleted results too.
+
+CREATE TABLE IF NOT EXISTS result_snapshots (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    result_id BIGINT NOT NULL,
+    url_id BIGINT NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_result (result_id),
+    INDEX idx_url_created (url_id, created_at),
+    INDEX idx_created_at (created_at)
+);
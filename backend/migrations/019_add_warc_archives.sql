@@ -0,0 +1,6 @@
+-- With WARC archiving on, a snapshot also keeps its result's request, response and metadata
+-- records (and, if the job asked, its link checks) as a gzipped WARC fragment in the blob
+-- store. Exports concatenate the fragments of a job or a date range after a warcinfo record.
+
+ALTER TABLE result_snapshots ADD COLUMN warc_key VARCHAR(255) NULL AFTER size_bytes;
+ALTER TABLE result_snapshots ADD COLUMN warc_bytes BIGINT NULL AFTER warc_key;
@@ -0,0 +1,7 @@
+-- What a result was built from besides the page: inputs_hash fingerprints the extraction rules,
+-- request profile and login recipe of the crawl, so a crawl with other inputs fetches the page
+-- in full rather than revalidating it. links are the page's links, which a crawl that finds the
+-- page unchanged can check again.
+
+ALTER TABLE crawl_results ADD COLUMN inputs_hash CHAR(64) NULL AFTER previous_result_id;
+ALTER TABLE crawl_results ADD COLUMN links JSON NULL AFTER link_errors;
@@ -0,0 +1,5 @@
+-- WARC records have a retention of their own (WARC_MAX_AGE, kept forever by default). A snapshot
+-- past the snapshot retention loses its HTTP message but keeps its row while it still has WARC
+-- records; blob_key is NULL then.
+
+ALTER TABLE result_snapshots MODIFY blob_key VARCHAR(255) NULL;
@@ -0,0 +1,3 @@
+-- Forms are kept in the forms analyzer's findings; the column added by 007 is no longer written
+
+ALTER TABLE crawl_results DROP COLUMN forms;
@@ -20,7 +20,7 @@ export async function login(username: string, password: string): Promise<string>
   return token
 }
 
-export type URLItem = { id: number; url: string }
+export type URLItem = { id: number; url: string; tags?: string[] }
 
 export async function createUrl(url: string): Promise<URLItem> {
   const headers = buildHeaders({ 'Content-Type': 'application/json' })
@@ -70,10 +70,38 @@ export async function jobStatus(jobId: number): Promise<{ data: { id: number; st
   return res.json()
 }
 
+// FormInfo is one entry of findings.forms
+export type FormInfo = {
+  type: 'login' | 'signup' | 'password_change' | 'search' | 'newsletter' | 'payment' | 'contact' | 'other'
+  id?: string
+  action: string
+  method: string
+  fields: { name?: string; type: string; autocomplete?: string; required: boolean }[]
+  csrf_fields: string[]
+  submit_buttons: string[]
+  standalone: boolean
+}
+
 export type Result = {
   id: number
   url_id: number
+  outcome?: 'ok' | 'truncated' | 'parse_timeout' | 'not_html' | 'not_modified'
+  content_type?: string | null
+  body_bytes?: number
+  truncated?: boolean
+  proxy?: string | null
+  etag?: string | null
+  last_modified?: string | null
+  previous_result_id?: number | null
   html_version: string | null
+  document_mode?: 'no-quirks' | 'limited-quirks' | 'quirks' | null
+  charset?: string | null
+  charset_source?: 'bom' | 'header' | 'meta' | 'sniffed' | 'default' | null
+  charset_mismatch?: boolean
+  content_hash?: string | null
+  simhash?: string | null
+  changed?: boolean | null
+  similarity?: number | null
   title: string | null
   headings_h1: number
   headings_h2: number
@@ -84,7 +112,11 @@ export type Result = {
   internal_links_count: number
   external_links_count: number
   inaccessible_links_count: number
+  link_errors?: Record<string, number> | null
   has_login_form: boolean
+  accessibility_score?: number | null
+  security_grade?: string | null
+  findings?: Record<string, unknown> | null
 }
 
 export async function getResult(urlId: number): Promise<Result> {